@@ -0,0 +1,65 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// Condition types.
+const (
+	// ConditionReady indicates that the resource was successfully reconciled.
+	ConditionReady = "Ready"
+
+	// ConditionCredentialsValid indicates that the Linode API token can be used
+	// to manage LKE clusters.
+	ConditionCredentialsValid = "CredentialsValid"
+
+	// ConditionDrifted indicates that the LKE cluster was changed outside of
+	// the operator, and no longer matches the spec.
+	ConditionDrifted = "Drifted"
+
+	// ConditionDeleting reports the progress of the deletion of the LKE cluster
+	// and the resources published by the operator.
+	ConditionDeleting = "Deleting"
+)
+
+// Condition reasons.
+const (
+	ReasonValidated        = "Validated"
+	ReasonValidationFailed = "ValidationFailed"
+
+	ReasonReconciled         = "Reconciled"
+	ReasonReconcileFailed    = "ReconcileFailed"
+	ReasonInvalidCredentials = "InvalidCredentials"
+	ReasonInvalidLabel       = "InvalidLabel"
+	ReasonDeletionProtected  = "DeletionProtected"
+	ReasonApprovalRequired   = "ApprovalRequired"
+
+	ReasonNoDrift        = "NoDrift"
+	ReasonDriftDetected  = "DriftDetected"
+	ReasonDriftCorrected = "DriftCorrected"
+
+	ReasonDeletingCluster          = "DeletingCluster"
+	ReasonCleaningUpWorkloads      = "CleaningUpWorkloads"
+	ReasonWaitingForClusterRemoval = "WaitingForClusterRemoval"
+	ReasonDeletionTimedOut         = "DeletionTimedOut"
+	ReasonCleaningUp               = "CleaningUp"
+
+	ReasonTokenValid         = "TokenValid"
+	ReasonTokenExpiringSoon  = "TokenExpiringSoon"
+	ReasonTokenExpired       = "TokenExpired"
+	ReasonTokenMissingScopes = "TokenMissingScopes"
+	ReasonTokenMissingGrants = "TokenMissingGrants"
+	ReasonTokenScopesUnknown = "TokenScopesUnknown"
+)
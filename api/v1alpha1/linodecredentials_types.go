@@ -0,0 +1,127 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LinodeCredentialsSpec defines the desired state of a LinodeCredentials resource.
+type LinodeCredentialsSpec struct {
+	// TokenSecretRef references the Kubernetes secret that stores the Linode API token.
+	// +kubebuilder:validation:Required
+	TokenSecretRef SecretRef `json:"tokenSecretRef"`
+
+	// AllowedNamespaces lists the namespaces in which LKEClusterConfigs may use
+	// these credentials. If empty, the credentials cannot be used in any namespace.
+	// +kubebuilder:validation:Optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// API configures how the Linode API is reached with these credentials. Unset
+	// fields default to the values configured for the operator.
+	// +kubebuilder:validation:Optional
+	API *LinodeAPI `json:"api,omitempty"`
+}
+
+// LinodeAPI configures the endpoint of the Linode API.
+type LinodeAPI struct {
+	// URL is the base URL of the Linode API, e.g. https://api.linode.com. It may
+	// include the API version, e.g. https://api.linode.com/v4beta.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url,omitempty"`
+
+	// Version is the version of the Linode API, e.g. v4beta. It takes precedence
+	// over the version included in the URL.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^v[a-zA-Z0-9]+$`
+	Version string `json:"version,omitempty"`
+
+	// Proxy is the URL of the HTTP(S) proxy used to reach the Linode API.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(https?|socks5)://`
+	Proxy string `json:"proxy,omitempty"`
+
+	// CABundle is the PEM encoded bundle of CA certificates trusted in addition
+	// to the system ones, e.g. the CA of the TLS intercepting proxy.
+	// +kubebuilder:validation:Optional
+	CABundle []byte `json:"caBundle,omitempty"`
+}
+
+// LinodeCredentialsStatus defines the observed state of a LinodeCredentials resource.
+type LinodeCredentialsStatus struct {
+	// Account contains the details of the Linode account the token belongs to.
+	// +kubebuilder:validation:Optional
+	Account *LinodeAccount `json:"account,omitempty"`
+
+	// ExpiresAt is the time after which the token can no longer be used.
+	// Empty if the token does not expire, or its expiry could not be determined.
+	// +kubebuilder:validation:Optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// Conditions represent the latest available observations of the credentials.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// LinodeAccount identifies the Linode user owning the token.
+type LinodeAccount struct {
+	// Username is the name of the Linode user.
+	Username string `json:"username"`
+
+	// Email is the email address of the Linode user.
+	// +kubebuilder:validation:Optional
+	Email string `json:"email,omitempty"`
+}
+
+// AllowsNamespace checks if LKEClusterConfigs from the namespace may use the credentials.
+func (c *LinodeCredentials) AllowsNamespace(namespace string) bool {
+	return slices.Contains(c.Spec.AllowedNamespaces, namespace)
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=lkecreds
+
+// LinodeCredentials is the Schema for the linodecredentials API.
+// +kubebuilder:printcolumn:name=Username,type=string,JSONPath=`.status.account.username`
+// +kubebuilder:printcolumn:name=ExpiresAt,type=string,JSONPath=`.status.expiresAt`
+// +kubebuilder:printcolumn:name=Ready,type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+type LinodeCredentials struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LinodeCredentialsSpec   `json:"spec,omitempty"`
+	Status LinodeCredentialsStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LinodeCredentialsList contains a list of LinodeCredentials
+type LinodeCredentialsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LinodeCredentials `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LinodeCredentials{}, &LinodeCredentialsList{})
+}
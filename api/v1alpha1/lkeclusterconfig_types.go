@@ -21,15 +21,32 @@ import (
 )
 
 // LKEClusterConfigSpec defines the desired state of an LKEClusterConfig resource.
+// +kubebuilder:validation:XValidation:rule="!(has(self.tokenSecretRef) && has(self.credentialsRef))",message="only one of tokenSecretRef and credentialsRef may be set"
 type LKEClusterConfigSpec struct {
 	// Region is the geographical region where the LKE cluster will be provisioned.
 	// +kubebuilder:validation:Required
 	Region string `json:"region"`
 
+	// Label is the template of the label of the LKE cluster, which must be unique within
+	// the Linode account. The template may use the {{ .Namespace }}, {{ .Name }} and
+	// {{ .Prefix }} variables, the latter configured for the whole operator. Defaults to
+	// the template configured for the operator, or the namespace and name of the object
+	// joined with a dash. Changing the label renames the existing LKE cluster. Without
+	// the label and the template configured for the operator, the existing LKE cluster
+	// keeps its label.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=256
+	Label string `json:"label,omitempty"`
+
 	// TokenSecretRef references the Kubernetes secret that stores the Linode API token.
 	// If not provided, then default token will be used.
-	// +kubebuilder:validation:Required
-	TokenSecretRef SecretRef `json:"tokenSecretRef"`
+	// +kubebuilder:validation:Optional
+	TokenSecretRef *SecretRef `json:"tokenSecretRef,omitempty"`
+
+	// CredentialsRef references the cluster-scoped LinodeCredentials used to manage the
+	// LKE cluster. Mutually exclusive with TokenSecretRef.
+	// +kubebuilder:validation:Optional
+	CredentialsRef *CredentialsRef `json:"credentialsRef,omitempty"`
 
 	// HighAvailability specifies whether the LKE cluster should be configured for high
 	// availability.
@@ -46,12 +63,136 @@ type LKEClusterConfigSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=latest
 	KubernetesVersion *string `json:"kubernetesVersion,omitempty"`
+
+	// DriftPolicy defines how the changes made to the LKE cluster outside of the operator
+	// are handled. Report only records them in the Drifted condition, Correct reverts them
+	// to match the spec.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Report
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// UnmanagedNodePools defines how the node pools of the LKE cluster not created by the
+	// operator are handled. Ignore leaves them untouched, Adopt adds them to the spec,
+	// Delete removes them from the LKE cluster. Deletion follows the DriftPolicy once the
+	// spec is applied, and requires approval if NodePoolDelete is listed in RequireApproval.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Ignore
+	UnmanagedNodePools UnmanagedNodePoolsPolicy `json:"unmanagedNodePools,omitempty"`
+
+	// RequeueIntervals overrides the operator-wide intervals, after which the LKE cluster
+	// is reconciled again in the given phase.
+	// +kubebuilder:validation:Optional
+	RequeueIntervals *RequeueIntervals `json:"requeueIntervals,omitempty"`
+
+	// Protection guards the LKE cluster against accidental deletion and destructive changes.
+	// +kubebuilder:validation:Optional
+	Protection *Protection `json:"protection,omitempty"`
+
+	// PreDeleteCleanup deletes the resources of the workload cluster backed by Linode
+	// NodeBalancers and Block Storage Volumes, before the LKE cluster is deleted. Otherwise
+	// they are left behind in the Linode account.
+	// +kubebuilder:validation:Optional
+	PreDeleteCleanup *PreDeleteCleanup `json:"preDeleteCleanup,omitempty"`
+}
+
+// RequeueIntervals defines the intervals after which the LKE cluster is reconciled again.
+// Unset intervals default to the operator-wide values. Intervals must be at least 5s, and
+// are raised to the operator-wide minimum.
+type RequeueIntervals struct {
+	// Provisioning is the interval used while the LKE cluster is being provisioned.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('5s')",message="must be at least 5s"
+	Provisioning *metav1.Duration `json:"provisioning,omitempty"`
+
+	// Updating is the interval used while the LKE cluster is being updated.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('5s')",message="must be at least 5s"
+	Updating *metav1.Duration `json:"updating,omitempty"`
+
+	// Deleting is the interval used while the LKE cluster is being deleted.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('5s')",message="must be at least 5s"
+	Deleting *metav1.Duration `json:"deleting,omitempty"`
+
+	// Resync is the interval used while the LKE cluster is active.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('5s')",message="must be at least 5s"
+	Resync *metav1.Duration `json:"resync,omitempty"`
+}
+
+// Protection guards the LKE cluster against accidental deletion and destructive changes.
+type Protection struct {
+	// Deletion blocks deletion of the object and of the LKE cluster it manages.
+	// +kubebuilder:validation:Optional
+	Deletion bool `json:"deletion,omitempty"`
+
+	// RequireApproval lists the kinds of destructive operations requiring approval. Changes
+	// including such operations are planned, and applied only once the lke.anza-labs.dev/approve
+	// annotation is set to the hash of the plan, reported in the status. The annotation is
+	// removed once the plan is applied, so every plan must be approved again.
+	// +kubebuilder:validation:Optional
+	// +listType=set
+	RequireApproval []OperationKind `json:"requireApproval,omitempty"`
+}
+
+// PreDeleteCleanup defines the resources deleted from the workload cluster, using the
+// stored kubeconfig, before the LKE cluster is deleted.
+type PreDeleteCleanup struct {
+	// LoadBalancers deletes the Services of type LoadBalancer, and waits for them to be
+	// removed together with their NodeBalancers.
+	// +kubebuilder:validation:Optional
+	LoadBalancers bool `json:"loadBalancers,omitempty"`
+
+	// Volumes scales the StatefulSets with claim templates to zero, deletes the
+	// PersistentVolumeClaims and the Pods using them, and waits for the PersistentVolumes
+	// of the Linode CSI driver with the Delete reclaim policy to be removed together with
+	// their Block Storage Volumes.
+	// +kubebuilder:validation:Optional
+	Volumes bool `json:"volumes,omitempty"`
+
+	// Timeout is the time after the start of the deletion, after which the LKE cluster
+	// is deleted even if the cleanup did not finish, e.g. because the workload cluster
+	// is unreachable. Defaults to 30 minutes.
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Plan lists the destructive operations waiting for approval.
+type Plan struct {
+	// Hash identifies the plan. The plan is approved by setting the lke.anza-labs.dev/approve
+	// annotation to the hash.
+	Hash string `json:"hash"`
+
+	// Operations lists the planned destructive operations.
+	Operations []PlannedOperation `json:"operations"`
+}
+
+// PlannedOperation is a single destructive operation waiting for approval.
+type PlannedOperation struct {
+	// Kind is the kind of the operation.
+	Kind OperationKind `json:"kind"`
+
+	// Description is the human readable description of the operation.
+	Description string `json:"description"`
 }
 
 // SecretRef references a Kubernetes secret.
 type SecretRef struct {
 	Namespace string `json:"namespace"`
 	Name      string `json:"name"`
+
+	// Key is the key in the secret data that stores the Linode API token.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=LINODE_TOKEN
+	Key string `json:"key,omitempty"`
+}
+
+// CredentialsRef references a LinodeCredentials resource.
+type CredentialsRef struct {
+	// Name is the name of the LinodeCredentials resource.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
 }
 
 // LKENodePool represents a pool of nodes within the LKE cluster.
@@ -122,6 +263,31 @@ type LKEClusterConfigStatus struct {
 	// FailureMessage contains an optional failure message for the LKE cluster.
 	// +kubebuilder:validation:Optional
 	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// UnmanagedNodePools lists the node pools of the LKE cluster not created by the operator.
+	// +kubebuilder:validation:Optional
+	UnmanagedNodePools []NodePoolStatus `json:"unmanagedNodePools,omitempty"`
+
+	// PendingApproval contains the plan of destructive operations waiting for approval.
+	// +kubebuilder:validation:Optional
+	PendingApproval *Plan `json:"pendingApproval,omitempty"`
+
+	// AppliedHash identifies the tags and the rendered label last applied to the LKE cluster.
+	// They do not change the generation of the object, so their changes are detected by the hash.
+	// +kubebuilder:validation:Optional
+	AppliedHash string `json:"appliedHash,omitempty"`
+
+	// DeletionRequestedAt is the time the deletion of the LKE cluster was last requested.
+	// The deletion timeout is measured from it, and the deletion is requested again
+	// at most once per timeout.
+	// +kubebuilder:validation:Optional
+	DeletionRequestedAt *metav1.Time `json:"deletionRequestedAt,omitempty"`
+
+	// Conditions represent the latest available observations of the LKE cluster.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // NodePoolStatus
@@ -139,6 +305,33 @@ func (n NodePoolStatus) IsEqual(cmp NodePoolStatus) bool {
 	return n.NodePoolDetails.IsEqual(cmp.NodePoolDetails)
 }
 
+// +kubebuilder:validation:Enum=Report;Correct
+type DriftPolicy string
+
+const (
+	DriftPolicyReport  DriftPolicy = "Report"
+	DriftPolicyCorrect DriftPolicy = "Correct"
+)
+
+// +kubebuilder:validation:Enum=Ignore;Adopt;Delete
+type UnmanagedNodePoolsPolicy string
+
+const (
+	UnmanagedNodePoolsIgnore UnmanagedNodePoolsPolicy = "Ignore"
+	UnmanagedNodePoolsAdopt  UnmanagedNodePoolsPolicy = "Adopt"
+	UnmanagedNodePoolsDelete UnmanagedNodePoolsPolicy = "Delete"
+)
+
+// +kubebuilder:validation:Enum=NodePoolDelete;NodePoolReplace;VersionUpgrade;HighAvailabilityChange
+type OperationKind string
+
+const (
+	OperationNodePoolDelete         OperationKind = "NodePoolDelete"
+	OperationNodePoolReplace        OperationKind = "NodePoolReplace"
+	OperationVersionUpgrade         OperationKind = "VersionUpgrade"
+	OperationHighAvailabilityChange OperationKind = "HighAvailabilityChange"
+)
+
 // +kubebuilder:validation:Enum=Active;Deleting;Error;Provisioning;Unknown;Updating
 type Phase string
 
@@ -151,6 +344,18 @@ const (
 	PhaseUnknown      Phase = "Unknown"
 )
 
+const (
+	// ApproveAnnotation approves the plan of destructive operations with the hash
+	// set as its value. It is removed once the plan is applied.
+	ApproveAnnotation = "lke.anza-labs.dev/approve"
+
+	// PausedAnnotation stops the reconciliation of the object, including its deletion,
+	// while set to "true".
+	PausedAnnotation = "lke.anza-labs.dev/paused"
+)
+
+// +genclient
+// +genclient:noStatus
 // +kubebuilder:object:root=true
 
 // LKEClusterConfig is the Schema for the lkeclusterconfigs API.
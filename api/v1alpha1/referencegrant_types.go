@@ -0,0 +1,104 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReferenceGrantSpec defines which objects from other namespaces may reference
+// Secrets in the namespace of the ReferenceGrant.
+type ReferenceGrantSpec struct {
+	// From lists the namespaces allowed to reference Secrets in this namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	From []ReferenceGrantFrom `json:"from"`
+
+	// To lists the Secrets that may be referenced. If empty, all Secrets in this
+	// namespace may be referenced.
+	// +kubebuilder:validation:Optional
+	To []ReferenceGrantTo `json:"to,omitempty"`
+}
+
+// ReferenceGrantFrom describes the namespace allowed to reference Secrets.
+type ReferenceGrantFrom struct {
+	// Namespace is the namespace of LKEClusterConfigs allowed to reference Secrets.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+// ReferenceGrantTo describes the Secret that may be referenced.
+type ReferenceGrantTo struct {
+	// Name is the name of the Secret.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// Permits checks if the grant allows objects from the namespace to reference
+// the Secret with the given name.
+func (g *ReferenceGrant) Permits(namespace, secretName string) bool {
+	from := false
+	for _, f := range g.Spec.From {
+		if f.Namespace == namespace {
+			from = true
+			break
+		}
+	}
+
+	if !from {
+		return false
+	}
+
+	if len(g.Spec.To) == 0 {
+		return true
+	}
+
+	for _, t := range g.Spec.To {
+		if t.Name == secretName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// +kubebuilder:object:root=true
+
+// ReferenceGrant allows LKEClusterConfigs from other namespaces to reference
+// Secrets in the namespace of the ReferenceGrant.
+// +kubebuilder:resource:shortName=lkerg
+type ReferenceGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ReferenceGrantSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReferenceGrantList contains a list of ReferenceGrant
+type ReferenceGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReferenceGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReferenceGrant{}, &ReferenceGrantList{})
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command lke-emulator serves the in-memory emulator of the Linode API. Run the
+// operator with --linode-api-url pointing at http://<addr>/v4 to use it.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/anza-labs/lke-operator/internal/emulator"
+)
+
+func main() {
+	var (
+		addr              string
+		versions          string
+		provisioningDelay time.Duration
+		deletionDelay     time.Duration
+	)
+
+	flag.StringVar(&addr, "addr", ":8080", "The address the emulator binds to.")
+	flag.StringVar(&versions, "versions", strings.Join(emulator.DefaultVersions(), ","),
+		"Comma separated Kubernetes versions offered for the LKE clusters.")
+	flag.DurationVar(&provisioningDelay, "provisioning-delay", 30*time.Second,
+		"Time after which the new LKE clusters and nodes become ready.")
+	flag.DurationVar(&deletionDelay, "deletion-delay", 10*time.Second,
+		"Time for which the deleted LKE clusters are still returned by the API.")
+	flag.Parse()
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	server := &http.Server{
+		Addr: addr,
+		Handler: emulator.New(emulator.Options{
+			Versions:          splitVersions(versions),
+			ProvisioningDelay: provisioningDelay,
+			DeletionDelay:     deletionDelay,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error("failed to shut down the emulator", "error", err)
+		}
+	}()
+
+	log.Info("serving the Linode API emulator", "addr", addr, "apiVersion", emulator.APIVersion)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("failed to serve the emulator", "error", err)
+		os.Exit(1)
+	}
+}
+
+func splitVersions(s string) []string {
+	versions := []string{}
+
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			versions = append(versions, v)
+		}
+	}
+
+	return versions
+}
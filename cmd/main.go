@@ -21,15 +21,24 @@ import (
 	"flag"
 	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"github.com/anza-labs/lke-operator/internal/controller"
+	"github.com/anza-labs/lke-operator/internal/credentials"
 	tracedk8s "github.com/anza-labs/lke-operator/internal/k8s/traced"
+	"github.com/anza-labs/lke-operator/internal/label"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	"github.com/anza-labs/lke-operator/internal/policy"
+	"github.com/anza-labs/lke-operator/internal/resty/logger"
 	"github.com/anza-labs/lke-operator/internal/version"
+	internalwebhook "github.com/anza-labs/lke-operator/internal/webhook"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
@@ -63,6 +72,35 @@ func main() {
 		probeAddr            string
 		secureMetrics        bool
 		enableHTTP2          bool
+		linodeAPIURL         string
+		linodeAPIVersion     string
+		linodeProxy          string
+		linodeCAFile         string
+		linodeRateLimit      float64
+		linodeBurst          int
+		breakerThreshold     int
+		breakerCooldown      time.Duration
+		clientIdleTimeout    time.Duration
+		defaultToken         string
+		defaultTokenFile     string
+		crossNamespaceMode   string
+		enableWebhooks       bool
+		tokenValidationTTL   time.Duration
+		tokenExpiryWarning   time.Duration
+		requeueIntervals     = controller.DefaultRequeueIntervals()
+		deletionTimeout      time.Duration
+		maxConcurrent        int
+		backoffBase          time.Duration
+		backoffMax           time.Duration
+		reconcileQPS         float64
+		reconcileBurst       int
+		gcInterval           time.Duration
+		gcGracePeriod        time.Duration
+		gcDryRun             bool
+		gcDeleteVolumes      bool
+		instanceID           string
+		labelPrefix          string
+		defaultLabel         string
 	)
 
 	flag.StringVar(
@@ -101,6 +139,260 @@ func main() {
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers.",
 	)
 
+	flag.StringVar(
+		&linodeAPIURL,
+		"linode-api-url",
+		"",
+		"URL of the Linode API, optionally including the API version, e.g. http://127.0.0.1:8080/v4 "+
+			"for the LKE emulator. Defaults to the public Linode API. "+
+			"May be overridden by the LinodeCredentials.",
+	)
+
+	flag.StringVar(
+		&linodeAPIVersion,
+		"linode-api-version",
+		"",
+		"Version of the Linode API, e.g. v4beta. Takes precedence over the version included in the URL. "+
+			"May be overridden by the LinodeCredentials.",
+	)
+
+	flag.StringVar(
+		&linodeProxy,
+		"linode-proxy",
+		"",
+		"URL of the HTTP(S) proxy used to reach the Linode API. "+
+			"Defaults to the HTTPS_PROXY and NO_PROXY environment variables. "+
+			"May be overridden by the LinodeCredentials.",
+	)
+
+	flag.StringVar(
+		&linodeCAFile,
+		"linode-ca-file",
+		"",
+		"Path to the PEM encoded bundle of CA certificates trusted by the Linode client, "+
+			"in addition to the system ones. May be overridden by the LinodeCredentials.",
+	)
+
+	flag.Float64Var(
+		&linodeRateLimit,
+		"linode-rate-limit",
+		5,
+		"Maximum number of requests per second sent to the Linode API using a single token.",
+	)
+
+	flag.IntVar(
+		&linodeBurst,
+		"linode-burst",
+		10,
+		"Maximum burst of requests sent to the Linode API using a single token.",
+	)
+
+	flag.IntVar(
+		&breakerThreshold,
+		"linode-circuit-breaker-threshold",
+		5,
+		"Number of consecutive Linode API server errors after which all requests using the token are suspended. "+
+			"Set to 0 to disable the circuit breaker.",
+	)
+
+	flag.DurationVar(
+		&breakerCooldown,
+		"linode-circuit-breaker-cooldown",
+		time.Minute,
+		"Time for which requests using the token are suspended, once the circuit breaker opens.",
+	)
+
+	flag.DurationVar(
+		&clientIdleTimeout,
+		"linode-client-idle-timeout",
+		time.Hour,
+		"Time after which the Linode client, rate limiter and circuit breaker of a token not used "+
+			"by any reconciliation are dropped. Set to 0 to keep them forever.",
+	)
+
+	flag.StringVar(
+		&defaultToken,
+		"linode-token",
+		"",
+		"Default Linode API token, used by objects not referencing any token. "+
+			"If not set, the token is read from the "+credentials.TokenEnv+" environment variable.",
+	)
+
+	flag.StringVar(
+		&defaultTokenFile,
+		"linode-token-file",
+		"",
+		"Path to the file containing default Linode API token, used by objects not referencing any token. "+
+			"Takes precedence over the --linode-token flag.",
+	)
+
+	flag.DurationVar(
+		&tokenValidationTTL,
+		"linode-token-validation-ttl",
+		credentials.DefaultValidationTTL,
+		"Time for which the result of the token scope and expiry validation is reused.",
+	)
+
+	flag.DurationVar(
+		&tokenExpiryWarning,
+		"linode-token-expiry-warning",
+		controller.DefaultTokenExpiryWarning,
+		"Time before the token expiry, from which the upcoming expiry is reported.",
+	)
+
+	flag.StringVar(
+		&crossNamespaceMode,
+		"cross-namespace-policy",
+		string(policy.CrossNamespaceRequireGrant),
+		"Policy for references to token Secrets in other namespaces. "+
+			"One of: "+string(policy.CrossNamespaceAllow)+", "+string(policy.CrossNamespaceRequireGrant)+". "+
+			"With "+string(policy.CrossNamespaceRequireGrant)+", the namespace of the Secret must contain "+
+			"ReferenceGrant allowing the reference.",
+	)
+
+	flag.BoolVar(
+		&enableWebhooks,
+		"enable-webhooks",
+		false,
+		"If set, the admission webhooks will be served. Requires serving certificates to be provisioned.",
+	)
+
+	flag.DurationVar(
+		&requeueIntervals.Provisioning,
+		"requeue-provisioning-interval",
+		requeueIntervals.Provisioning,
+		"Interval after which LKE clusters being provisioned are reconciled again.",
+	)
+
+	flag.DurationVar(
+		&requeueIntervals.Updating,
+		"requeue-updating-interval",
+		requeueIntervals.Updating,
+		"Interval after which LKE clusters being updated are reconciled again.",
+	)
+
+	flag.DurationVar(
+		&requeueIntervals.Deleting,
+		"requeue-deleting-interval",
+		requeueIntervals.Deleting,
+		"Interval after which LKE clusters being deleted are reconciled again.",
+	)
+
+	flag.DurationVar(
+		&requeueIntervals.Resync,
+		"resync-interval",
+		requeueIntervals.Resync,
+		"Interval after which active LKE clusters are reconciled again. Set to 0 to disable periodic resync.",
+	)
+
+	flag.DurationVar(
+		&requeueIntervals.Minimum,
+		"requeue-min-interval",
+		requeueIntervals.Minimum,
+		"Minimum interval after which LKE clusters are reconciled again, applied to the intervals "+
+			"overridden by the LKEClusterConfig.",
+	)
+
+	flag.DurationVar(
+		&deletionTimeout,
+		"deletion-timeout",
+		controller.DefaultDeletionTimeout,
+		"Time to wait for LKE clusters to disappear after requesting their deletion, "+
+			"before the timeout is reported and the deletion is requested again.",
+	)
+
+	flag.IntVar(
+		&maxConcurrent,
+		"max-concurrent-reconciles",
+		1,
+		"Maximum number of LKEClusterConfigs reconciled concurrently.",
+	)
+
+	flag.DurationVar(
+		&backoffBase,
+		"reconcile-backoff-base",
+		5*time.Millisecond,
+		"Initial delay after which LKEClusterConfigs that failed to reconcile are retried.",
+	)
+
+	flag.DurationVar(
+		&backoffMax,
+		"reconcile-backoff-max",
+		1000*time.Second,
+		"Maximum delay after which LKEClusterConfigs that failed to reconcile are retried.",
+	)
+
+	flag.Float64Var(
+		&reconcileQPS,
+		"reconcile-qps",
+		10,
+		"Maximum overall rate of retries of LKEClusterConfigs that failed to reconcile.",
+	)
+
+	flag.IntVar(
+		&reconcileBurst,
+		"reconcile-burst",
+		100,
+		"Maximum burst of retries of LKEClusterConfigs that failed to reconcile.",
+	)
+
+	flag.DurationVar(
+		&gcInterval,
+		"gc-interval",
+		0,
+		"Interval between the searches for LKE clusters created by the operator without matching LKEClusterConfig, "+
+			"and for NodeBalancers and Volumes left behind by deleted LKE clusters. "+
+			"Set to 0 to disable the garbage collection.",
+	)
+
+	flag.DurationVar(
+		&gcGracePeriod,
+		"gc-grace-period",
+		controller.DefaultGCGracePeriod,
+		"Time for which the LKE cluster, NodeBalancer or Volume must stay orphaned, "+
+			"before it is deleted by the garbage collection.",
+	)
+
+	flag.BoolVar(
+		&gcDryRun,
+		"gc-dry-run",
+		true,
+		"If set, orphaned LKE clusters, NodeBalancers and Volumes are only reported, "+
+			"and never deleted by the garbage collection.",
+	)
+
+	flag.BoolVar(
+		&gcDeleteVolumes,
+		"gc-delete-volumes",
+		false,
+		"If set, Volumes left behind by deleted LKE clusters are deleted by the garbage collection. "+
+			"Otherwise they are only reported, as they may hold data worth recovering.",
+	)
+
+	flag.StringVar(
+		&instanceID,
+		"instance-id",
+		controller.DefaultInstanceID,
+		"ID of the operator instance, tagged on the LKE clusters it creates. "+
+			"Operators sharing a Linode account must use different IDs, "+
+			"as the garbage collection only deletes the LKE clusters tagged with its own ID.",
+	)
+
+	flag.StringVar(
+		&labelPrefix,
+		"cluster-label-prefix",
+		"",
+		"Prefix available in the templates of the LKE cluster labels as the {{ .Prefix }} variable.",
+	)
+
+	flag.StringVar(
+		&defaultLabel,
+		"default-cluster-label",
+		label.DefaultTemplate,
+		"Template of the label of LKE clusters, used by objects not setting the label. "+
+			"May use the {{ .Namespace }}, {{ .Name }} and {{ .Prefix }} variables.",
+	)
+
 	klog.InitFlags(nil)
 	flag.Parse()
 	ctrl.SetLogger(klog.Background())
@@ -108,6 +400,40 @@ func main() {
 	setupLog.Info("starting manager",
 		"version", version.Version)
 
+	mode, err := policy.ParseCrossNamespaceMode(crossNamespaceMode)
+	if err != nil {
+		setupLog.Error(err, "invalid flag value",
+			"flag", "cross-namespace-policy")
+		os.Exit(1)
+	}
+
+	if err := controller.ValidateInstanceID(instanceID); err != nil {
+		setupLog.Error(err, "invalid flag value",
+			"flag", "instance-id")
+		os.Exit(1)
+	}
+
+	linodeEndpoint := lkeclient.Endpoint{
+		URL:     linodeAPIURL,
+		Version: linodeAPIVersion,
+		Proxy:   linodeProxy,
+	}
+
+	if linodeCAFile != "" {
+		linodeEndpoint.CA, err = os.ReadFile(filepath.Clean(linodeCAFile))
+		if err != nil {
+			setupLog.Error(err, "unable to read CA bundle",
+				"flag", "linode-ca-file")
+			os.Exit(1)
+		}
+	}
+
+	if err := linodeEndpoint.Validate(); err != nil {
+		setupLog.Error(err, "invalid Linode API endpoint",
+			"flags", []string{"linode-api-url", "linode-api-version", "linode-proxy", "linode-ca-file"})
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -151,15 +477,97 @@ func main() {
 		return otelhttp.NewTransport(rt)
 	}
 
+	referencePolicy := &policy.ReferencePolicy{
+		Mode:   mode,
+		Reader: mgr.GetClient(),
+	}
+
+	kubernetesClient := kubernetes.NewForConfigOrDie(rest)
+
+	linodeClients := lkeclient.NewCache(lkeclient.CacheOptions{
+		Endpoint:         linodeEndpoint,
+		RateLimit:        rate.Limit(linodeRateLimit),
+		Burst:            linodeBurst,
+		FailureThreshold: breakerThreshold,
+		Cooldown:         breakerCooldown,
+		IdleTimeout:      clientIdleTimeout,
+		Logger:           logger.Wrap(ctrl.Log.WithName("linode")),
+	})
+
+	tokenValidator := credentials.NewValidator(tokenValidationTTL)
+
+	labels := label.Template{
+		Prefix:  labelPrefix,
+		Default: defaultLabel,
+	}
+
 	if err = (&controller.LKEClusterConfigReconciler{
-		Client:           tracedk8s.NewClientWithTracing(mgr.GetClient(), "main_mgr_client"),
-		Scheme:           mgr.GetScheme(),
-		KubernetesClient: kubernetes.NewForConfigOrDie(rest),
+		Client:             tracedk8s.NewClientWithTracing(mgr.GetClient(), "main_mgr_client"),
+		Scheme:             mgr.GetScheme(),
+		KubernetesClient:   kubernetesClient,
+		LinodeClients:      linodeClients,
+		DefaultCredentials: credentials.Default(defaultToken, defaultTokenFile),
+		ReferencePolicy:    referencePolicy,
+		TokenValidator:     tokenValidator,
+		TokenExpiryWarning: tokenExpiryWarning,
+		Recorder:           mgr.GetEventRecorderFor("lkeclusterconfig-controller"),
+
+		Labels:                  labels,
+		InstanceID:              instanceID,
+		DeletionTimeout:         deletionTimeout,
+		RequeueIntervals:        requeueIntervals,
+		MaxConcurrentReconciles: maxConcurrent,
+		RateLimiter:             controller.NewRateLimiter(backoffBase, backoffMax, reconcileQPS, reconcileBurst),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller",
 			"controller", "LKEClusterConfig")
 		os.Exit(1)
 	}
+
+	if err = (&controller.LinodeCredentialsReconciler{
+		Client:             tracedk8s.NewClientWithTracing(mgr.GetClient(), "credentials_mgr_client"),
+		Scheme:             mgr.GetScheme(),
+		KubernetesClient:   kubernetesClient,
+		LinodeClients:      linodeClients,
+		TokenValidator:     tokenValidator,
+		TokenExpiryWarning: tokenExpiryWarning,
+		Recorder:           mgr.GetEventRecorderFor("linodecredentials-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller",
+			"controller", "LinodeCredentials")
+		os.Exit(1)
+	}
+
+	if gcInterval > 0 {
+		if err = (&controller.GarbageCollector{
+			Client:             mgr.GetClient(),
+			KubernetesClient:   kubernetesClient,
+			LinodeClients:      linodeClients,
+			DefaultCredentials: credentials.Default(defaultToken, defaultTokenFile),
+			ReferencePolicy:    referencePolicy,
+			Recorder:           mgr.GetEventRecorderFor("garbage-collector"),
+			InstanceID:         instanceID,
+			Interval:           gcInterval,
+			GracePeriod:        gcGracePeriod,
+			DryRun:             gcDryRun,
+			DeleteVolumes:      gcDeleteVolumes,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up garbage collector")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhooks {
+		if err = (&internalwebhook.LKEClusterConfigValidator{
+			Reader:          mgr.GetClient(),
+			ReferencePolicy: referencePolicy,
+			Labels:          labels,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook",
+				"webhook", "LKEClusterConfig")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
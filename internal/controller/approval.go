@@ -0,0 +1,222 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/linode/linodego"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+// lkeApproveAnnotation approves the plan of destructive operations with the given hash.
+const lkeApproveAnnotation = v1alpha1.ApproveAnnotation
+
+// planHashLength is the number of hex characters of the plan hash.
+const planHashLength = 16
+
+// planDestructiveOperations returns the sorted list of destructive operations,
+// required to make the LKE cluster match the spec. Descriptions do not include
+// the live node counts, which change with autoscaling.
+func planDestructiveOperations(
+	lke *v1alpha1.LKEClusterConfig,
+	cluster *linodego.LKECluster,
+	pools []linodego.LKENodePool,
+) []v1alpha1.PlannedOperation {
+	ops := []v1alpha1.PlannedOperation{}
+
+	ha := lke.Spec.HighAvailability != nil && *lke.Spec.HighAvailability
+	if cluster.ControlPlane.HighAvailability != ha {
+		ops = append(ops, v1alpha1.PlannedOperation{
+			Kind: v1alpha1.OperationHighAvailabilityChange,
+			Description: fmt.Sprintf("change highAvailability from %t to %t",
+				cluster.ControlPlane.HighAvailability, ha),
+		})
+	}
+
+	if version := desiredVersion(lke); version != "" && version != cluster.K8sVersion {
+		ops = append(ops, v1alpha1.PlannedOperation{
+			Kind: v1alpha1.OperationVersionUpgrade,
+			Description: fmt.Sprintf("upgrade kubernetesVersion from %s to %s",
+				cluster.K8sVersion, version),
+		})
+	}
+
+	spec := generateNodePoolStatusesFromSpec(lke.Spec.NodePools)
+	live := generateNodePoolStatusesFromAPI(pools)
+
+	ignoreAutoscaledCounts(spec, live)
+
+	change, remove, _ := compareNodePoolStatuses(spec, live)
+
+	for name, status := range remove {
+		ops = append(ops, v1alpha1.PlannedOperation{
+			Kind:        v1alpha1.OperationNodePoolDelete,
+			Description: fmt.Sprintf("delete node pool %s (%s)", name, status.NodePoolDetails.LinodeType),
+		})
+	}
+
+	if lke.Spec.UnmanagedNodePools == v1alpha1.UnmanagedNodePoolsDelete {
+		for _, np := range unmanagedNodePools(pools) {
+			ops = append(ops, v1alpha1.PlannedOperation{
+				Kind:        v1alpha1.OperationNodePoolDelete,
+				Description: fmt.Sprintf("delete unmanaged node pool %d (%s)", np.ID, np.Type),
+			})
+		}
+	}
+
+	for name, status := range change {
+		current := live[name]
+		if current.ID == nil || current.NodePoolDetails.LinodeType == status.NodePoolDetails.LinodeType {
+			continue
+		}
+
+		ops = append(ops, v1alpha1.PlannedOperation{
+			Kind: v1alpha1.OperationNodePoolReplace,
+			Description: fmt.Sprintf("replace node pool %s (%s) with %s", name,
+				current.NodePoolDetails.LinodeType, describeNodePool(status.NodePoolDetails)),
+		})
+	}
+
+	slices.SortFunc(ops, func(a, b v1alpha1.PlannedOperation) int {
+		if c := strings.Compare(string(a.Kind), string(b.Kind)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Description, b.Description)
+	})
+
+	return ops
+}
+
+// planHash returns the hash identifying the plan.
+func planHash(ops []v1alpha1.PlannedOperation) string {
+	h := sha256.New()
+
+	for _, op := range ops {
+		fmt.Fprintf(h, "%s\t%s\n", op.Kind, op.Description)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:planHashLength]
+}
+
+// approved checks if the destructive operations required to make the LKE cluster
+// match the spec were approved, if the spec requires their approval. Otherwise the
+// plan is recorded in the status, waiting for approval.
+func (r *LKEClusterConfigReconciler) approved(
+	lke *v1alpha1.LKEClusterConfig,
+	cluster *linodego.LKECluster,
+	pools []linodego.LKENodePool,
+) bool {
+	var gated []v1alpha1.PlannedOperation
+
+	if lke.Spec.Protection != nil {
+		for _, op := range planDestructiveOperations(lke, cluster, pools) {
+			if slices.Contains(lke.Spec.Protection.RequireApproval, op.Kind) {
+				gated = append(gated, op)
+			}
+		}
+	}
+
+	if len(gated) == 0 {
+		lke.Status.PendingApproval = nil
+		return true
+	}
+
+	hash := planHash(gated)
+
+	if lke.Annotations[lkeApproveAnnotation] == hash {
+		lke.Status.PendingApproval = nil
+		r.event(lke, corev1.EventTypeNormal, "PlanApproved",
+			fmt.Sprintf("applying approved plan %s", hash))
+
+		return true
+	}
+
+	if lke.Status.PendingApproval == nil || lke.Status.PendingApproval.Hash != hash {
+		descriptions := make([]string, 0, len(gated))
+		for _, op := range gated {
+			descriptions = append(descriptions, op.Description)
+		}
+
+		r.event(lke, corev1.EventTypeWarning, v1alpha1.ReasonApprovalRequired,
+			fmt.Sprintf("plan %s requires approval: %s", hash, strings.Join(descriptions, "; ")))
+	}
+
+	lke.Status.PendingApproval = &v1alpha1.Plan{
+		Hash:       hash,
+		Operations: gated,
+	}
+
+	meta.SetStatusCondition(&lke.Status.Conditions, metav1.Condition{
+		Type:   v1alpha1.ConditionReady,
+		Status: metav1.ConditionFalse,
+		Reason: v1alpha1.ReasonApprovalRequired,
+		Message: fmt.Sprintf("destructive changes require approval, set the %s annotation to %s",
+			lkeApproveAnnotation, hash),
+		ObservedGeneration: lke.Generation,
+	})
+
+	return false
+}
+
+// consumeApproval removes the approval once the plan is applied, so that it does not
+// approve the same plan again, e.g. after the spec is reverted and changed back.
+// Approval left on the object without any plan to apply is removed as well.
+func (r *LKEClusterConfigReconciler) consumeApproval(ctx context.Context, lke *v1alpha1.LKEClusterConfig) error {
+	if _, ok := lke.Annotations[lkeApproveAnnotation]; !ok {
+		return nil
+	}
+
+	delete(lke.Annotations, lkeApproveAnnotation)
+
+	if err := r.Update(ctx, lke); err != nil {
+		return fmt.Errorf("failed to remove approval: %w", err)
+	}
+
+	return nil
+}
+
+// deletionProtected checks if the deletion of the LKE cluster is blocked. The
+// Ready condition reports the blocked deletion.
+func (r *LKEClusterConfigReconciler) deletionProtected(lke *v1alpha1.LKEClusterConfig) bool {
+	if lke.Spec.Protection == nil || !lke.Spec.Protection.Deletion {
+		return false
+	}
+
+	if meta.SetStatusCondition(&lke.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             v1alpha1.ReasonDeletionProtected,
+		Message:            "deletion protection is enabled, disable spec.protection.deletion to delete the LKE cluster",
+		ObservedGeneration: lke.Generation,
+	}) {
+		r.event(lke, corev1.EventTypeWarning, v1alpha1.ReasonDeletionProtected,
+			"deletion of the LKE cluster is blocked by deletion protection")
+	}
+
+	return true
+}
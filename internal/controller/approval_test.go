@@ -0,0 +1,263 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/linode/linodego"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	lkefake "github.com/anza-labs/lke-operator/internal/lkeclient/fake"
+)
+
+func Test_planDestructiveOperations(t *testing.T) {
+	t.Parallel()
+
+	cluster := &linodego.LKECluster{K8sVersion: "1.29"}
+	pools := []linodego.LKENodePool{
+		{ID: 1, Count: 3, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"}},
+		{ID: 2, Count: 1, Type: "g6-standard-2", Tags: []string{lkeOperatorTag + "extra"}},
+	}
+
+	for name, tc := range map[string]struct {
+		spec          v1alpha1.LKEClusterConfigSpec
+		expectedKinds []v1alpha1.OperationKind
+	}{
+		"none": {
+			spec: v1alpha1.LKEClusterConfigSpec{
+				KubernetesVersion: mkptr("latest"),
+				NodePools: map[string]v1alpha1.LKENodePool{
+					"default": {NodeCount: 5, LinodeType: "g6-standard-1"},
+					"extra":   {NodeCount: 1, LinodeType: "g6-standard-2"},
+					"new":     {NodeCount: 1, LinodeType: "g6-standard-2"},
+				},
+			},
+			expectedKinds: []v1alpha1.OperationKind{},
+		},
+		"all": {
+			spec: v1alpha1.LKEClusterConfigSpec{
+				HighAvailability:  mkptr(true),
+				KubernetesVersion: mkptr("1.30"),
+				NodePools: map[string]v1alpha1.LKENodePool{
+					"default": {NodeCount: 3, LinodeType: "g6-standard-4"},
+				},
+			},
+			expectedKinds: []v1alpha1.OperationKind{
+				v1alpha1.OperationHighAvailabilityChange,
+				v1alpha1.OperationNodePoolDelete,
+				v1alpha1.OperationNodePoolReplace,
+				v1alpha1.OperationVersionUpgrade,
+			},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ops := planDestructiveOperations(&v1alpha1.LKEClusterConfig{Spec: tc.spec}, cluster, pools)
+
+			kinds := []v1alpha1.OperationKind{}
+			for _, op := range ops {
+				kinds = append(kinds, op.Kind)
+			}
+
+			if !reflect.DeepEqual(kinds, tc.expectedKinds) {
+				t.Errorf("expected Kinds value: %#+v, got: %#+v",
+					tc.expectedKinds, kinds)
+			}
+		})
+	}
+}
+
+func TestLKEClusterConfigReconciler_approved(t *testing.T) {
+	t.Parallel()
+
+	cluster := &linodego.LKECluster{}
+	pools := []linodego.LKENodePool{
+		{ID: 1, Count: 3, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"}},
+		{ID: 2, Count: 1, Type: "g6-standard-2", Tags: []string{lkeOperatorTag + "extra"}},
+	}
+	spec := v1alpha1.LKEClusterConfigSpec{
+		NodePools: map[string]v1alpha1.LKENodePool{
+			"default": {NodeCount: 3, LinodeType: "g6-standard-1"},
+		},
+	}
+	hash := planHash([]v1alpha1.PlannedOperation{{
+		Kind:        v1alpha1.OperationNodePoolDelete,
+		Description: "delete node pool extra (g6-standard-2)",
+	}})
+
+	for name, tc := range map[string]struct {
+		protection      *v1alpha1.Protection
+		annotations     map[string]string
+		expectedApprove bool
+		expectedPending bool
+	}{
+		"unprotected": {
+			expectedApprove: true,
+		},
+		"not_gated": {
+			protection: &v1alpha1.Protection{
+				RequireApproval: []v1alpha1.OperationKind{v1alpha1.OperationVersionUpgrade},
+			},
+			expectedApprove: true,
+		},
+		"pending": {
+			protection: &v1alpha1.Protection{
+				RequireApproval: []v1alpha1.OperationKind{v1alpha1.OperationNodePoolDelete},
+			},
+			annotations:     map[string]string{lkeApproveAnnotation: "stale"},
+			expectedPending: true,
+		},
+		"approved": {
+			protection: &v1alpha1.Protection{
+				RequireApproval: []v1alpha1.OperationKind{v1alpha1.OperationNodePoolDelete},
+			},
+			annotations:     map[string]string{lkeApproveAnnotation: hash},
+			expectedApprove: true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &LKEClusterConfigReconciler{Recorder: record.NewFakeRecorder(10)}
+
+			lke := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: v1.ObjectMeta{Annotations: tc.annotations},
+				Spec:       *spec.DeepCopy(),
+			}
+			lke.Spec.Protection = tc.protection
+
+			if approved := r.approved(lke, cluster, pools); approved != tc.expectedApprove {
+				t.Errorf("expected Approved value: %#+v, got: %#+v",
+					tc.expectedApprove, approved)
+			}
+
+			if pending := lke.Status.PendingApproval != nil; pending != tc.expectedPending {
+				t.Errorf("expected Pending value: %#+v, got: %#+v",
+					tc.expectedPending, pending)
+			}
+
+			if tc.expectedPending && lke.Status.PendingApproval.Hash != hash {
+				t.Errorf("expected Hash value: %#+v, got: %#+v",
+					hash, lke.Status.PendingApproval.Hash)
+			}
+		})
+	}
+}
+
+func TestLKEClusterConfigReconciler_onChange_approval(t *testing.T) {
+	t.Parallel()
+
+	obj := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: "test", UID: "test-uid"},
+		Spec: v1alpha1.LKEClusterConfigSpec{
+			Region:      "us-east",
+			NodePools:   map[string]v1alpha1.LKENodePool{"default": {NodeCount: 1, LinodeType: "g6-standard-1"}},
+			DriftPolicy: v1alpha1.DriftPolicyCorrect,
+			Protection:  &v1alpha1.Protection{RequireApproval: []v1alpha1.OperationKind{v1alpha1.OperationNodePoolDelete}},
+		},
+		Status: v1alpha1.LKEClusterConfigStatus{ClusterID: mkptr(1)},
+	}
+
+	r := &LKEClusterConfigReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(newTestScheme(t)).
+			WithObjects(obj).
+			Build(),
+		KubernetesClient: kubefake.NewSimpleClientset(),
+		Recorder:         record.NewFakeRecorder(100),
+	}
+
+	extra := linodego.LKENodePoolCreateOptions{
+		Count: 1,
+		Type:  "g6-standard-2",
+		Tags:  []string{lkeOperatorTag + "extra"},
+	}
+
+	client := lkefake.NewClient()
+	client.AddCluster(
+		linodego.LKECluster{
+			ID:         1,
+			Label:      "default-test",
+			Region:     "us-east",
+			K8sVersion: "1.30",
+			Tags:       ownershipTags(obj, DefaultInstanceID),
+		},
+		linodego.LKENodePool{Count: 1, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"}},
+	)
+
+	if _, err := client.CreateLKENodePool(context.Background(), 1, extra); err != nil {
+		t.Fatalf("failed to create node pool: %v", err)
+	}
+
+	for i, step := range []struct {
+		approve         bool
+		recreate        bool
+		expectedPools   map[string]int
+		expectedPending bool
+	}{
+		{
+			expectedPools:   map[string]int{"default": 1, "extra": 1},
+			expectedPending: true,
+		},
+		{
+			approve:       true,
+			expectedPools: map[string]int{"default": 1},
+		},
+		{
+			// the same plan must be approved again
+			recreate:        true,
+			expectedPools:   map[string]int{"default": 1, "extra": 1},
+			expectedPending: true,
+		},
+	} {
+		if step.approve {
+			obj.Annotations = map[string]string{lkeApproveAnnotation: obj.Status.PendingApproval.Hash}
+		}
+
+		if step.recreate {
+			if _, err := client.CreateLKENodePool(context.Background(), 1, extra); err != nil {
+				t.Fatalf("step %d: failed to create node pool: %v", i, err)
+			}
+		}
+
+		if _, err := r.onChange(context.Background(), client, obj); err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+
+		if counts := poolCounts(client.NodePools(1)); !reflect.DeepEqual(counts, step.expectedPools) {
+			t.Errorf("step %d: expected Pools value: %#+v, got: %#+v", i, step.expectedPools, counts)
+		}
+
+		if pending := obj.Status.PendingApproval != nil; pending != step.expectedPending {
+			t.Errorf("step %d: expected Pending value: %#+v, got: %#+v", i, step.expectedPending, pending)
+		}
+
+		if _, ok := obj.Annotations[lkeApproveAnnotation]; ok {
+			t.Errorf("step %d: expected approval to be removed, got: %#+v", i, obj.Annotations)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	"github.com/anza-labs/lke-operator/internal/lkeclient/cassette"
+)
+
+const (
+	// cassetteReconciles limits the reconciles of the single scenario step.
+	cassetteReconciles = 100
+
+	// cassettePollInterval is the wait between requeued reconciles while recording.
+	cassettePollInterval = 5 * time.Second
+)
+
+// newCassetteClient returns the Linode client replaying the cassette from testdata.
+// The cassettes are recorded against the Linode API emulator (cmd/lke-emulator) at
+// LINODE_API_URL, not the public Linode API, so they cover the reconciler against the
+// emulated responses only.
+func newCassetteClient(t *testing.T, name string) lkeclient.Client {
+	t.Helper()
+
+	path := filepath.Join("testdata", "emulator-cassettes", name+".yaml")
+
+	opts := []lkeclient.Option{lkeclient.WithTransport(cassette.Transport(t, path, nil))}
+
+	if cassette.Recording() {
+		apiURL := os.Getenv("LINODE_API_URL")
+		if apiURL == "" {
+			t.Fatal("LINODE_API_URL of the emulator is required to record the cassettes")
+		}
+
+		opts = append(opts, lkeclient.WithAPIURL(apiURL))
+	}
+
+	client := lkeclient.New("cassette-token", "lke-operator-test", opts...)
+	// retries would make the recorded traffic depend on the timing
+	client.SetRetryCount(0)
+
+	return client
+}
+
+// cassetteStep changes the object and reconciles it until it is not requeued.
+type cassetteStep struct {
+	mutate         func(lke *v1alpha1.LKEClusterConfig)
+	delete         bool
+	expectedErr    bool
+	expectedPhase  *v1alpha1.Phase
+	expectedPools  int
+	expectedSecret bool
+}
+
+func TestLKEClusterConfigReconciler_emulatorCassettes(t *testing.T) {
+	t.Parallel()
+
+	active := cassetteStep{
+		expectedPhase:  mkptr(v1alpha1.PhaseActive),
+		expectedPools:  1,
+		expectedSecret: true,
+	}
+
+	for name, tc := range map[string][]cassetteStep{
+		"create": {active},
+		"update": {
+			active,
+			{
+				mutate: func(lke *v1alpha1.LKEClusterConfig) {
+					lke.Spec.NodePools["default"] = v1alpha1.LKENodePool{NodeCount: 2, LinodeType: "g6-standard-1"}
+					lke.Spec.NodePools["extra"] = v1alpha1.LKENodePool{NodeCount: 1, LinodeType: "g6-standard-2"}
+				},
+				expectedPhase:  mkptr(v1alpha1.PhaseActive),
+				expectedPools:  2,
+				expectedSecret: true,
+			},
+			{
+				mutate: func(lke *v1alpha1.LKEClusterConfig) {
+					lke.Spec.HighAvailability = mkptr(true)
+				},
+				expectedPhase:  mkptr(v1alpha1.PhaseActive),
+				expectedPools:  2,
+				expectedSecret: true,
+			},
+		},
+		"delete": {
+			active,
+			{
+				delete:        true,
+				expectedPhase: mkptr(v1alpha1.PhaseActive),
+				expectedPools: 1,
+			},
+		},
+		"unsupported_version": {
+			{
+				mutate: func(lke *v1alpha1.LKEClusterConfig) {
+					lke.Spec.KubernetesVersion = mkptr("1.10")
+				},
+				expectedErr: true,
+			},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := newCassetteClient(t, name)
+
+			obj := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "cassette", Name: name, UID: types.UID("cassette-" + name)},
+				Spec: v1alpha1.LKEClusterConfigSpec{
+					Region: "us-east",
+					NodePools: map[string]v1alpha1.LKENodePool{
+						"default": {NodeCount: 1, LinodeType: "g6-standard-1"},
+					},
+				},
+			}
+
+			kubernetesClient := kubefake.NewSimpleClientset()
+
+			r := &LKEClusterConfigReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme(t)).
+					WithObjects(obj).
+					Build(),
+				KubernetesClient: kubernetesClient,
+				Recorder:         record.NewFakeRecorder(1000),
+			}
+
+			for i, step := range tc {
+				if step.mutate != nil {
+					step.mutate(obj)
+					// the API server bumps the generation on spec changes
+					obj.Generation++
+				}
+
+				var (
+					res ctrl.Result
+					err error
+				)
+
+				for range cassetteReconciles {
+					if step.delete {
+						res, err = r.onDelete(context.Background(), client, obj)
+					} else {
+						res, err = r.onChange(context.Background(), client, obj)
+					}
+
+					if err != nil || res.IsZero() {
+						break
+					}
+
+					if cassette.Recording() {
+						time.Sleep(cassettePollInterval)
+					}
+				}
+
+				if (err != nil) != step.expectedErr {
+					t.Fatalf("step %d: expected error: %#+v, got: %#+v", i, step.expectedErr, err)
+				}
+
+				if !res.IsZero() {
+					t.Fatalf("step %d: still requeued after %d reconciles", i, cassetteReconciles)
+				}
+
+				if phase, expected := deref(obj.Status.Phase), deref(step.expectedPhase); phase != expected {
+					t.Errorf("step %d: expected Phase value: %#+v, got: %#+v", i, expected, phase)
+				}
+
+				if pools := len(obj.Status.NodePoolStatuses); pools != step.expectedPools {
+					t.Errorf("step %d: expected Pools value: %#+v, got: %#+v", i, step.expectedPools, pools)
+				}
+
+				_, err = kubernetesClient.CoreV1().Secrets(obj.Namespace).
+					Get(context.Background(), kubeconfigSecretName(obj), metav1.GetOptions{})
+				if exists := err == nil; exists != step.expectedSecret {
+					t.Errorf("step %d: expected kubeconfig Secret: %#+v, got: %#+v", i, step.expectedSecret, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+// DefaultPreDeleteCleanupTimeout is the default time after the start of the deletion,
+// after which the LKE cluster is deleted even if the pre-delete cleanup did not finish.
+const DefaultPreDeleteCleanupTimeout = 30 * time.Minute
+
+// workloadRequestTimeout limits the requests to the workload cluster, so that the
+// unreachable API server does not block the deletion.
+const workloadRequestTimeout = 30 * time.Second
+
+// linodeCSIDriver is the name of the CSI driver provisioning Linode Volumes.
+const linodeCSIDriver = "linodebs.csi.linode.com"
+
+// newWorkloadClient returns the client of the workload cluster from its kubeconfig,
+// which is stored base64 encoded, as returned by the Linode API.
+func newWorkloadClient(kubeconfig []byte) (kubernetes.Interface, error) {
+	if decoded, err := base64.StdEncoding.DecodeString(string(kubeconfig)); err == nil {
+		kubeconfig = decoded
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	config.Timeout = workloadRequestTimeout
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload cluster client: %w", err)
+	}
+
+	return client, nil
+}
+
+// preDeleteCleanup deletes the resources of the workload cluster backed by the
+// Linode resources, as defined by the pre-delete cleanup. True is returned once they
+// are gone, the cleanup is disabled, the kubeconfig is not available, or the cleanup
+// timed out, also when the workload cluster is unreachable. Otherwise the remaining
+// resources are reported in the Deleting condition.
+func (r *LKEClusterConfigReconciler) preDeleteCleanup(
+	ctx context.Context,
+	lke *v1alpha1.LKEClusterConfig,
+) (bool, error) {
+	cleanup := lke.Spec.PreDeleteCleanup
+	if cleanup == nil || (!cleanup.LoadBalancers && !cleanup.Volumes) {
+		return true, nil
+	}
+
+	log := log.FromContext(ctx)
+
+	secret, err := r.KubernetesClient.CoreV1().Secrets(lke.Namespace).
+		Get(ctx, kubeconfigSecretName(lke), metav1.GetOptions{})
+	if err != nil {
+		if !kubeerrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get kubeconfig secret: %w", err)
+		}
+
+		r.event(lke, corev1.EventTypeWarning, "PreDeleteCleanupSkipped",
+			"kubeconfig secret not found, skipping pre-delete cleanup")
+
+		return true, nil
+	}
+
+	remaining, err := r.cleanupWorkloads(ctx, secret.Data[kubeconfigKey], cleanup)
+	if err == nil && remaining == 0 {
+		return true, nil
+	}
+
+	timeout := DefaultPreDeleteCleanupTimeout
+	if cleanup.Timeout != nil {
+		timeout = cleanup.Timeout.Duration
+	}
+
+	if cond := meta.FindStatusCondition(lke.Status.Conditions, v1alpha1.ConditionDeleting); cond != nil &&
+		time.Since(cond.LastTransitionTime.Time) >= timeout {
+		message := fmt.Sprintf("pre-delete cleanup did not finish within %s, deleting LKE cluster", timeout)
+		if err != nil {
+			message += ": " + err.Error()
+		}
+
+		r.event(lke, corev1.EventTypeWarning, "PreDeleteCleanupTimedOut", message)
+
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	log.Info("waiting for pre-delete cleanup", "remaining", remaining)
+
+	r.setDeleting(lke, v1alpha1.ReasonCleaningUpWorkloads,
+		fmt.Sprintf("waiting for %d resources of the workload cluster to be removed", remaining))
+
+	if err := r.Update(ctx, lke); err != nil {
+		return false, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	return false, nil
+}
+
+// cleanupWorkloads deletes the resources of the workload cluster, and returns the
+// number of those not yet removed. Services are removed once the cloud controller
+// manager deletes their NodeBalancers, and PersistentVolumes once the CSI driver
+// deletes their Volumes. StatefulSets with claim templates are scaled to zero, so
+// they do not recreate the claims, and Pods using the claims are deleted, as the
+// claims are not removed while in use.
+func (r *LKEClusterConfigReconciler) cleanupWorkloads(
+	ctx context.Context,
+	kubeconfig []byte,
+	cleanup *v1alpha1.PreDeleteCleanup,
+) (int, error) {
+	newClient := r.newWorkloadClient
+	if newClient == nil {
+		newClient = newWorkloadClient
+	}
+
+	workload, err := newClient(kubeconfig)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := 0
+
+	if cleanup.LoadBalancers {
+		n, err := deleteLoadBalancers(ctx, workload)
+		if err != nil {
+			return 0, err
+		}
+
+		remaining += n
+	}
+
+	if cleanup.Volumes {
+		n, err := deleteVolumes(ctx, workload)
+		if err != nil {
+			return 0, err
+		}
+
+		remaining += n
+	}
+
+	return remaining, nil
+}
+
+func deleteLoadBalancers(ctx context.Context, workload kubernetes.Interface) (int, error) {
+	services, err := workload.CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	remaining := 0
+
+	for _, svc := range services.Items {
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+
+		remaining++
+
+		if svc.DeletionTimestamp != nil {
+			continue
+		}
+
+		if err := workload.CoreV1().Services(svc.Namespace).
+			Delete(ctx, svc.Name, metav1.DeleteOptions{}); err != nil && !kubeerrors.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to delete service %s/%s: %w", svc.Namespace, svc.Name, err)
+		}
+	}
+
+	return remaining, nil
+}
+
+func deleteVolumes(ctx context.Context, workload kubernetes.Interface) (int, error) {
+	if err := scaleDownStatefulSets(ctx, workload); err != nil {
+		return 0, err
+	}
+
+	claims, err := workload.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list persistent volume claims: %w", err)
+	}
+
+	for _, pvc := range claims.Items {
+		if pvc.DeletionTimestamp != nil {
+			continue
+		}
+
+		if err := workload.CoreV1().PersistentVolumeClaims(pvc.Namespace).
+			Delete(ctx, pvc.Name, metav1.DeleteOptions{}); err != nil && !kubeerrors.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to delete persistent volume claim %s/%s: %w", pvc.Namespace, pvc.Name, err)
+		}
+	}
+
+	if err := deletePodsUsingClaims(ctx, workload); err != nil {
+		return 0, err
+	}
+
+	volumes, err := workload.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list persistent volumes: %w", err)
+	}
+
+	remaining := len(claims.Items)
+
+	for _, pv := range volumes.Items {
+		// retained volumes are kept intentionally, and only the volumes of the
+		// Linode CSI driver are backed by Linode Volumes
+		if pv.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimDelete &&
+			pv.Spec.CSI != nil && pv.Spec.CSI.Driver == linodeCSIDriver {
+			remaining++
+		}
+	}
+
+	return remaining, nil
+}
+
+// scaleDownStatefulSets scales the StatefulSets with claim templates to zero, as
+// they would recreate the deleted Pods and claims otherwise.
+func scaleDownStatefulSets(ctx context.Context, workload kubernetes.Interface) error {
+	statefulSets, err := workload.AppsV1().StatefulSets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list stateful sets: %w", err)
+	}
+
+	for _, sts := range statefulSets.Items {
+		if len(sts.Spec.VolumeClaimTemplates) == 0 || (sts.Spec.Replicas != nil && *sts.Spec.Replicas == 0) {
+			continue
+		}
+
+		if _, err := workload.AppsV1().StatefulSets(sts.Namespace).Patch(ctx, sts.Name, types.MergePatchType,
+			[]byte(`{"spec":{"replicas":0}}`), metav1.PatchOptions{}); err != nil && !kubeerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to scale down stateful set %s/%s: %w", sts.Namespace, sts.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func deletePodsUsingClaims(ctx context.Context, workload kubernetes.Interface) error {
+	pods, err := workload.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || !usesClaim(&pod) {
+			continue
+		}
+
+		if err := workload.CoreV1().Pods(pod.Namespace).
+			Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !kubeerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func usesClaim(pod *corev1.Pod) bool {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil || vol.Ephemeral != nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+func TestLKEClusterConfigReconciler_preDeleteCleanup(t *testing.T) {
+	t.Parallel()
+
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-kubeconfig"},
+		Data:       map[string][]byte{kubeconfigKey: []byte("kubeconfig")},
+	}
+
+	workloads := []runtime.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "lb"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "internal"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data"},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-data"},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: linodeCSIDriver, VolumeHandle: "1-data"},
+				},
+			},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "retained"},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: linodeCSIDriver, VolumeHandle: "2-retained"},
+				},
+			},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "local-path"},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: "/opt/local-path-provisioner/data"},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"},
+			Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+				},
+			}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: mkptr(int32(3)),
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+					ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				}},
+			},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cache"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: mkptr(int32(2))},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		cleanup           *v1alpha1.PreDeleteCleanup
+		noKubeconfig      bool
+		unreachable       bool
+		elapsed           time.Duration
+		expectedDone      bool
+		expectedErr       bool
+		expectedServices  []string
+		expectedClaims    []string
+		expectedPods      []string
+		expectedReplicas  map[string]int32
+		expectedCondition bool
+	}{
+		"disabled": {
+			expectedDone:     true,
+			expectedServices: []string{"internal", "lb"},
+			expectedClaims:   []string{"data"},
+			expectedPods:     []string{"db", "web"},
+			expectedReplicas: map[string]int32{"cache": 2, "db": 3},
+		},
+		"no_kubeconfig": {
+			cleanup:          &v1alpha1.PreDeleteCleanup{LoadBalancers: true, Volumes: true},
+			noKubeconfig:     true,
+			expectedDone:     true,
+			expectedServices: []string{"internal", "lb"},
+			expectedClaims:   []string{"data"},
+			expectedPods:     []string{"db", "web"},
+			expectedReplicas: map[string]int32{"cache": 2, "db": 3},
+		},
+		"load_balancers": {
+			cleanup:           &v1alpha1.PreDeleteCleanup{LoadBalancers: true},
+			expectedServices:  []string{"internal"},
+			expectedClaims:    []string{"data"},
+			expectedPods:      []string{"db", "web"},
+			expectedReplicas:  map[string]int32{"cache": 2, "db": 3},
+			expectedCondition: true,
+		},
+		"volumes": {
+			cleanup:           &v1alpha1.PreDeleteCleanup{Volumes: true},
+			expectedServices:  []string{"internal", "lb"},
+			expectedClaims:    []string{},
+			expectedPods:      []string{"web"},
+			expectedReplicas:  map[string]int32{"cache": 2, "db": 0},
+			expectedCondition: true,
+		},
+		"timed_out": {
+			cleanup: &v1alpha1.PreDeleteCleanup{
+				LoadBalancers: true,
+				Volumes:       true,
+				Timeout:       &metav1.Duration{Duration: time.Minute},
+			},
+			elapsed:          time.Hour,
+			expectedDone:     true,
+			expectedServices: []string{"internal"},
+			expectedClaims:   []string{},
+			expectedPods:     []string{"web"},
+			expectedReplicas: map[string]int32{"cache": 2, "db": 0},
+		},
+		"default_timeout": {
+			cleanup:          &v1alpha1.PreDeleteCleanup{LoadBalancers: true},
+			elapsed:          time.Hour,
+			expectedDone:     true,
+			expectedServices: []string{"internal"},
+			expectedClaims:   []string{"data"},
+			expectedPods:     []string{"db", "web"},
+			expectedReplicas: map[string]int32{"cache": 2, "db": 3},
+		},
+		"unreachable": {
+			cleanup:          &v1alpha1.PreDeleteCleanup{LoadBalancers: true},
+			unreachable:      true,
+			expectedErr:      true,
+			expectedServices: []string{"internal", "lb"},
+			expectedClaims:   []string{"data"},
+			expectedPods:     []string{"db", "web"},
+			expectedReplicas: map[string]int32{"cache": 2, "db": 3},
+		},
+		"unreachable_timed_out": {
+			cleanup:          &v1alpha1.PreDeleteCleanup{LoadBalancers: true},
+			unreachable:      true,
+			elapsed:          time.Hour,
+			expectedDone:     true,
+			expectedServices: []string{"internal", "lb"},
+			expectedClaims:   []string{"data"},
+			expectedPods:     []string{"db", "web"},
+			expectedReplicas: map[string]int32{"cache": 2, "db": 3},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lke := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+				Spec:       v1alpha1.LKEClusterConfigSpec{PreDeleteCleanup: tc.cleanup},
+				Status: v1alpha1.LKEClusterConfigStatus{
+					Conditions: []metav1.Condition{{
+						Type:               v1alpha1.ConditionDeleting,
+						Status:             metav1.ConditionTrue,
+						Reason:             v1alpha1.ReasonDeletingCluster,
+						LastTransitionTime: metav1.NewTime(time.Now().Add(-max(tc.elapsed, time.Minute))),
+					}},
+				},
+			}
+
+			objects := []runtime.Object{}
+			if !tc.noKubeconfig {
+				objects = append(objects, kubeconfig.DeepCopy())
+			}
+
+			workload := kubefake.NewSimpleClientset(workloads...)
+
+			r := &LKEClusterConfigReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme(t)).
+					WithObjects(lke).
+					Build(),
+				KubernetesClient: kubefake.NewSimpleClientset(objects...),
+				Recorder:         record.NewFakeRecorder(10),
+				newWorkloadClient: func([]byte) (kubernetes.Interface, error) {
+					if tc.unreachable {
+						return nil, errors.New("unreachable")
+					}
+
+					return workload, nil
+				},
+			}
+
+			done, err := r.preDeleteCleanup(context.Background(), lke)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %#+v, got: %#+v", tc.expectedErr, err)
+			}
+
+			if done != tc.expectedDone {
+				t.Errorf("expected Done value: %#+v, got: %#+v",
+					tc.expectedDone, done)
+			}
+
+			if reason := lke.Status.Conditions[0].Reason; (reason == v1alpha1.ReasonCleaningUpWorkloads) !=
+				tc.expectedCondition {
+				t.Errorf("expected Condition value: %#+v, got: %#+v",
+					tc.expectedCondition, reason)
+			}
+
+			ctx := context.Background()
+
+			services, err := workload.CoreV1().Services("default").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			claims, err := workload.CoreV1().PersistentVolumeClaims("default").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pods, err := workload.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			statefulSets, err := workload.AppsV1().StatefulSets("default").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			replicas := map[string]int32{}
+			for _, sts := range statefulSets.Items {
+				replicas[sts.Name] = deref(sts.Spec.Replicas)
+			}
+
+			if !reflect.DeepEqual(replicas, tc.expectedReplicas) {
+				t.Errorf("expected Replicas value: %#+v, got: %#+v",
+					tc.expectedReplicas, replicas)
+			}
+
+			for _, check := range []struct {
+				kind     string
+				expected []string
+				actual   []string
+			}{
+				{"Services", tc.expectedServices, names(services.Items, func(o corev1.Service) string { return o.Name })},
+				{"Claims", tc.expectedClaims, names(claims.Items, func(o corev1.PersistentVolumeClaim) string { return o.Name })},
+				{"Pods", tc.expectedPods, names(pods.Items, func(o corev1.Pod) string { return o.Name })},
+			} {
+				if !reflect.DeepEqual(check.actual, check.expected) {
+					t.Errorf("expected %s value: %#+v, got: %#+v",
+						check.kind, check.expected, check.actual)
+				}
+			}
+		})
+	}
+}
+
+func names[T any](items []T, name func(T) string) []string {
+	out := []string{}
+	for _, item := range items {
+		out = append(out, name(item))
+	}
+
+	slices.Sort(out)
+
+	return out
+}
@@ -19,11 +19,15 @@ package controller
 const (
 	lkeFinalizer      = "lke.anza-labs.dev/finalizer"
 	lkeTagsAnnotation = "lke.anza-labs.dev/tags"
+	lkeOwnerUIDLabel  = "lke.anza-labs.dev/owner-uid"
 
 	lkeOperatorTag = "lke-operator.name="
 	kubeconfigKey  = "kubeconfig"
 
 	statusReady = "ready"
+
+	tokenSecretRefField = ".spec.tokenSecretRef"
+	credentialsRefField = ".spec.credentialsRef"
 )
 
 func mkptr[T any](t T) *T {
@@ -0,0 +1,268 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+// DefaultDeletionTimeout is the default time to wait for the LKE cluster to
+// disappear after requesting its deletion.
+const DefaultDeletionTimeout = 30 * time.Minute
+
+// kubeconfigSecretName returns the name of the Secret holding the kubeconfig.
+func kubeconfigSecretName(lke *v1alpha1.LKEClusterConfig) string {
+	return lke.Name + "-kubeconfig"
+}
+
+// publishedSecretLabels returns the labels of the Secrets published by the operator,
+// used to find and delete them together with the object.
+func publishedSecretLabels(lke *v1alpha1.LKEClusterConfig) map[string]string {
+	return map[string]string{lkeOwnerUIDLabel: string(lke.UID)}
+}
+
+// OnDelete must be idempotent
+func (r *LKEClusterConfigReconciler) OnDelete(
+	ctx context.Context,
+	lke *v1alpha1.LKEClusterConfig,
+) (ctrl.Result, error) {
+	client, err := r.newLKEClient(ctx, lke)
+	if err != nil {
+		if lke.Status.ClusterID != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to create client: %w", err)
+		}
+
+		// the cluster was most likely never created, and there is no way to look for it
+		log.FromContext(ctx).Info("skipping cluster lookup, client unavailable", "error", err.Error())
+	}
+
+	lke.Status.Phase = mkptr(v1alpha1.PhaseDeleting)
+
+	if meta.FindStatusCondition(lke.Status.Conditions, v1alpha1.ConditionDeleting) == nil {
+		r.setDeleting(lke, v1alpha1.ReasonDeletingCluster, "deleting LKE cluster")
+	}
+
+	if err := r.Update(ctx, lke); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to set phase %s: %w",
+			v1alpha1.PhaseDeleting,
+			err,
+		)
+	}
+
+	return r.onDelete(ctx, client, lke)
+}
+
+// onDelete deletes the LKE cluster, waits for it to disappear, and then deletes
+// the Secrets published by the operator. Empty result is returned once everything
+// is gone, and the finalizer can be removed. Client is nil if it could not be
+// created for an object without the cluster ID.
+func (r *LKEClusterConfigReconciler) onDelete(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+) (ctrl.Result, error) {
+	if client != nil {
+		res, err := r.deleteCluster(ctx, client, lke)
+		if err != nil || !res.IsZero() {
+			return res, err
+		}
+	}
+
+	r.setDeleting(lke, v1alpha1.ReasonCleaningUp, "deleting published secrets")
+
+	if err := r.Update(ctx, lke); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	if err := r.deletePublishedSecrets(ctx, lke); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// deleteCluster requests the deletion of the LKE cluster, once the pre-delete cleanup
+// is done, and requeues the object until the cluster disappears. If the cluster still
+// exists after the deletion timeout, measured from the last request, the timeout is
+// reported and the deletion requested again. Cluster of the object without the
+// cluster ID is looked up by its UID ownership tag, in case the status was lost
+// after creating it.
+func (r *LKEClusterConfigReconciler) deleteCluster(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+) (ctrl.Result, error) {
+	if lke.Status.ClusterID == nil {
+		cluster, err := findCluster(ctx, client, lke, r.instanceID())
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to find cluster: %w", err)
+		}
+
+		// clusters matched only by the namespace and name may belong to another object
+		if cluster == nil || !slices.Contains(cluster.Tags, ownerUIDTag(lke)) {
+			return ctrl.Result{}, nil
+		}
+
+		lke.Status.ClusterID = &cluster.ID
+	}
+
+	clusterID := *lke.Status.ClusterID
+
+	if _, err := client.GetLKECluster(ctx, clusterID); err != nil {
+		if !errors.Is(err, internalerrors.ErrLinodeNotFound) {
+			return ctrl.Result{}, fmt.Errorf("failed to get cluster: %w", err)
+		}
+
+		r.reportLeftovers(ctx, client, lke, clusterID)
+
+		return ctrl.Result{}, nil
+	}
+
+	// the time is recorded only once the deletion was requested, so the failed
+	// request is retried instead of waiting for the timeout
+	requestedAt := deletionRequestedAt(lke)
+
+	var reason, message string
+
+	switch {
+	case requestedAt == nil:
+		done, err := r.preDeleteCleanup(ctx, lke)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to clean up workload cluster: %w", err)
+		}
+
+		if !done {
+			return r.requeue(lke, v1alpha1.PhaseDeleting), nil
+		}
+
+		reason = v1alpha1.ReasonWaitingForClusterRemoval
+		message = fmt.Sprintf("waiting for LKE cluster %d to be removed", clusterID)
+
+	case time.Since(requestedAt.Time) < r.deletionTimeout():
+		return r.requeue(lke, v1alpha1.PhaseDeleting), nil
+
+	default:
+		r.event(lke, corev1.EventTypeWarning, v1alpha1.ReasonDeletionTimedOut,
+			fmt.Sprintf("LKE cluster %d still exists %s after requesting its deletion, requesting it again",
+				clusterID, r.deletionTimeout()))
+
+		reason = v1alpha1.ReasonDeletionTimedOut
+		message = fmt.Sprintf("LKE cluster %d still exists %s after requesting its deletion",
+			clusterID, r.deletionTimeout())
+	}
+
+	if err := client.DeleteLKECluster(ctx, clusterID); err != nil {
+		if !errors.Is(err, internalerrors.ErrLinodeNotFound) {
+			return ctrl.Result{}, fmt.Errorf("failed to remove cluster: %w", err)
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	lke.Status.DeletionRequestedAt = mkptr(metav1.Now())
+	r.setDeleting(lke, reason, message)
+
+	if err := r.Update(ctx, lke); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	return r.requeue(lke, v1alpha1.PhaseDeleting), nil
+}
+
+// deletionRequestedAt returns the time the deletion of the LKE cluster was last
+// requested, or nil if it was not requested yet. Deletion requested by the older
+// versions of the operator is only reported by the Deleting condition.
+func deletionRequestedAt(lke *v1alpha1.LKEClusterConfig) *metav1.Time {
+	if lke.Status.DeletionRequestedAt != nil {
+		return lke.Status.DeletionRequestedAt
+	}
+
+	cond := meta.FindStatusCondition(lke.Status.Conditions, v1alpha1.ConditionDeleting)
+	if cond != nil && (cond.Reason == v1alpha1.ReasonWaitingForClusterRemoval ||
+		cond.Reason == v1alpha1.ReasonDeletionTimedOut) {
+		return &cond.LastTransitionTime
+	}
+
+	return nil
+}
+
+// deletePublishedSecrets deletes the kubeconfig Secret, and all other Secrets
+// labeled as published for the object. Kubeconfig is deleted by its name, as
+// it was not labeled by the older versions of the operator.
+func (r *LKEClusterConfigReconciler) deletePublishedSecrets(
+	ctx context.Context,
+	lke *v1alpha1.LKEClusterConfig,
+) error {
+	sc := r.KubernetesClient.CoreV1().Secrets(lke.Namespace)
+
+	if err := sc.Delete(ctx, kubeconfigSecretName(lke), metav1.DeleteOptions{}); err != nil &&
+		!kubeerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete kubeconfig secret: %w", err)
+	}
+
+	secrets, err := sc.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(publishedSecretLabels(lke)).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list published secrets: %w", err)
+	}
+
+	for _, secret := range secrets.Items {
+		if err := sc.Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil &&
+			!kubeerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete secret %s: %w", secret.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// setDeleting sets the Deleting condition, reporting the progress of the deletion.
+// The transition time is kept between the steps, marking the start of the deletion.
+func (r *LKEClusterConfigReconciler) setDeleting(lke *v1alpha1.LKEClusterConfig, reason, message string) {
+	meta.SetStatusCondition(&lke.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionDeleting,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: lke.Generation,
+	})
+}
+
+func (r *LKEClusterConfigReconciler) deletionTimeout() time.Duration {
+	if r.DeletionTimeout > 0 {
+		return r.DeletionTimeout
+	}
+
+	return DefaultDeletionTimeout
+}
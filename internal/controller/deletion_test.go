@@ -0,0 +1,379 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	lkefake "github.com/anza-labs/lke-operator/internal/lkeclient/fake"
+)
+
+type deletionClient struct {
+	lkeclient.Client
+
+	clusters []linodego.LKECluster
+	deleted  []int
+}
+
+func (c *deletionClient) ListLKEClusters(context.Context, *linodego.ListOptions) ([]linodego.LKECluster, error) {
+	return c.clusters, nil
+}
+
+func (c *deletionClient) GetLKECluster(_ context.Context, clusterID int) (*linodego.LKECluster, error) {
+	for i := range c.clusters {
+		if c.clusters[i].ID == clusterID {
+			return &c.clusters[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("cluster %d: %w", clusterID, internalerrors.ErrLinodeNotFound)
+}
+
+func (c *deletionClient) ListNodeBalancers(context.Context, *linodego.ListOptions) ([]linodego.NodeBalancer, error) {
+	return nil, nil
+}
+
+func (c *deletionClient) ListVolumes(context.Context, *linodego.ListOptions) ([]linodego.Volume, error) {
+	return nil, nil
+}
+
+func (c *deletionClient) DeleteLKECluster(_ context.Context, clusterID int) error {
+	c.deleted = append(c.deleted, clusterID)
+	return nil
+}
+
+func TestLKEClusterConfigReconciler_onDelete(t *testing.T) {
+	t.Parallel()
+
+	lke := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "test-uid"},
+	}
+
+	secrets := []corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-kubeconfig"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-extra", Labels: publishedSecretLabels(lke)}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unrelated"}},
+	}
+
+	owned := linodego.LKECluster{ID: 1, Tags: ownershipTags(lke, DefaultInstanceID)}
+	byRef := linodego.LKECluster{ID: 2, Tags: []string{"lke-operator.uid=other", ownerRefTag(lke)}}
+
+	for name, tc := range map[string]struct {
+		noClient        bool
+		clusterID       *int
+		clusters        []linodego.LKECluster
+		condition       *metav1.Condition
+		requestedAt     *metav1.Time
+		expectedRequeue bool
+		expectedDeleted []int
+		expectedReason  string
+		expectedSecrets []string
+	}{
+		"no_client": {
+			noClient:        true,
+			expectedReason:  v1alpha1.ReasonCleaningUp,
+			expectedSecrets: []string{"unrelated"},
+		},
+		"never_created": {
+			clusters:        []linodego.LKECluster{byRef},
+			expectedReason:  v1alpha1.ReasonCleaningUp,
+			expectedSecrets: []string{"unrelated"},
+		},
+		"lost_cluster_id": {
+			clusters:        []linodego.LKECluster{owned},
+			expectedRequeue: true,
+			expectedDeleted: []int{1},
+			expectedReason:  v1alpha1.ReasonWaitingForClusterRemoval,
+			expectedSecrets: []string{"test-extra", "test-kubeconfig", "unrelated"},
+		},
+		"delete": {
+			clusterID:       mkptr(1),
+			clusters:        []linodego.LKECluster{owned},
+			expectedRequeue: true,
+			expectedDeleted: []int{1},
+			expectedReason:  v1alpha1.ReasonWaitingForClusterRemoval,
+			expectedSecrets: []string{"test-extra", "test-kubeconfig", "unrelated"},
+		},
+		"waiting": {
+			clusterID: mkptr(1),
+			clusters:  []linodego.LKECluster{owned},
+			condition: &metav1.Condition{
+				Type:               v1alpha1.ConditionDeleting,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1alpha1.ReasonWaitingForClusterRemoval,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute)),
+			},
+			expectedRequeue: true,
+			expectedReason:  v1alpha1.ReasonWaitingForClusterRemoval,
+			expectedSecrets: []string{"test-extra", "test-kubeconfig", "unrelated"},
+		},
+		"timed_out": {
+			clusterID: mkptr(1),
+			clusters:  []linodego.LKECluster{owned},
+			condition: &metav1.Condition{
+				Type:               v1alpha1.ConditionDeleting,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1alpha1.ReasonWaitingForClusterRemoval,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			},
+			expectedRequeue: true,
+			expectedDeleted: []int{1},
+			expectedReason:  v1alpha1.ReasonDeletionTimedOut,
+			expectedSecrets: []string{"test-extra", "test-kubeconfig", "unrelated"},
+		},
+		"cleanup_before_request": {
+			clusterID: mkptr(1),
+			clusters:  []linodego.LKECluster{owned},
+			condition: &metav1.Condition{
+				Type:               v1alpha1.ConditionDeleting,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1alpha1.ReasonDeletingCluster,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			},
+			expectedRequeue: true,
+			expectedDeleted: []int{1},
+			expectedReason:  v1alpha1.ReasonWaitingForClusterRemoval,
+			expectedSecrets: []string{"test-extra", "test-kubeconfig", "unrelated"},
+		},
+		"requested_recently": {
+			clusterID: mkptr(1),
+			clusters:  []linodego.LKECluster{owned},
+			condition: &metav1.Condition{
+				Type:               v1alpha1.ConditionDeleting,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1alpha1.ReasonDeletionTimedOut,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			},
+			requestedAt:     mkptr(metav1.NewTime(time.Now().Add(-time.Minute))),
+			expectedRequeue: true,
+			expectedReason:  v1alpha1.ReasonDeletionTimedOut,
+			expectedSecrets: []string{"test-extra", "test-kubeconfig", "unrelated"},
+		},
+		"requested_long_ago": {
+			clusterID: mkptr(1),
+			clusters:  []linodego.LKECluster{owned},
+			condition: &metav1.Condition{
+				Type:               v1alpha1.ConditionDeleting,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1alpha1.ReasonDeletionTimedOut,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			requestedAt:     mkptr(metav1.NewTime(time.Now().Add(-time.Hour))),
+			expectedRequeue: true,
+			expectedDeleted: []int{1},
+			expectedReason:  v1alpha1.ReasonDeletionTimedOut,
+			expectedSecrets: []string{"test-extra", "test-kubeconfig", "unrelated"},
+		},
+		"removed": {
+			clusterID: mkptr(1),
+			condition: &metav1.Condition{
+				Type:               v1alpha1.ConditionDeleting,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1alpha1.ReasonWaitingForClusterRemoval,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute)),
+			},
+			expectedReason:  v1alpha1.ReasonCleaningUp,
+			expectedSecrets: []string{"unrelated"},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := lke.DeepCopy()
+			obj.Status.ClusterID = tc.clusterID
+			obj.Status.DeletionRequestedAt = tc.requestedAt
+
+			if tc.condition != nil {
+				obj.Status.Conditions = []metav1.Condition{*tc.condition}
+			}
+
+			kubernetesClient := kubefake.NewSimpleClientset()
+			for i := range secrets {
+				if _, err := kubernetesClient.CoreV1().Secrets("default").
+					Create(context.Background(), secrets[i].DeepCopy(), metav1.CreateOptions{}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			r := &LKEClusterConfigReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme(t)).
+					WithObjects(obj).
+					Build(),
+				KubernetesClient: kubernetesClient,
+				Recorder:         record.NewFakeRecorder(10),
+				DeletionTimeout:  30 * time.Minute,
+			}
+
+			var client lkeclient.Client
+
+			stub := &deletionClient{clusters: tc.clusters}
+			if !tc.noClient {
+				client = stub
+			}
+
+			res, err := r.onDelete(context.Background(), client, obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if requeue := !res.IsZero(); requeue != tc.expectedRequeue {
+				t.Errorf("expected Requeue value: %#+v, got: %#+v",
+					tc.expectedRequeue, requeue)
+			}
+
+			if !reflect.DeepEqual(stub.deleted, tc.expectedDeleted) {
+				t.Errorf("expected Deleted value: %#+v, got: %#+v",
+					tc.expectedDeleted, stub.deleted)
+			}
+
+			if len(tc.expectedDeleted) > 0 &&
+				(obj.Status.DeletionRequestedAt == nil || time.Since(obj.Status.DeletionRequestedAt.Time) > time.Minute) {
+				t.Errorf("expected DeletionRequestedAt to be updated, got: %#+v",
+					obj.Status.DeletionRequestedAt)
+			}
+
+			cond := meta.FindStatusCondition(obj.Status.Conditions, v1alpha1.ConditionDeleting)
+			if cond == nil || cond.Reason != tc.expectedReason {
+				t.Errorf("expected Reason value: %#+v, got: %#+v",
+					tc.expectedReason, cond)
+			}
+
+			list, err := kubernetesClient.CoreV1().Secrets("default").
+				List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			secrets := names(list.Items, func(o corev1.Secret) string { return o.Name })
+			if !reflect.DeepEqual(secrets, tc.expectedSecrets) {
+				t.Errorf("expected Secrets value: %#+v, got: %#+v",
+					tc.expectedSecrets, secrets)
+			}
+		})
+	}
+}
+
+func TestLKEClusterConfigReconciler_onDelete_fake(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		inject          func(c *lkefake.Client)
+		reconciles      int
+		expectedErr     bool
+		expectedDeletes int
+		expectedExists  bool
+		expectedReason  string
+	}{
+		"deleted": {
+			reconciles:      2,
+			expectedDeletes: 1,
+			expectedReason:  v1alpha1.ReasonCleaningUp,
+		},
+		"delete_failure": {
+			inject: func(c *lkefake.Client) {
+				c.InjectError("DeleteLKECluster", lkefake.Unavailable(), 0)
+			},
+			expectedErr:     true,
+			expectedDeletes: 1,
+			expectedExists:  true,
+		},
+		"delete_failure_retried": {
+			inject: func(c *lkefake.Client) {
+				c.InjectError("DeleteLKECluster", lkefake.Unavailable(), 1)
+			},
+			reconciles:      2,
+			expectedDeletes: 2,
+			expectedReason:  v1alpha1.ReasonWaitingForClusterRemoval,
+		},
+		"get_failure": {
+			inject: func(c *lkefake.Client) {
+				c.InjectError("GetLKECluster", lkefake.Unavailable(), 0)
+			},
+			expectedErr:    true,
+			expectedExists: true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "test-uid"},
+				Status:     v1alpha1.LKEClusterConfigStatus{ClusterID: mkptr(1)},
+			}
+
+			client := lkefake.NewClient()
+			client.AddCluster(linodego.LKECluster{ID: 1, Tags: ownershipTags(obj, DefaultInstanceID)})
+
+			if tc.inject != nil {
+				tc.inject(client)
+			}
+
+			r := &LKEClusterConfigReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme(t)).
+					WithObjects(obj).
+					Build(),
+				KubernetesClient: kubefake.NewSimpleClientset(),
+				Recorder:         record.NewFakeRecorder(100),
+			}
+
+			var err error
+			for range max(tc.reconciles, 1) {
+				_, err = r.onDelete(context.Background(), client, obj)
+			}
+
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %#+v, got: %#+v", tc.expectedErr, err)
+			}
+
+			if deletes := len(client.CallsTo("DeleteLKECluster")); deletes != tc.expectedDeletes {
+				t.Errorf("expected Deletes value: %#+v, got: %#+v", tc.expectedDeletes, deletes)
+			}
+
+			if _, exists := client.Cluster(1); exists != tc.expectedExists {
+				t.Errorf("expected Exists value: %#+v, got: %#+v", tc.expectedExists, exists)
+			}
+
+			reason := ""
+			if cond := meta.FindStatusCondition(obj.Status.Conditions, v1alpha1.ConditionDeleting); cond != nil {
+				reason = cond.Reason
+			}
+
+			if reason != tc.expectedReason {
+				t.Errorf("expected Reason value: %#+v, got: %#+v", tc.expectedReason, reason)
+			}
+		})
+	}
+}
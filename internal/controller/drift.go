@@ -0,0 +1,180 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/linode/linodego"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+// detectDrift compares the live state of the LKE cluster with the spec and the
+// rendered label, and returns the sorted list of differences.
+func detectDrift(
+	lke *v1alpha1.LKEClusterConfig,
+	label string,
+	cluster *linodego.LKECluster,
+	pools []linodego.LKENodePool,
+) []string {
+	drift := []string{}
+
+	if cluster.Label != label {
+		drift = append(drift, fmt.Sprintf("label: expected %s, got %s", label, cluster.Label))
+	}
+
+	ha := lke.Spec.HighAvailability != nil && *lke.Spec.HighAvailability
+	if cluster.ControlPlane.HighAvailability != ha {
+		drift = append(drift, fmt.Sprintf("highAvailability: expected %t, got %t",
+			ha, cluster.ControlPlane.HighAvailability))
+	}
+
+	if rawTags, ok := lke.Annotations[lkeTagsAnnotation]; ok {
+		tags := extractTags(rawTags)
+		live := userTags(cluster.Tags)
+
+		slices.Sort(tags)
+		slices.Sort(live)
+
+		if !slices.Equal(tags, live) {
+			drift = append(drift, fmt.Sprintf("tags: expected [%s], got [%s]",
+				strings.Join(tags, ","), strings.Join(live, ",")))
+		}
+	}
+
+	live := generateNodePoolStatusesFromAPI(pools)
+
+	for name, spec := range lke.Spec.NodePools {
+		status, ok := live[name]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("nodePools.%s: missing", name))
+			continue
+		}
+
+		if !nodePoolMatches(spec, status.NodePoolDetails) {
+			drift = append(drift, fmt.Sprintf("nodePools.%s: expected %s, got %s",
+				name, describeNodePool(spec), describeNodePool(status.NodePoolDetails)))
+		}
+	}
+
+	for name := range live {
+		if _, ok := lke.Spec.NodePools[name]; !ok {
+			drift = append(drift, fmt.Sprintf("nodePools.%s: not in spec", name))
+		}
+	}
+
+	if lke.Spec.UnmanagedNodePools == v1alpha1.UnmanagedNodePoolsDelete {
+		for _, np := range unmanagedNodePools(pools) {
+			drift = append(drift, fmt.Sprintf("unmanagedNodePools.%d: not deleted", np.ID))
+		}
+	}
+
+	slices.Sort(drift)
+
+	return drift
+}
+
+// nodePoolMatches checks if the live node pool matches the spec. Node count
+// of autoscaled pools is not compared, as it is managed by the autoscaler.
+func nodePoolMatches(spec, live v1alpha1.LKENodePool) bool {
+	if spec.Autoscaler != nil {
+		live.NodeCount = spec.NodeCount
+	}
+
+	return spec.IsEqual(live)
+}
+
+func describeNodePool(np v1alpha1.LKENodePool) string {
+	if np.Autoscaler == nil {
+		return fmt.Sprintf("%d x %s", np.NodeCount, np.LinodeType)
+	}
+
+	return fmt.Sprintf("%d x %s (autoscaler %d-%d)",
+		np.NodeCount, np.LinodeType, np.Autoscaler.Min, np.Autoscaler.Max)
+}
+
+// reconciled checks if the current spec of the object, its tags and the rendered
+// label were already successfully applied, so any difference between them and
+// the LKE cluster was made outside of the operator.
+func reconciled(lke *v1alpha1.LKEClusterConfig, label string) bool {
+	cond := meta.FindStatusCondition(lke.Status.Conditions, v1alpha1.ConditionReady)
+
+	return cond != nil &&
+		cond.Status == metav1.ConditionTrue &&
+		cond.ObservedGeneration == lke.Generation &&
+		lke.Status.AppliedHash == appliedHash(lke, label)
+}
+
+// appliedHash returns the hash of the tags annotation and the rendered label,
+// which are applied to the LKE cluster without changing the generation.
+func appliedHash(lke *v1alpha1.LKEClusterConfig, label string) string {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "label\t%s\n", label)
+
+	if rawTags, ok := lke.Annotations[lkeTagsAnnotation]; ok {
+		tags := extractTags(rawTags)
+		slices.Sort(tags)
+
+		fmt.Fprintf(h, "tags\t%s\n", strings.Join(tags, ","))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:planHashLength]
+}
+
+// recordDrift sets the Drifted condition and emits the Event if the drift
+// changed. It reports if the drift should be corrected.
+func (r *LKEClusterConfigReconciler) recordDrift(lke *v1alpha1.LKEClusterConfig, drift []string) bool {
+	cond := metav1.Condition{
+		Type:               v1alpha1.ConditionDrifted,
+		Status:             metav1.ConditionFalse,
+		Reason:             v1alpha1.ReasonNoDrift,
+		Message:            "LKE cluster matches the spec",
+		ObservedGeneration: lke.Generation,
+	}
+
+	correct := len(drift) != 0 && lke.Spec.DriftPolicy == v1alpha1.DriftPolicyCorrect
+
+	if len(drift) != 0 {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = v1alpha1.ReasonDriftDetected
+		cond.Message = strings.Join(drift, "; ")
+
+		if correct {
+			cond.Reason = v1alpha1.ReasonDriftCorrected
+		}
+	}
+
+	if meta.SetStatusCondition(&lke.Status.Conditions, cond) {
+		eventType := corev1.EventTypeWarning
+		if cond.Status == metav1.ConditionFalse {
+			eventType = corev1.EventTypeNormal
+		}
+
+		r.event(lke, eventType, cond.Reason, cond.Message)
+	}
+
+	return correct
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/linode/linodego"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func Test_detectDrift(t *testing.T) {
+	t.Parallel()
+
+	spec := v1alpha1.LKEClusterConfigSpec{
+		NodePools: map[string]v1alpha1.LKENodePool{
+			"default": {NodeCount: 3, LinodeType: "g6-standard-1"},
+			"scaled": {
+				NodeCount:  3,
+				LinodeType: "g6-standard-2",
+				Autoscaler: &v1alpha1.LKENodePoolAutoscaler{Min: 3, Max: 10},
+			},
+		},
+	}
+
+	matchingPools := []linodego.LKENodePool{
+		{ID: 1, Count: 3, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"}},
+		{
+			ID:         2,
+			Count:      7,
+			Type:       "g6-standard-2",
+			Tags:       []string{lkeOperatorTag + "scaled"},
+			Autoscaler: linodego.LKENodePoolAutoscaler{Enabled: true, Min: 3, Max: 10},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		annotations   map[string]string
+		cluster       *linodego.LKECluster
+		pools         []linodego.LKENodePool
+		expectedDrift []string
+	}{
+		"no_drift": {
+			cluster:       &linodego.LKECluster{Label: "test"},
+			pools:         matchingPools,
+			expectedDrift: []string{},
+		},
+		"high_availability": {
+			cluster: &linodego.LKECluster{
+				Label:        "test",
+				ControlPlane: linodego.LKEClusterControlPlane{HighAvailability: true},
+			},
+			pools:         matchingPools,
+			expectedDrift: []string{"highAvailability: expected false, got true"},
+		},
+		"tags": {
+			annotations:   map[string]string{lkeTagsAnnotation: "foo,bar"},
+			cluster:       &linodego.LKECluster{Label: "test", Tags: []string{"foo"}},
+			pools:         matchingPools,
+			expectedDrift: []string{"tags: expected [bar,foo], got [foo]"},
+		},
+		"resized_and_added_pool": {
+			cluster: &linodego.LKECluster{Label: "test"},
+			pools: []linodego.LKENodePool{
+				{ID: 1, Count: 5, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"}},
+				matchingPools[1],
+				{ID: 3, Count: 1, Type: "g6-standard-1"},
+			},
+			expectedDrift: []string{
+				"nodePools.default: expected 3 x g6-standard-1, got 5 x g6-standard-1",
+			},
+		},
+		"renamed": {
+			cluster:       &linodego.LKECluster{Label: "renamed"},
+			pools:         matchingPools,
+			expectedDrift: []string{"label: expected test, got renamed"},
+		},
+		"deleted_pool": {
+			cluster:       &linodego.LKECluster{Label: "test"},
+			pools:         matchingPools[1:],
+			expectedDrift: []string{"nodePools.default: missing"},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lke := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: v1.ObjectMeta{Annotations: tc.annotations},
+				Spec:       spec,
+			}
+
+			drift := detectDrift(lke, "test", tc.cluster, tc.pools)
+			if !reflect.DeepEqual(drift, tc.expectedDrift) {
+				t.Errorf("expected Drift value: %#+v, got: %#+v",
+					tc.expectedDrift, drift)
+			}
+		})
+	}
+}
+
+func TestLKEClusterConfigReconciler_recordDrift(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		policy          v1alpha1.DriftPolicy
+		drift           []string
+		expectedCorrect bool
+		expectedStatus  v1.ConditionStatus
+		expectedReason  string
+		expectedEvents  int
+	}{
+		"no_drift": {
+			policy:         v1alpha1.DriftPolicyCorrect,
+			drift:          []string{},
+			expectedStatus: v1.ConditionFalse,
+			expectedReason: v1alpha1.ReasonNoDrift,
+			expectedEvents: 1,
+		},
+		"report": {
+			policy:         v1alpha1.DriftPolicyReport,
+			drift:          []string{"nodePools.default: missing"},
+			expectedStatus: v1.ConditionTrue,
+			expectedReason: v1alpha1.ReasonDriftDetected,
+			expectedEvents: 1,
+		},
+		"correct": {
+			policy:          v1alpha1.DriftPolicyCorrect,
+			drift:           []string{"nodePools.default: missing"},
+			expectedCorrect: true,
+			expectedStatus:  v1.ConditionTrue,
+			expectedReason:  v1alpha1.ReasonDriftCorrected,
+			expectedEvents:  1,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := record.NewFakeRecorder(10)
+			r := &LKEClusterConfigReconciler{Recorder: recorder}
+
+			lke := &v1alpha1.LKEClusterConfig{
+				Spec: v1alpha1.LKEClusterConfigSpec{DriftPolicy: tc.policy},
+			}
+
+			if correct := r.recordDrift(lke, tc.drift); correct != tc.expectedCorrect {
+				t.Errorf("expected Correct value: %#+v, got: %#+v",
+					tc.expectedCorrect, correct)
+			}
+
+			// recording the same drift again must not emit another event
+			r.recordDrift(lke, tc.drift)
+
+			cond := lke.Status.Conditions[0]
+			if cond.Status != tc.expectedStatus {
+				t.Errorf("expected Status value: %#+v, got: %#+v",
+					tc.expectedStatus, cond.Status)
+			}
+
+			if cond.Reason != tc.expectedReason {
+				t.Errorf("expected Reason value: %#+v, got: %#+v",
+					tc.expectedReason, cond.Reason)
+			}
+
+			if len(recorder.Events) != tc.expectedEvents {
+				t.Errorf("expected Events value: %#+v, got: %#+v",
+					tc.expectedEvents, len(recorder.Events))
+			}
+		})
+	}
+}
+
+func Test_reconciled(t *testing.T) {
+	t.Parallel()
+
+	ready := v1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             v1.ConditionTrue,
+		ObservedGeneration: 2,
+	}
+
+	applied := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: v1.ObjectMeta{
+			Generation:  2,
+			Annotations: map[string]string{lkeTagsAnnotation: "foo,bar"},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		conditions         []v1.Condition
+		generation         int64
+		tags               string
+		label              string
+		expectedReconciled bool
+	}{
+		"reconciled": {
+			conditions:         []v1.Condition{ready},
+			generation:         2,
+			tags:               "bar,foo",
+			label:              "cluster",
+			expectedReconciled: true,
+		},
+		"not_ready": {
+			generation: 2,
+			tags:       "foo,bar",
+			label:      "cluster",
+		},
+		"new_generation": {
+			conditions: []v1.Condition{ready},
+			generation: 3,
+			tags:       "foo,bar",
+			label:      "cluster",
+		},
+		"tags_changed": {
+			conditions: []v1.Condition{ready},
+			generation: 2,
+			tags:       "foo,baz",
+			label:      "cluster",
+		},
+		"label_changed": {
+			conditions: []v1.Condition{ready},
+			generation: 2,
+			tags:       "foo,bar",
+			label:      "default-cluster",
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lke := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: v1.ObjectMeta{
+					Generation:  tc.generation,
+					Annotations: map[string]string{lkeTagsAnnotation: tc.tags},
+				},
+				Status: v1alpha1.LKEClusterConfigStatus{
+					Conditions:  tc.conditions,
+					AppliedHash: appliedHash(applied, "cluster"),
+				},
+			}
+
+			if got := reconciled(lke, tc.label); got != tc.expectedReconciled {
+				t.Errorf("expected Reconciled value: %#+v, got: %#+v",
+					tc.expectedReconciled, got)
+			}
+		})
+	}
+}
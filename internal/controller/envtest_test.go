@@ -0,0 +1,532 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/controller"
+	"github.com/anza-labs/lke-operator/internal/credentials"
+	"github.com/anza-labs/lke-operator/internal/emulator"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+const (
+	// envtestTimeout is the time within which the reconciler must reach the
+	// expected state.
+	envtestTimeout = 30 * time.Second
+
+	envtestToken = "envtest-token"
+)
+
+// suite is the API server started by envtest, with the manager running the
+// LKEClusterConfigReconciler against the Linode API emulator.
+type suite struct {
+	client   client.Client
+	emulator *emulator.Server
+}
+
+// env is nil if the envtest binaries are not available.
+var env *suite
+
+func TestMain(m *testing.M) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		os.Exit(m.Run())
+	}
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var err error
+
+	env, err = startSuite(ctx, testEnv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start envtest: %v\n", err)
+		cancel()
+		_ = testEnv.Stop()
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	cancel()
+	env.emulator.Close()
+
+	if err := testEnv.Stop(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to stop envtest: %v\n", err)
+	}
+
+	os.Exit(code)
+}
+
+func startSuite(ctx context.Context, testEnv *envtest.Environment) (*suite, error) {
+	cfg, err := testEnv.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: "0"},
+		HealthProbeBindAddress: "0",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	srv := emulator.NewServer(emulator.Options{
+		ProvisioningDelay: time.Second,
+		DeletionDelay:     time.Second,
+	})
+
+	kubernetesClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := (&controller.LKEClusterConfigReconciler{
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		KubernetesClient: kubernetesClient,
+		LinodeClients: lkeclient.NewCache(lkeclient.CacheOptions{
+			Endpoint:  lkeclient.Endpoint{URL: srv.URL()},
+			RateLimit: 100,
+			Burst:     100,
+		}),
+		DefaultCredentials: credentials.Static(envtestToken),
+		RequeueIntervals: controller.RequeueIntervals{
+			Provisioning: 200 * time.Millisecond,
+			Updating:     200 * time.Millisecond,
+			Deleting:     200 * time.Millisecond,
+		},
+		RateLimiter: controller.NewRateLimiter(10*time.Millisecond, time.Second, 100, 100),
+	}).SetupWithManager(mgr); err != nil {
+		srv.Close()
+		return nil, err
+	}
+
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "manager stopped: %v\n", err)
+		}
+	}()
+
+	return &suite{client: mgr.GetClient(), emulator: srv}, nil
+}
+
+// newSuite returns the running suite, skipping the test if envtest is not available.
+// Tests using the suite are not run in parallel, as they share the emulator faults.
+func newSuite(t *testing.T) *suite {
+	t.Helper()
+
+	if env == nil {
+		t.Skip("KUBEBUILDER_ASSETS is not set, skipping envtest")
+	}
+
+	return env
+}
+
+// namespace creates the namespace for the test.
+func (s *suite) namespace(t *testing.T) string {
+	t.Helper()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "envtest-"}}
+	if err := s.client.Create(context.Background(), ns); err != nil {
+		t.Fatalf("failed to create namespace: %v", err)
+	}
+
+	return ns.Name
+}
+
+// eventually polls the condition until it succeeds, or fails the test after
+// the timeout with the last error.
+func eventually(t *testing.T, what string, condition func() error) {
+	t.Helper()
+
+	var err error
+
+	for deadline := time.Now().Add(envtestTimeout); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		if err = condition(); err == nil {
+			return
+		}
+	}
+
+	t.Fatalf("timed out waiting for %s: %v", what, err)
+}
+
+// get fetches the object, and checks it with the function.
+func (s *suite) get(key types.NamespacedName, check func(lke *v1alpha1.LKEClusterConfig) error) func() error {
+	return func() error {
+		lke := &v1alpha1.LKEClusterConfig{}
+		if err := s.client.Get(context.Background(), key, lke); err != nil {
+			return err
+		}
+
+		return check(lke)
+	}
+}
+
+// update applies the mutation to the latest version of the object.
+func (s *suite) update(t *testing.T, key types.NamespacedName, mutate func(lke *v1alpha1.LKEClusterConfig)) {
+	t.Helper()
+
+	eventually(t, "update of "+key.String(), func() error {
+		lke := &v1alpha1.LKEClusterConfig{}
+		if err := s.client.Get(context.Background(), key, lke); err != nil {
+			return err
+		}
+
+		mutate(lke)
+
+		return s.client.Update(context.Background(), lke)
+	})
+}
+
+func phase(lke *v1alpha1.LKEClusterConfig) v1alpha1.Phase {
+	if lke.Status.Phase == nil {
+		return ""
+	}
+
+	return *lke.Status.Phase
+}
+
+func active(lke *v1alpha1.LKEClusterConfig) error {
+	cond := meta.FindStatusCondition(lke.Status.Conditions, v1alpha1.ConditionReady)
+	if phase(lke) != v1alpha1.PhaseActive || cond == nil || cond.Status != metav1.ConditionTrue ||
+		cond.ObservedGeneration != lke.Generation {
+		return fmt.Errorf("not active: phase %q, condition %#+v", phase(lke), cond)
+	}
+
+	return nil
+}
+
+func notReady(reason string) func(lke *v1alpha1.LKEClusterConfig) error {
+	return func(lke *v1alpha1.LKEClusterConfig) error {
+		cond := meta.FindStatusCondition(lke.Status.Conditions, v1alpha1.ConditionReady)
+		if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != reason {
+			return fmt.Errorf("expected Ready condition with reason %s, got: %#+v", reason, cond)
+		}
+
+		return nil
+	}
+}
+
+// clusterOf returns the emulated LKE cluster and its node pools.
+func (s *suite) clusterOf(lke *v1alpha1.LKEClusterConfig) (linodego.LKECluster, []linodego.LKENodePool, error) {
+	if lke.Status.ClusterID == nil {
+		return linodego.LKECluster{}, nil, errors.New("cluster ID not set")
+	}
+
+	cluster, pools, ok := s.emulator.Cluster(*lke.Status.ClusterID)
+	if !ok {
+		return linodego.LKECluster{}, nil, fmt.Errorf("cluster %d not found", *lke.Status.ClusterID)
+	}
+
+	return cluster, pools, nil
+}
+
+func newEnvtestLKEClusterConfig(namespace string) *v1alpha1.LKEClusterConfig {
+	return &v1alpha1.LKEClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "test"},
+		Spec: v1alpha1.LKEClusterConfigSpec{
+			Region: "us-east",
+			NodePools: map[string]v1alpha1.LKENodePool{
+				"default": {NodeCount: 1, LinodeType: "g6-standard-1"},
+			},
+		},
+	}
+}
+
+func TestEnvtest_Lifecycle(t *testing.T) {
+	s := newSuite(t)
+	ctx := context.Background()
+
+	lke := newEnvtestLKEClusterConfig(s.namespace(t))
+	key := client.ObjectKeyFromObject(lke)
+
+	if err := s.client.Create(ctx, lke); err != nil {
+		t.Fatalf("failed to create object: %v", err)
+	}
+
+	eventually(t, "finalizer and provisioning", s.get(key, func(lke *v1alpha1.LKEClusterConfig) error {
+		if !slices.Contains(lke.Finalizers, "lke.anza-labs.dev/finalizer") {
+			return errors.New("finalizer not added")
+		}
+
+		if lke.Status.ClusterID == nil {
+			return errors.New("cluster ID not set")
+		}
+
+		return nil
+	}))
+
+	eventually(t, "active cluster", s.get(key, active))
+
+	secret := &corev1.Secret{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: key.Namespace, Name: "test-kubeconfig"}, secret); err != nil {
+		t.Fatalf("expected kubeconfig Secret, got: %v", err)
+	}
+
+	s.update(t, key, func(lke *v1alpha1.LKEClusterConfig) {
+		lke.Spec.NodePools["default"] = v1alpha1.LKENodePool{NodeCount: 3, LinodeType: "g6-standard-1"}
+		lke.Spec.NodePools["extra"] = v1alpha1.LKENodePool{NodeCount: 1, LinodeType: "g6-standard-2"}
+	})
+
+	eventually(t, "node pools changed", s.get(key, func(lke *v1alpha1.LKEClusterConfig) error {
+		if err := active(lke); err != nil {
+			return err
+		}
+
+		_, pools, err := s.clusterOf(lke)
+		if err != nil {
+			return err
+		}
+
+		counts := map[string]int{}
+		for _, np := range pools {
+			counts[np.Type] += np.Count
+		}
+
+		if len(pools) != 2 || counts["g6-standard-1"] != 3 || counts["g6-standard-2"] != 1 {
+			return fmt.Errorf("unexpected node pools: %#+v", pools)
+		}
+
+		if len(lke.Status.NodePoolStatuses) != 2 {
+			return fmt.Errorf("unexpected node pool statuses: %#+v", lke.Status.NodePoolStatuses)
+		}
+
+		return nil
+	}))
+
+	s.update(t, key, func(lke *v1alpha1.LKEClusterConfig) {
+		delete(lke.Spec.NodePools, "extra")
+		lke.Spec.HighAvailability = ptr(true)
+	})
+
+	eventually(t, "node pool removed and HA enabled", s.get(key, func(lke *v1alpha1.LKEClusterConfig) error {
+		if err := active(lke); err != nil {
+			return err
+		}
+
+		cluster, pools, err := s.clusterOf(lke)
+		if err != nil {
+			return err
+		}
+
+		if !cluster.ControlPlane.HighAvailability {
+			return errors.New("HA not enabled")
+		}
+
+		if len(pools) != 1 {
+			return fmt.Errorf("unexpected node pools: %#+v", pools)
+		}
+
+		return nil
+	}))
+
+	current := &v1alpha1.LKEClusterConfig{}
+	if err := s.client.Get(ctx, key, current); err != nil {
+		t.Fatalf("failed to get object: %v", err)
+	}
+
+	clusterID := *current.Status.ClusterID
+
+	if err := s.client.Delete(ctx, current); err != nil {
+		t.Fatalf("failed to delete object: %v", err)
+	}
+
+	eventually(t, "object removed", func() error {
+		err := s.client.Get(ctx, key, &v1alpha1.LKEClusterConfig{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("object still exists: %v", err)
+	})
+
+	if _, _, ok := s.emulator.Cluster(clusterID); ok {
+		t.Errorf("expected LKE cluster %d to be deleted", clusterID)
+	}
+
+	err := s.client.Get(ctx, types.NamespacedName{Namespace: key.Namespace, Name: "test-kubeconfig"}, &corev1.Secret{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected kubeconfig Secret to be deleted, got: %v", err)
+	}
+}
+
+func TestEnvtest_UnsupportedVersion(t *testing.T) {
+	s := newSuite(t)
+
+	lke := newEnvtestLKEClusterConfig(s.namespace(t))
+	lke.Spec.KubernetesVersion = ptr("1.10")
+	key := client.ObjectKeyFromObject(lke)
+
+	if err := s.client.Create(context.Background(), lke); err != nil {
+		t.Fatalf("failed to create object: %v", err)
+	}
+
+	eventually(t, "validation error", s.get(key, func(lke *v1alpha1.LKEClusterConfig) error {
+		if err := notReady("LinodeValidation")(lke); err != nil {
+			return err
+		}
+
+		if phase(lke) != v1alpha1.PhaseError {
+			return fmt.Errorf("expected Error phase, got: %q", phase(lke))
+		}
+
+		return nil
+	}))
+
+	s.update(t, key, func(lke *v1alpha1.LKEClusterConfig) {
+		lke.Spec.KubernetesVersion = ptr("1.30")
+	})
+
+	eventually(t, "active cluster after fixing the version", s.get(key, active))
+}
+
+func TestEnvtest_MissingTokenSecret(t *testing.T) {
+	s := newSuite(t)
+	ctx := context.Background()
+
+	lke := newEnvtestLKEClusterConfig(s.namespace(t))
+	lke.Spec.TokenSecretRef = &v1alpha1.SecretRef{Name: "token"}
+	key := client.ObjectKeyFromObject(lke)
+
+	if err := s.client.Create(ctx, lke); err != nil {
+		t.Fatalf("failed to create object: %v", err)
+	}
+
+	eventually(t, "missing token error", s.get(key, func(lke *v1alpha1.LKEClusterConfig) error {
+		cond := meta.FindStatusCondition(lke.Status.Conditions, v1alpha1.ConditionReady)
+		if cond == nil || cond.Status != metav1.ConditionFalse || lke.Status.ClusterID != nil {
+			return fmt.Errorf("expected Ready condition to be false, got: %#+v", cond)
+		}
+
+		return nil
+	}))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: "token"},
+		Data:       map[string][]byte{credentials.DefaultTokenKey: []byte(envtestToken)},
+	}
+	if err := s.client.Create(ctx, secret); err != nil {
+		t.Fatalf("failed to create Secret: %v", err)
+	}
+
+	eventually(t, "active cluster after creating the Secret", s.get(key, active))
+}
+
+func TestEnvtest_DeletionFailure(t *testing.T) {
+	s := newSuite(t)
+	ctx := context.Background()
+
+	lke := newEnvtestLKEClusterConfig(s.namespace(t))
+	key := client.ObjectKeyFromObject(lke)
+
+	if err := s.client.Create(ctx, lke); err != nil {
+		t.Fatalf("failed to create object: %v", err)
+	}
+
+	eventually(t, "active cluster", s.get(key, active))
+
+	if err := s.client.Get(ctx, key, lke); err != nil {
+		t.Fatalf("failed to get object: %v", err)
+	}
+
+	clusterID := *lke.Status.ClusterID
+
+	s.emulator.InjectFault(emulator.Fault{
+		Method: "DELETE",
+		Path:   fmt.Sprintf("/%s/lke/clusters/%d", emulator.APIVersion, clusterID),
+		Status: 500,
+	})
+	t.Cleanup(s.emulator.ClearFaults)
+
+	if err := s.client.Delete(ctx, lke); err != nil {
+		t.Fatalf("failed to delete object: %v", err)
+	}
+
+	eventually(t, "transient error", s.get(key, func(lke *v1alpha1.LKEClusterConfig) error {
+		if err := notReady("LinodeTransient")(lke); err != nil {
+			return err
+		}
+
+		if !slices.Contains(lke.Finalizers, "lke.anza-labs.dev/finalizer") {
+			return errors.New("finalizer removed before the cluster was deleted")
+		}
+
+		return nil
+	}))
+
+	if _, _, ok := s.emulator.Cluster(clusterID); !ok {
+		t.Fatalf("expected LKE cluster %d to exist while deletion fails", clusterID)
+	}
+
+	s.emulator.ClearFaults()
+
+	eventually(t, "object removed", func() error {
+		err := s.client.Get(ctx, key, &v1alpha1.LKEClusterConfig{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("object still exists: %v", err)
+	})
+
+	if _, _, ok := s.emulator.Cluster(clusterID); ok {
+		t.Errorf("expected LKE cluster %d to be deleted", clusterID)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,548 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/linode/linodego"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/credentials"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	"github.com/anza-labs/lke-operator/internal/policy"
+)
+
+// DefaultGCGracePeriod is the default time for which the LKE cluster must stay
+// orphaned, before it is deleted by the GarbageCollector.
+const DefaultGCGracePeriod = 24 * time.Hour
+
+var (
+	orphanedClusters = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "lke_operator_orphaned_clusters",
+			Help: "Number of LKE clusters created by the operator without matching LKEClusterConfig.",
+		},
+	)
+
+	orphanedClustersDeleted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "lke_operator_orphaned_clusters_deleted_total",
+			Help: "Number of orphaned LKE clusters deleted by the garbage collector.",
+		},
+	)
+
+	orphanedResources = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "lke_operator_orphaned_resources",
+			Help: "Number of NodeBalancers and Volumes left behind by deleted LKE clusters.",
+		},
+		[]string{"kind"},
+	)
+
+	orphanedResourcesDeleted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "lke_operator_orphaned_resources_deleted_total",
+			Help: "Number of NodeBalancers and Volumes left behind by deleted LKE clusters, deleted by the garbage collector.",
+		},
+		[]string{"kind"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(orphanedClusters, orphanedClustersDeleted, orphanedResources, orphanedResourcesDeleted)
+}
+
+// GarbageCollector periodically looks for the LKE clusters created by the operator,
+// whose LKEClusterConfig no longer exists, e.g. because it was force-deleted or its
+// finalizer was removed by hand, and for the NodeBalancers and Volumes left behind
+// by the deleted LKE clusters. Orphaned clusters and resources are reported, and
+// deleted once they stay orphaned for the grace period, unless running in dry-run mode.
+// Volumes are deleted only if enabled, as they may hold data worth recovering.
+//
+// Resources are left behind by the LKE cluster if they are tagged with its ID, and the
+// cluster was seen managed by the operator instance by the previous collections, but no
+// longer exists. Clusters deleted while the operator was not running are not known, so
+// their resources are not collected. Resources tagged with the ownership tags of the
+// operator instance are collected, once no cluster carries the same ownership tags.
+//
+// Only the accounts of the tokens known to the operator are searched: the default
+// token, the tokens of LinodeCredentials and the tokens referenced by the existing
+// LKEClusterConfigs.
+type GarbageCollector struct {
+	Client           client.Reader
+	KubernetesClient kubernetes.Interface
+
+	// LinodeClients shares Linode clients, rate limiters and circuit breakers
+	// with the reconcilers.
+	LinodeClients *lkeclient.Cache
+
+	// DefaultCredentials provides the token used by objects not referencing any.
+	DefaultCredentials credentials.Provider
+
+	// ReferencePolicy decides if objects may reference Secrets in other namespaces.
+	ReferencePolicy *policy.ReferencePolicy
+
+	Recorder record.EventRecorder
+
+	// InstanceID identifies the operator instance in the ownership tags of the
+	// LKE clusters. Only the clusters tagged with it are collected, so that the
+	// operators sharing the account do not delete each other's clusters.
+	// Defaults to DefaultInstanceID.
+	InstanceID string
+
+	// Interval is the time between the collections.
+	Interval time.Duration
+
+	// GracePeriod is the time for which the LKE cluster or resource must stay
+	// orphaned, before it is deleted.
+	GracePeriod time.Duration
+
+	// DryRun disables deletion of the orphaned LKE clusters and resources, which
+	// are only reported.
+	DryRun bool
+
+	// DeleteVolumes enables deletion of the orphaned Volumes, which are otherwise
+	// only reported.
+	DeleteVolumes bool
+
+	now       func() time.Time
+	clientFor func(cred *credentials.Credential) lkeclient.Client
+	orphans   map[orphanKey]*orphan
+
+	// managed maps the IDs of the LKE clusters managed by the operator instance,
+	// seen by the previous collections, to their accounts.
+	managed map[int]string
+}
+
+// orphanKey identifies the orphaned LKE cluster or resource.
+type orphanKey struct {
+	kind string
+	id   int
+}
+
+// orphan is the LKE cluster without matching LKEClusterConfig, or the resource
+// left behind by the deleted LKE cluster.
+type orphan struct {
+	resource
+	account string
+	client  lkeclient.Client
+
+	// since is the time the cluster was first seen orphaned.
+	since time.Time
+
+	// expired is set once the grace period passed in dry-run mode.
+	expired bool
+}
+
+var _ manager.LeaderElectionRunnable = (*GarbageCollector)(nil)
+
+// SetupWithManager adds the GarbageCollector to the Manager.
+func (g *GarbageCollector) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(g)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, so only the leader
+// deletes the orphaned LKE clusters.
+func (g *GarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the collections every interval, until the context is done.
+func (g *GarbageCollector) Start(ctx context.Context) error {
+	logger := ctrl.Log.WithName("garbage-collector")
+	ctx = log.IntoContext(ctx, logger)
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := g.Collect(ctx); err != nil {
+			logger.Error(err, "failed to collect orphaned LKE clusters and resources")
+		}
+	}, g.Interval)
+
+	return nil
+}
+
+// Collect finds the orphaned LKE clusters and resources, reports the new ones and
+// deletes the ones orphaned for longer than the grace period.
+func (g *GarbageCollector) Collect(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	lkes := &v1alpha1.LKEClusterConfigList{}
+	if err := g.Client.List(ctx, lkes); err != nil {
+		return fmt.Errorf("failed to list LKEClusterConfigs: %w", err)
+	}
+
+	owners := make(map[string]struct{})
+	clusterIDs := make(map[int]struct{})
+	providers := []credentials.Provider{}
+
+	if g.DefaultCredentials != nil {
+		providers = append(providers, g.DefaultCredentials)
+	}
+
+	for i := range lkes.Items {
+		lke := &lkes.Items[i]
+
+		owners[ownerUIDTag(lke)] = struct{}{}
+		owners[ownerRefTag(lke)] = struct{}{}
+
+		if lke.Status.ClusterID != nil {
+			clusterIDs[*lke.Status.ClusterID] = struct{}{}
+		}
+
+		if lke.Spec.TokenSecretRef == nil && lke.Spec.CredentialsRef == nil {
+			continue
+		}
+
+		provider, err := resolveCredentials(ctx, g.Client, g.KubernetesClient,
+			g.ReferencePolicy, nil, lke)
+		if err != nil {
+			// reported by the LKEClusterConfig controller
+			continue
+		}
+
+		providers = append(providers, provider)
+	}
+
+	creds := &v1alpha1.LinodeCredentialsList{}
+	if err := g.Client.List(ctx, creds); err != nil {
+		return fmt.Errorf("failed to list LinodeCredentials: %w", err)
+	}
+
+	for i := range creds.Items {
+		providers = append(providers, linodeCredentialsProvider(g.KubernetesClient, &creds.Items[i]))
+	}
+
+	var (
+		errs    []error
+		found   = make(map[orphanKey]*orphan)
+		failed  = make(map[string]struct{})
+		visited = make(map[string]struct{})
+	)
+
+	for _, provider := range providers {
+		cred, err := provider.Credential(ctx)
+		if err != nil {
+			log.V(1).Info("skipping credentials", "error", err.Error())
+			continue
+		}
+
+		if _, ok := visited[cred.Key.ID]; ok {
+			continue
+		}
+
+		visited[cred.Key.ID] = struct{}{}
+
+		client := g.newClient(cred)
+
+		clusters, err := client.ListLKEClusters(ctx, &linodego.ListOptions{})
+		if err != nil {
+			failed[cred.Key.ID] = struct{}{}
+			errs = append(errs, fmt.Errorf("failed to list clusters: %w", err))
+
+			continue
+		}
+
+		deleted := g.trackClusters(cred.Key.ID, clusters)
+
+		for _, cluster := range clusters {
+			if orphaned(cluster, g.instanceID(), owners, clusterIDs) {
+				found[orphanKey{kindLKECluster, cluster.ID}] = &orphan{
+					resource: resource{kind: kindLKECluster, id: cluster.ID, label: cluster.Label, tags: cluster.Tags},
+					account:  cred.Key.ID,
+					client:   client,
+				}
+			}
+		}
+
+		resources, err := listResources(ctx, client)
+		if err != nil {
+			failed[cred.Key.ID] = struct{}{}
+			errs = append(errs, err)
+
+			continue
+		}
+
+		for _, res := range resources {
+			if leftover(res, clusters, g.instanceID(), deleted) {
+				found[orphanKey{res.kind, res.id}] = &orphan{
+					resource: res,
+					account:  cred.Key.ID,
+					client:   client,
+				}
+			}
+		}
+
+		g.forgetClusters(deleted, resources)
+	}
+
+	now := g.clock()
+
+	for key, o := range g.orphans {
+		if _, ok := failed[o.account]; ok {
+			// keep the orphans which could not be listed this time
+			found[key] = o
+		} else if _, ok := found[key]; ok {
+			found[key].since = o.since
+			found[key].expired = o.expired
+		}
+	}
+
+	for _, o := range found {
+		if o.since.IsZero() {
+			o.since = now
+
+			if o.kind == kindLKECluster {
+				g.event(o, corev1.EventTypeWarning, "OrphanedCluster",
+					fmt.Sprintf("LKE cluster %d (%s) has no matching LKEClusterConfig", o.id, o.label))
+			} else {
+				g.event(o, corev1.EventTypeWarning, "OrphanedResource",
+					fmt.Sprintf("%s was left behind by a deleted LKE cluster", o.resource))
+			}
+		}
+	}
+
+	g.orphans = found
+
+	for key, o := range g.orphans {
+		if now.Sub(o.since) < g.GracePeriod {
+			continue
+		}
+
+		if g.DryRun || (o.kind == kindVolume && !g.DeleteVolumes) {
+			if !o.expired {
+				o.expired = true
+
+				why := "garbage collection runs in dry-run mode"
+				if !g.DryRun {
+					why = "deletion of Volumes is disabled"
+				}
+
+				g.event(o, corev1.EventTypeWarning, o.reason("NotDeleted"),
+					fmt.Sprintf("%s is orphaned for more than %s, but %s", o.resource, g.GracePeriod, why))
+			}
+
+			continue
+		}
+
+		if err := g.delete(ctx, o); err != nil &&
+			!errors.Is(err, internalerrors.ErrLinodeNotFound) {
+			errs = append(errs, fmt.Errorf("failed to delete orphaned %s: %w", o.resource, err))
+			continue
+		}
+
+		log.Info("deleted orphaned "+o.kind, "id", o.id, "label", o.label)
+
+		if o.kind == kindLKECluster {
+			orphanedClustersDeleted.Inc()
+		} else {
+			orphanedResourcesDeleted.WithLabelValues(o.kind).Inc()
+		}
+
+		g.event(o, corev1.EventTypeNormal, o.reason("Deleted"),
+			fmt.Sprintf("deleted orphaned %s", o.resource))
+
+		delete(g.orphans, key)
+	}
+
+	counts := map[string]int{kindNodeBalancer: 0, kindVolume: 0}
+	clusters := 0
+
+	for _, o := range g.orphans {
+		if o.kind == kindLKECluster {
+			clusters++
+		} else {
+			counts[o.kind]++
+		}
+	}
+
+	orphanedClusters.Set(float64(clusters))
+
+	for kind, n := range counts {
+		orphanedResources.WithLabelValues(kind).Set(float64(n))
+	}
+
+	return errors.Join(errs...)
+}
+
+// reason returns the reason of the Event about the orphan.
+func (o *orphan) reason(suffix string) string {
+	if o.kind == kindLKECluster {
+		return "OrphanedCluster" + suffix
+	}
+
+	return "OrphanedResource" + suffix
+}
+
+// delete deletes the orphaned LKE cluster or resource.
+func (g *GarbageCollector) delete(ctx context.Context, o *orphan) error {
+	if o.kind == kindLKECluster {
+		return o.client.DeleteLKECluster(ctx, o.id)
+	}
+
+	return deleteResource(ctx, o.client, o.resource)
+}
+
+// orphaned checks if the LKE cluster was created by the operator instance, but
+// neither the object it was created for, nor the object restored from its backup
+// exists. Clusters of other instances are never orphaned, nor are the clusters
+// matched only by the ref tag, e.g. adopted before their object is created.
+func orphaned(
+	cluster linodego.LKECluster,
+	instance string,
+	owners map[string]struct{},
+	clusterIDs map[int]struct{},
+) bool {
+	if !slices.Contains(cluster.Tags, ownerInstanceTag(instance)) {
+		return false
+	}
+
+	if _, ok := clusterIDs[cluster.ID]; ok {
+		return false
+	}
+
+	owned := false
+
+	for _, tag := range cluster.Tags {
+		if !strings.HasPrefix(tag, ownerUIDTagPrefix) && !strings.HasPrefix(tag, ownerRefTagPrefix) {
+			continue
+		}
+
+		if _, ok := owners[tag]; ok {
+			return false
+		}
+
+		owned = owned || strings.HasPrefix(tag, ownerUIDTagPrefix)
+	}
+
+	return owned
+}
+
+// trackClusters remembers the LKE clusters of the account managed by the operator
+// instance, and returns the IDs of those seen by the previous collections, which
+// no longer exist.
+func (g *GarbageCollector) trackClusters(account string, clusters []linodego.LKECluster) map[int]struct{} {
+	if g.managed == nil {
+		g.managed = make(map[int]string)
+	}
+
+	existing := make(map[int]struct{}, len(clusters))
+	instanceTag := ownerInstanceTag(g.instanceID())
+
+	for _, cluster := range clusters {
+		existing[cluster.ID] = struct{}{}
+
+		if slices.Contains(cluster.Tags, instanceTag) {
+			g.managed[cluster.ID] = account
+		}
+	}
+
+	deleted := make(map[int]struct{})
+
+	for id, acc := range g.managed {
+		if _, ok := existing[id]; !ok && acc == account {
+			deleted[id] = struct{}{}
+		}
+	}
+
+	return deleted
+}
+
+// forgetClusters forgets the deleted LKE clusters, once none of the resources is
+// tagged with their IDs.
+func (g *GarbageCollector) forgetClusters(deleted map[int]struct{}, resources []resource) {
+	referenced := make(map[int]struct{})
+
+	for _, res := range resources {
+		for _, id := range referencedClusterIDs(res.tags) {
+			referenced[id] = struct{}{}
+		}
+	}
+
+	for id := range deleted {
+		if _, ok := referenced[id]; !ok {
+			delete(g.managed, id)
+		}
+	}
+}
+
+func (g *GarbageCollector) newClient(cred *credentials.Credential) lkeclient.Client {
+	if g.clientFor != nil {
+		return g.clientFor(cred)
+	}
+
+	return g.LinodeClients.Get(cred.Key, cred.Token, lkeclient.WithEndpoint(cred.Endpoint))
+}
+
+func (g *GarbageCollector) instanceID() string {
+	if g.InstanceID != "" {
+		return g.InstanceID
+	}
+
+	return DefaultInstanceID
+}
+
+func (g *GarbageCollector) clock() time.Time {
+	if g.now != nil {
+		return g.now()
+	}
+
+	return time.Now()
+}
+
+// event emits the Event for the LKEClusterConfig the orphan was created for. The
+// namespace and name of the object are known only if they were not hashed in the
+// ownership tag.
+func (g *GarbageCollector) event(o *orphan, eventType, reason, message string) {
+	if g.Recorder == nil {
+		return
+	}
+
+	obj := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: o.label},
+	}
+
+	for _, tag := range o.tags {
+		ref, ok := strings.CutPrefix(tag, ownerRefTagPrefix)
+		if !ok {
+			continue
+		}
+
+		if namespace, name, ok := strings.Cut(ref, "/"); ok {
+			obj.Namespace, obj.Name = namespace, name
+		}
+	}
+
+	g.Recorder.Event(obj, eventType, reason, message)
+}
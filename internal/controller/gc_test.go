@@ -0,0 +1,236 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/credentials"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+type gcClient struct {
+	lkeclient.Client
+
+	clusters      []linodego.LKECluster
+	nodeBalancers []linodego.NodeBalancer
+	volumes       []linodego.Volume
+	deleted       []int
+}
+
+func (c *gcClient) ListLKEClusters(context.Context, *linodego.ListOptions) ([]linodego.LKECluster, error) {
+	return c.clusters, nil
+}
+
+func (c *gcClient) ListNodeBalancers(context.Context, *linodego.ListOptions) ([]linodego.NodeBalancer, error) {
+	return c.nodeBalancers, nil
+}
+
+func (c *gcClient) DeleteNodeBalancer(_ context.Context, nodebalancerID int) error {
+	c.deleted = append(c.deleted, nodebalancerID)
+	c.nodeBalancers = slices.DeleteFunc(c.nodeBalancers, func(nb linodego.NodeBalancer) bool {
+		return nb.ID == nodebalancerID
+	})
+
+	return nil
+}
+
+func (c *gcClient) ListVolumes(context.Context, *linodego.ListOptions) ([]linodego.Volume, error) {
+	return c.volumes, nil
+}
+
+func (c *gcClient) DeleteVolume(_ context.Context, volumeID int) error {
+	c.deleted = append(c.deleted, volumeID)
+	c.volumes = slices.DeleteFunc(c.volumes, func(vol linodego.Volume) bool {
+		return vol.ID == volumeID
+	})
+
+	return nil
+}
+
+func (c *gcClient) DeleteLKECluster(_ context.Context, clusterID int) error {
+	c.deleted = append(c.deleted, clusterID)
+	c.clusters = slices.DeleteFunc(c.clusters, func(cluster linodego.LKECluster) bool {
+		return cluster.ID == clusterID
+	})
+
+	return nil
+}
+
+func TestGarbageCollector_Collect(t *testing.T) {
+	t.Parallel()
+
+	existing := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "existing", UID: "existing"},
+	}
+	restored := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "restored", UID: "restored"},
+	}
+	deleted := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deleted", UID: "deleted"},
+	}
+
+	clusters := []linodego.LKECluster{
+		{ID: 1, Label: "existing", Tags: ownershipTags(existing, DefaultInstanceID)},
+		{ID: 2, Label: "restored", Tags: []string{
+			"lke-operator.uid=old", ownerRefTag(restored), ownerInstanceTag(DefaultInstanceID),
+		}},
+		{ID: 3, Label: "deleted", Tags: ownershipTags(deleted, DefaultInstanceID)},
+		{ID: 4, Label: "unmanaged", Tags: []string{"foo"}},
+		{ID: 6, Label: "other-instance", Tags: ownershipTags(deleted, "other")},
+		{ID: 7, Label: "untagged-instance", Tags: []string{ownerUIDTag(deleted), ownerRefTag(deleted)}},
+		{ID: 9, Label: "adopted", Tags: []string{ownerRefTag(deleted), ownerInstanceTag(DefaultInstanceID)}},
+	}
+
+	// replaced is the cluster managed by the operator, deleted after the first collection
+	replaced := linodego.LKECluster{ID: 5, Label: "replaced", Tags: ownershipTags(existing, DefaultInstanceID)}
+
+	nodeBalancers := []linodego.NodeBalancer{
+		{ID: 10, Label: mkptr("existing"), Tags: []string{clusterIDTag(1)}},
+		{ID: 11, Label: mkptr("replaced"), Tags: []string{clusterIDTag(5)}},
+		{ID: 12, Label: mkptr("untagged"), Tags: []string{"foo"}},
+		{ID: 13, Label: mkptr("unknown"), Tags: []string{clusterIDTag(8)}},
+	}
+
+	volumes := []linodego.Volume{
+		{ID: 20, Label: "existing", Tags: []string{ownerUIDTag(existing), ownerInstanceTag(DefaultInstanceID)}},
+		{ID: 21, Label: "gone", Tags: []string{ownerUIDTagPrefix + "gone", ownerInstanceTag(DefaultInstanceID)}},
+		{ID: 22, Label: "other-instance", Tags: []string{ownerUIDTagPrefix + "gone", ownerInstanceTag("other")}},
+		{ID: 23, Label: "unknown", Tags: []string{clusterIDTag(8)}},
+	}
+
+	for name, tc := range map[string]struct {
+		dryRun          bool
+		deleteVolumes   bool
+		elapsed         time.Duration
+		expectedOrphans []orphanKey
+		expectedDeleted []int
+		expectedEvents  int
+	}{
+		"within_grace_period": {
+			elapsed: time.Hour,
+			expectedOrphans: []orphanKey{
+				{kindLKECluster, 3},
+				{kindNodeBalancer, 11},
+				{kindVolume, 21},
+			},
+			expectedEvents: 3,
+		},
+		"dry_run": {
+			dryRun:  true,
+			elapsed: 2 * time.Hour,
+			expectedOrphans: []orphanKey{
+				{kindLKECluster, 3},
+				{kindNodeBalancer, 11},
+				{kindVolume, 21},
+			},
+			expectedEvents: 6,
+		},
+		"delete": {
+			deleteVolumes:   true,
+			elapsed:         2 * time.Hour,
+			expectedOrphans: []orphanKey{},
+			expectedDeleted: []int{3, 11, 21},
+			expectedEvents:  6,
+		},
+		"keep_volumes": {
+			elapsed: 2 * time.Hour,
+			expectedOrphans: []orphanKey{
+				{kindVolume, 21},
+			},
+			expectedDeleted: []int{3, 11},
+			expectedEvents:  6,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+			client := &gcClient{
+				clusters:      append(slices.Clone(clusters), replaced),
+				nodeBalancers: slices.Clone(nodeBalancers),
+				volumes:       slices.Clone(volumes),
+			}
+			recorder := record.NewFakeRecorder(10)
+
+			g := &GarbageCollector{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme(t)).
+					WithObjects(existing.DeepCopy(), restored.DeepCopy()).
+					Build(),
+				DefaultCredentials: credentials.Static("token"),
+				Recorder:           recorder,
+				GracePeriod:        90 * time.Minute,
+				DryRun:             tc.dryRun,
+				DeleteVolumes:      tc.deleteVolumes,
+				now:                func() time.Time { return now },
+				clientFor:          func(*credentials.Credential) lkeclient.Client { return client },
+			}
+
+			if err := g.Collect(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			client.clusters = slices.Clone(clusters)
+
+			for _, step := range []time.Duration{0, tc.elapsed, 0} {
+				now = now.Add(step)
+
+				if err := g.Collect(context.Background()); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			orphans := []orphanKey{}
+			for key := range g.orphans {
+				orphans = append(orphans, key)
+			}
+
+			slices.SortFunc(orphans, func(a, b orphanKey) int {
+				return a.id - b.id
+			})
+
+			slices.Sort(client.deleted)
+
+			if !reflect.DeepEqual(orphans, tc.expectedOrphans) {
+				t.Errorf("expected Orphans value: %#+v, got: %#+v",
+					tc.expectedOrphans, orphans)
+			}
+
+			if !reflect.DeepEqual(client.deleted, tc.expectedDeleted) {
+				t.Errorf("expected Deleted value: %#+v, got: %#+v",
+					tc.expectedDeleted, client.deleted)
+			}
+
+			if len(recorder.Events) != tc.expectedEvents {
+				t.Errorf("expected Events value: %#+v, got: %#+v",
+					tc.expectedEvents, len(recorder.Events))
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/linode/linodego"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+// ImportCluster returns the object describing the existing LKE cluster. Node pools
+// created by the operator keep their names, the others are named as if adopted.
+//
+// If adopt is true, the LKE cluster and its node pools are tagged for the object, so
+// that once the object is created, the operator instance recovers the LKE cluster
+// instead of provisioning a new one. The LKE cluster is left with only the ref and
+// instance tags, which the garbage collector ignores until the object exists. The
+// LKE cluster managed by another object or operator instance is not imported.
+func ImportCluster(
+	ctx context.Context,
+	client lkeclient.Client,
+	namespace, name, instance string,
+	clusterID int,
+	adopt bool,
+) (*v1alpha1.LKEClusterConfig, error) {
+	cluster, err := client.GetLKECluster(ctx, clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	nps, err := client.ListLKENodePools(ctx, cluster.ID, &linodego.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node pools: %w", err)
+	}
+
+	lke := &v1alpha1.LKEClusterConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "LKEClusterConfig",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: v1alpha1.LKEClusterConfigSpec{
+			Region:            cluster.Region,
+			Label:             cluster.Label,
+			HighAvailability:  mkptr(cluster.ControlPlane.HighAvailability),
+			KubernetesVersion: mkptr(cluster.K8sVersion),
+			NodePools:         make(map[string]v1alpha1.LKENodePool, len(nps)),
+		},
+	}
+
+	refTag, instanceTag := ownerRefTag(lke), ownerInstanceTag(instance)
+	adopted := slices.Contains(cluster.Tags, refTag)
+
+	// tagged for another object, or by another operator instance
+	if slices.ContainsFunc(cluster.Tags, func(tag string) bool {
+		return isOwnershipTag(tag) && !adopted ||
+			strings.HasPrefix(tag, ownerInstanceTagPrefix) && tag != instanceTag
+	}) {
+		return nil, fmt.Errorf("%w: %d", internalerrors.ErrClusterManaged, cluster.ID)
+	}
+
+	if tags := userTags(cluster.Tags); len(tags) > 0 {
+		lke.Annotations = map[string]string{lkeTagsAnnotation: strings.Join(tags, ",")}
+	}
+
+	for _, np := range nps {
+		poolName, managed := nodePoolName(np)
+		if !managed {
+			poolName = fmt.Sprintf("%s%d", adoptedNodePoolPrefix, np.ID)
+		}
+
+		lke.Spec.NodePools[poolName] = nodePoolStatusFromAPI(np).NodePoolDetails
+
+		if !adopt || managed {
+			continue
+		}
+
+		tags := append(slices.Clone(np.Tags), lkeOperatorTag+poolName)
+
+		if _, err := client.UpdateLKENodePool(ctx, cluster.ID, np.ID, linodego.LKENodePoolUpdateOptions{
+			Tags: &tags,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to tag node pool %d: %w", np.ID, err)
+		}
+	}
+
+	// the cluster is tagged last, so the operator does not recover it with untagged node pools;
+	// stale ownership tags are removed, so it is not collected before the object is created
+	stale := slices.ContainsFunc(cluster.Tags, func(tag string) bool {
+		return isOwnershipTag(tag) && tag != refTag && tag != instanceTag
+	})
+
+	if adopt && (stale || !adopted || !slices.Contains(cluster.Tags, instanceTag)) {
+		tags := append(userTags(cluster.Tags), refTag, instanceTag)
+
+		if _, err := client.UpdateLKECluster(ctx, cluster.ID, linodego.LKEClusterUpdateOptions{
+			Tags: &tags,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to tag cluster: %w", err)
+		}
+	}
+
+	return lke, nil
+}
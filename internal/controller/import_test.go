@@ -0,0 +1,165 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/linode/linodego"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	lkefake "github.com/anza-labs/lke-operator/internal/lkeclient/fake"
+)
+
+func TestImportCluster(t *testing.T) {
+	t.Parallel()
+
+	other := &v1alpha1.LKEClusterConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "other"}}
+	self := &v1alpha1.LKEClusterConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "imported"}}
+	stale := &v1alpha1.LKEClusterConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "imported", UID: "old"}}
+
+	for name, tc := range map[string]struct {
+		clusterTags         []string
+		adopt               bool
+		expectedErr         error
+		expectedPools       map[string]v1alpha1.LKENodePool
+		expectedAnnotations map[string]string
+		expectedUpdates     int
+	}{
+		"generate": {
+			clusterTags: []string{"team=platform"},
+			expectedPools: map[string]v1alpha1.LKENodePool{
+				"adopted-10": {NodeCount: 3, LinodeType: "g6-standard-2"},
+				"workers":    {NodeCount: 1, LinodeType: "g6-standard-1"},
+			},
+			expectedAnnotations: map[string]string{lkeTagsAnnotation: "team=platform"},
+		},
+		"adopt": {
+			adopt: true,
+			expectedPools: map[string]v1alpha1.LKENodePool{
+				"adopted-10": {NodeCount: 3, LinodeType: "g6-standard-2"},
+				"workers":    {NodeCount: 1, LinodeType: "g6-standard-1"},
+			},
+			// the node pool without the name tag, and the cluster
+			expectedUpdates: 2,
+		},
+		"adopted_again": {
+			clusterTags: []string{ownerRefTag(self), ownerInstanceTag(DefaultInstanceID)},
+			adopt:       true,
+			expectedPools: map[string]v1alpha1.LKENodePool{
+				"adopted-10": {NodeCount: 3, LinodeType: "g6-standard-2"},
+				"workers":    {NodeCount: 1, LinodeType: "g6-standard-1"},
+			},
+			expectedUpdates: 1,
+		},
+		"adopted_stale": {
+			clusterTags: ownershipTags(stale, DefaultInstanceID),
+			adopt:       true,
+			expectedPools: map[string]v1alpha1.LKENodePool{
+				"adopted-10": {NodeCount: 3, LinodeType: "g6-standard-2"},
+				"workers":    {NodeCount: 1, LinodeType: "g6-standard-1"},
+			},
+			// the node pool without the name tag, and the cluster losing the stale tags
+			expectedUpdates: 2,
+		},
+		"managed": {
+			clusterTags: ownershipTags(other, DefaultInstanceID),
+			expectedErr: internalerrors.ErrClusterManaged,
+		},
+		"managed_by_other_instance": {
+			clusterTags: ownershipTags(stale, "other"),
+			adopt:       true,
+			expectedErr: internalerrors.ErrClusterManaged,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := lkefake.NewClient()
+			id := client.AddCluster(linodego.LKECluster{
+				Label:        "imported",
+				Region:       "us-east",
+				K8sVersion:   "1.30",
+				Tags:         tc.clusterTags,
+				ControlPlane: linodego.LKEClusterControlPlane{HighAvailability: true},
+			},
+				linodego.LKENodePool{ID: 10, Count: 3, Type: "g6-standard-2"},
+				linodego.LKENodePool{ID: 11, Count: 1, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "workers"}},
+			)
+
+			lke, err := ImportCluster(context.Background(), client, "foo", "imported", DefaultInstanceID, id, tc.adopt)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error: %v, got: %v", tc.expectedErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(lke.Spec.NodePools, tc.expectedPools) {
+				t.Errorf("expected NodePools value: %#+v, got: %#+v", tc.expectedPools, lke.Spec.NodePools)
+			}
+
+			if !reflect.DeepEqual(lke.Annotations, tc.expectedAnnotations) {
+				t.Errorf("expected Annotations value: %#+v, got: %#+v", tc.expectedAnnotations, lke.Annotations)
+			}
+
+			if lke.Spec.Label != "imported" || lke.Spec.Region != "us-east" ||
+				deref(lke.Spec.KubernetesVersion) != "1.30" || !deref(lke.Spec.HighAvailability) {
+				t.Errorf("expected spec matching the cluster, got: %#+v", lke.Spec)
+			}
+
+			updates := len(client.CallsTo("UpdateLKECluster")) + len(client.CallsTo("UpdateLKENodePool"))
+			if updates != tc.expectedUpdates {
+				t.Errorf("expected Updates value: %#+v, got: %#+v", tc.expectedUpdates, updates)
+			}
+
+			if !tc.adopt {
+				return
+			}
+
+			// the operator recovers the cluster and sees all node pools as managed
+			cluster, err := findCluster(context.Background(), client, lke, DefaultInstanceID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cluster == nil || cluster.ID != id {
+				t.Fatalf("expected cluster %d to be found, got: %#+v", id, cluster)
+			}
+
+			// until the object is created, the cluster is not collected
+			if orphaned(*cluster, DefaultInstanceID, map[string]struct{}{}, map[int]struct{}{}) {
+				t.Errorf("expected adopted cluster not to be orphaned, got tags: %#+v", cluster.Tags)
+			}
+
+			statuses := generateNodePoolStatusesFromAPI(client.NodePools(id))
+
+			for name := range tc.expectedPools {
+				if _, ok := statuses[name]; !ok {
+					t.Errorf("expected node pool %s to be managed, got: %#+v", name, statuses)
+				}
+			}
+		})
+	}
+}
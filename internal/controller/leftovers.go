@@ -0,0 +1,196 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/linode/linodego"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+// clusterIDTagPrefix prefixes the ID of the LKE cluster in the tags of the
+// NodeBalancers and Volumes created for it.
+const clusterIDTagPrefix = "lke"
+
+// Kinds of the Linode resources collected by the GarbageCollector.
+const (
+	kindLKECluster   = "LKE cluster"
+	kindNodeBalancer = "NodeBalancer"
+	kindVolume       = "Volume"
+)
+
+// resource is the LKE cluster, or the NodeBalancer or Volume possibly left behind
+// by the deleted LKE cluster.
+type resource struct {
+	kind  string
+	id    int
+	label string
+	tags  []string
+}
+
+func (res resource) String() string {
+	return fmt.Sprintf("%s %d (%s)", res.kind, res.id, res.label)
+}
+
+// clusterIDTag returns the tag identifying the resources of the LKE cluster.
+func clusterIDTag(clusterID int) string {
+	return clusterIDTagPrefix + strconv.Itoa(clusterID)
+}
+
+// referencedClusterIDs returns the IDs of the LKE clusters referenced by the tags.
+func referencedClusterIDs(tags []string) []int {
+	ids := []int{}
+
+	for _, tag := range tags {
+		suffix, ok := strings.CutPrefix(tag, clusterIDTagPrefix)
+		if !ok {
+			continue
+		}
+
+		if id, err := strconv.Atoi(suffix); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// listResources lists the NodeBalancers and Volumes of the account.
+func listResources(ctx context.Context, client lkeclient.Client) ([]resource, error) {
+	nodeBalancers, err := client.ListNodeBalancers(ctx, &linodego.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node balancers: %w", err)
+	}
+
+	volumes, err := client.ListVolumes(ctx, &linodego.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	resources := make([]resource, 0, len(nodeBalancers)+len(volumes))
+
+	for _, nb := range nodeBalancers {
+		res := resource{kind: kindNodeBalancer, id: nb.ID, tags: nb.Tags}
+		if nb.Label != nil {
+			res.label = *nb.Label
+		}
+
+		resources = append(resources, res)
+	}
+
+	for _, vol := range volumes {
+		resources = append(resources, resource{kind: kindVolume, id: vol.ID, label: vol.Label, tags: vol.Tags})
+	}
+
+	return resources, nil
+}
+
+// deleteResource deletes the NodeBalancer or Volume.
+func deleteResource(ctx context.Context, client lkeclient.Client, res resource) error {
+	switch res.kind {
+	case kindNodeBalancer:
+		return client.DeleteNodeBalancer(ctx, res.id)
+	case kindVolume:
+		return client.DeleteVolume(ctx, res.id)
+	default:
+		return fmt.Errorf("unknown resource kind %s", res.kind)
+	}
+}
+
+// leftover checks if the resource was left behind by the LKE cluster of the operator
+// instance. It is either tagged with the ID of the deleted cluster, or with the
+// ownership tags of the instance, none of which is carried by an existing cluster.
+// User tags are not considered, as they may be shared with resources unrelated to
+// the clusters.
+func leftover(res resource, clusters []linodego.LKECluster, instance string, deleted map[int]struct{}) bool {
+	for _, id := range referencedClusterIDs(res.tags) {
+		if _, ok := deleted[id]; ok {
+			return true
+		}
+	}
+
+	if !slices.Contains(res.tags, ownerInstanceTag(instance)) {
+		return false
+	}
+
+	owners := []string{}
+	for _, tag := range res.tags {
+		if strings.HasPrefix(tag, ownerUIDTagPrefix) || strings.HasPrefix(tag, ownerRefTagPrefix) {
+			owners = append(owners, tag)
+		}
+	}
+
+	if len(owners) == 0 {
+		return false
+	}
+
+	for _, cluster := range clusters {
+		for _, tag := range owners {
+			if slices.Contains(cluster.Tags, tag) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// reportLeftovers reports the NodeBalancers and Volumes tagged with the ID or the
+// ownership tags of the deleted LKE cluster. They are deleted by the GarbageCollector,
+// if it is enabled and still knows the cluster. Failure to list them does not block
+// the deletion.
+func (r *LKEClusterConfigReconciler) reportLeftovers(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+	clusterID int,
+) {
+	log := log.FromContext(ctx)
+
+	resources, err := listResources(ctx, client)
+	if err != nil {
+		log.Error(err, "failed to look for resources left behind by the LKE cluster")
+		return
+	}
+
+	tags := []string{ownerUIDTag(lke), ownerRefTag(lke), clusterIDTag(clusterID)}
+	found := []string{}
+
+	for _, res := range resources {
+		if slices.ContainsFunc(res.tags, func(tag string) bool { return slices.Contains(tags, tag) }) {
+			found = append(found, res.String())
+		}
+	}
+
+	if len(found) == 0 {
+		return
+	}
+
+	log.Info("resources left behind by the LKE cluster", "clusterID", clusterID, "resources", found)
+
+	r.event(lke, corev1.EventTypeWarning, "OrphanedResources",
+		fmt.Sprintf("LKE cluster %d left behind: %s", clusterID, strings.Join(found, ", ")))
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	lkev1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/credentials"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	tracedlke "github.com/anza-labs/lke-operator/internal/lkeclient/traced"
+)
+
+// credentialsRefreshInterval is the interval in which the credentials are
+// validated, even if nothing has changed.
+const credentialsRefreshInterval = time.Hour
+
+// LinodeCredentialsReconciler reconciles a LinodeCredentials object
+type LinodeCredentialsReconciler struct {
+	client.Client
+	Scheme           *runtime.Scheme
+	KubernetesClient kubernetes.Interface
+
+	// LinodeClients shares Linode clients, rate limiters and circuit breakers
+	// between reconciliations of objects using the same token.
+	LinodeClients *lkeclient.Cache
+
+	// TokenValidator checks the scopes and expiry of the tokens.
+	TokenValidator *credentials.Validator
+
+	// TokenExpiryWarning is the time before the token expiry, from which the
+	// upcoming expiry is reported.
+	TokenExpiryWarning time.Duration
+
+	Recorder record.EventRecorder
+
+	// newLinodeClient returns the Linode client using the credential.
+	// Defaults to the client shared by LinodeClients.
+	newLinodeClient func(cred *credentials.Credential) lkeclient.Client
+}
+
+// +kubebuilder:rbac:groups=lke.anza-labs.dev,resources=linodecredentials,verbs=get;list;watch
+// +kubebuilder:rbac:groups=lke.anza-labs.dev,resources=linodecredentials/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile validates the token referenced by the LinodeCredentials against
+// the Linode API and reports the owning account and the token expiry.
+func (r *LinodeCredentialsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx, "object.name", req.Name)
+
+	log.Info("reconciling")
+
+	creds := &lkev1alpha1.LinodeCredentials{}
+	if err := r.Get(ctx, req.NamespacedName, creds); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("LinodeCredentials resource not found, ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+
+		log.Error(err, "failed to get LinodeCredentials")
+		return ctrl.Result{}, err
+	}
+
+	info, err := r.validate(ctx, creds)
+	if err != nil {
+		log.Error(err, "credentials validation failed")
+
+		reason, _, _ := classifyError(err)
+		if reason == lkev1alpha1.ReasonReconcileFailed {
+			reason = lkev1alpha1.ReasonValidationFailed
+		}
+
+		meta.SetStatusCondition(&creds.Status.Conditions, metav1.Condition{
+			Type:               lkev1alpha1.ConditionReady,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            conditionMessage(err),
+			ObservedGeneration: creds.Generation,
+		})
+
+		if uerr := r.Status().Update(ctx, creds); uerr != nil {
+			log.Error(uerr, "failed to update status")
+		}
+
+		if res, ok := backoffOnOpenCircuit(err); ok {
+			return res, nil
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	// tokens scoped only to LKE cannot read the profile of the user
+	creds.Status.Account = nil
+	message := "token is valid, profile of its user could not be read"
+
+	if info.Username != "" {
+		creds.Status.Account = &lkev1alpha1.LinodeAccount{
+			Username: info.Username,
+			Email:    info.Email,
+		}
+		message = fmt.Sprintf("token belongs to user %s", info.Username)
+	}
+
+	creds.Status.ExpiresAt = nil
+	if info.Expiry != nil {
+		creds.Status.ExpiresAt = &metav1.Time{Time: *info.Expiry}
+	}
+
+	meta.SetStatusCondition(&creds.Status.Conditions, metav1.Condition{
+		Type:               lkev1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             lkev1alpha1.ReasonValidated,
+		Message:            message,
+		ObservedGeneration: creds.Generation,
+	})
+
+	if err := r.Status().Update(ctx, creds); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	return ctrl.Result{RequeueAfter: credentialsRefreshInterval}, nil
+}
+
+// validate checks the token referenced by the credentials, and sets the
+// CredentialsValid condition.
+func (r *LinodeCredentialsReconciler) validate(
+	ctx context.Context,
+	creds *lkev1alpha1.LinodeCredentials,
+) (*credentials.TokenInfo, error) {
+	cred, err := linodeCredentialsProvider(r.KubernetesClient, creds).Credential(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	var linodeClient lkeclient.Client
+	if r.newLinodeClient != nil {
+		linodeClient = r.newLinodeClient(cred)
+	} else {
+		linodeClient = r.LinodeClients.Get(cred.Key, cred.Token, lkeclient.WithEndpoint(cred.Endpoint))
+	}
+
+	client := tracedlke.NewClientWithTracing(linodeClient, "credentials_lke_traced_client")
+
+	info, changed, err := validateToken(ctx,
+		r.TokenValidator,
+		client,
+		cred,
+		r.TokenExpiryWarning,
+		&creds.Status.Conditions,
+		creds.Generation,
+	)
+	if changed {
+		recordTokenCondition(r.Recorder, creds,
+			meta.FindStatusCondition(creds.Status.Conditions, lkev1alpha1.ConditionCredentialsValid))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LinodeCredentialsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.LinodeClients == nil {
+		r.LinodeClients = lkeclient.NewCache(lkeclient.DefaultCacheOptions())
+	}
+
+	if r.TokenValidator == nil {
+		r.TokenValidator = credentials.NewValidator(credentials.DefaultValidationTTL)
+	}
+
+	if r.TokenExpiryWarning == 0 {
+		r.TokenExpiryWarning = DefaultTokenExpiryWarning
+	}
+
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("linodecredentials-controller")
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&lkev1alpha1.LinodeCredentials{},
+		tokenSecretRefField,
+		indexCredentialsTokenSecretRef,
+	); err != nil {
+		return fmt.Errorf("failed to index %s: %w", tokenSecretRefField, err)
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&lkev1alpha1.LinodeCredentials{}).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForSecret),
+			builder.OnlyMetadata,
+		).
+		Complete(r)
+}
+
+// indexCredentialsTokenSecretRef indexes LinodeCredentials by the namespaced
+// name of the Secret holding the token.
+func indexCredentialsTokenSecretRef(obj client.Object) []string {
+	creds, ok := obj.(*lkev1alpha1.LinodeCredentials)
+	if !ok {
+		return nil
+	}
+
+	return []string{types.NamespacedName{
+		Namespace: creds.Spec.TokenSecretRef.Namespace,
+		Name:      creds.Spec.TokenSecretRef.Name,
+	}.String()}
+}
+
+// requestsForSecret enqueues all LinodeCredentials referencing the Secret.
+func (r *LinodeCredentialsReconciler) requestsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := log.FromContext(ctx)
+
+	key := client.ObjectKeyFromObject(obj).String()
+
+	list := &lkev1alpha1.LinodeCredentialsList{}
+	if err := r.List(ctx, list, client.MatchingFields{tokenSecretRefField: key}); err != nil {
+		log.Error(err, "failed to list LinodeCredentials referencing secret",
+			"secret", key)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, creds := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&creds),
+		})
+	}
+
+	return requests
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/credentials"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	lkefake "github.com/anza-labs/lke-operator/internal/lkeclient/fake"
+	"github.com/linode/linodego"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLinodeCredentialsReconciler_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	expiry := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	tokenSecret := func(data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "operator", Name: "token", UID: "uid"},
+			Data:       data,
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		secret               *corev1.Secret
+		inject               func(*lkefake.Client)
+		expectedErr          bool
+		expectedReady        metav1.ConditionStatus
+		expectedReadyReason  string
+		expectedTokenReason  string
+		expectedAccount      *v1alpha1.LinodeAccount
+		expectedExpiresAt    bool
+		expectedRequeueAfter time.Duration
+		expectedProfileCalls int
+	}{
+		"missing_secret": {
+			expectedErr:         true,
+			expectedReady:       metav1.ConditionFalse,
+			expectedReadyReason: v1alpha1.ReasonValidationFailed,
+		},
+		"missing_key": {
+			secret:              tokenSecret(map[string][]byte{"other": []byte("token")}),
+			expectedErr:         true,
+			expectedReady:       metav1.ConditionFalse,
+			expectedReadyReason: v1alpha1.ReasonValidationFailed,
+		},
+		"invalid_token": {
+			secret: tokenSecret(map[string][]byte{credentials.DefaultTokenKey: []byte("token")}),
+			inject: func(c *lkefake.Client) {
+				c.InjectError(lkefake.AnyMethod, &linodego.Error{Code: http.StatusUnauthorized, Message: "Invalid Token"}, 0)
+			},
+			expectedErr:          true,
+			expectedReady:        metav1.ConditionFalse,
+			expectedReadyReason:  (&internalerrors.LinodeError{Class: internalerrors.ClassUnauthorized}).Reason(),
+			expectedTokenReason:  v1alpha1.ReasonValidationFailed,
+			expectedProfileCalls: 1,
+		},
+		"profile_forbidden": {
+			secret: tokenSecret(map[string][]byte{credentials.DefaultTokenKey: []byte("token")}),
+			inject: func(c *lkefake.Client) {
+				c.InjectError("GetProfile", &linodego.Error{Code: http.StatusForbidden}, 0)
+				c.InjectError("ListTokens", &linodego.Error{Code: http.StatusForbidden}, 0)
+			},
+			expectedReady:        metav1.ConditionTrue,
+			expectedReadyReason:  v1alpha1.ReasonValidated,
+			expectedTokenReason:  v1alpha1.ReasonTokenScopesUnknown,
+			expectedRequeueAfter: credentialsRefreshInterval,
+			expectedProfileCalls: 1,
+		},
+		"valid": {
+			secret:               tokenSecret(map[string][]byte{credentials.DefaultTokenKey: []byte("token")}),
+			expectedReady:        metav1.ConditionTrue,
+			expectedReadyReason:  v1alpha1.ReasonValidated,
+			expectedTokenReason:  v1alpha1.ReasonTokenValid,
+			expectedAccount:      &v1alpha1.LinodeAccount{Username: "fake", Email: "fake@example.com"},
+			expectedRequeueAfter: credentialsRefreshInterval,
+			expectedProfileCalls: 1,
+		},
+		"expiring": {
+			secret: tokenSecret(map[string][]byte{credentials.DefaultTokenKey: []byte("token")}),
+			inject: func(c *lkefake.Client) {
+				c.SetProfile(
+					linodego.Profile{Username: "fake", Email: "fake@example.com"},
+					[]linodego.Token{{Token: "tok", Scopes: "*", Expiry: &expiry}},
+					linodego.GrantsListResponse{},
+				)
+			},
+			expectedReady:        metav1.ConditionTrue,
+			expectedReadyReason:  v1alpha1.ReasonValidated,
+			expectedTokenReason:  v1alpha1.ReasonTokenExpiringSoon,
+			expectedAccount:      &v1alpha1.LinodeAccount{Username: "fake", Email: "fake@example.com"},
+			expectedExpiresAt:    true,
+			expectedRequeueAfter: credentialsRefreshInterval,
+			expectedProfileCalls: 1,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			creds := &v1alpha1.LinodeCredentials{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared", Generation: 1},
+				Spec: v1alpha1.LinodeCredentialsSpec{
+					TokenSecretRef: v1alpha1.SecretRef{Namespace: "operator", Name: "token"},
+				},
+			}
+
+			kubernetesClient := kubefake.NewSimpleClientset()
+			if tc.secret != nil {
+				kubernetesClient = kubefake.NewSimpleClientset(tc.secret)
+			}
+
+			linodeClient := lkefake.NewClient()
+			linodeClient.SetProfile(
+				linodego.Profile{Username: "fake", Email: "fake@example.com"},
+				[]linodego.Token{{Token: "tok", Scopes: "*"}},
+				linodego.GrantsListResponse{},
+			)
+
+			if tc.inject != nil {
+				tc.inject(linodeClient)
+			}
+
+			r := &LinodeCredentialsReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme(t)).
+					WithObjects(creds).
+					WithStatusSubresource(creds).
+					Build(),
+				KubernetesClient:   kubernetesClient,
+				TokenValidator:     credentials.NewValidator(0),
+				TokenExpiryWarning: DefaultTokenExpiryWarning,
+				Recorder:           record.NewFakeRecorder(100),
+				newLinodeClient: func(*credentials.Credential) lkeclient.Client {
+					return linodeClient
+				},
+			}
+
+			res, err := r.Reconcile(context.Background(), ctrl.Request{
+				NamespacedName: client.ObjectKeyFromObject(creds),
+			})
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %#+v, got: %#+v", tc.expectedErr, err)
+			}
+
+			if res.RequeueAfter != tc.expectedRequeueAfter {
+				t.Errorf("expected RequeueAfter value: %#+v, got: %#+v",
+					tc.expectedRequeueAfter, res.RequeueAfter)
+			}
+
+			if calls := len(linodeClient.CallsTo("GetProfile")); calls != tc.expectedProfileCalls {
+				t.Errorf("expected GetProfile calls: %#+v, got: %#+v",
+					tc.expectedProfileCalls, calls)
+			}
+
+			got := &v1alpha1.LinodeCredentials{}
+			if err := r.Get(context.Background(), client.ObjectKeyFromObject(creds), got); err != nil {
+				t.Fatalf("failed to get LinodeCredentials: %v", err)
+			}
+
+			ready := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.ConditionReady)
+			switch {
+			case ready == nil:
+				t.Errorf("expected Ready condition, got: %#+v", got.Status.Conditions)
+			case ready.Status != tc.expectedReady || ready.Reason != tc.expectedReadyReason:
+				t.Errorf("expected Ready condition: %s/%s, got: %s/%s",
+					tc.expectedReady, tc.expectedReadyReason, ready.Status, ready.Reason)
+			case ready.ObservedGeneration != creds.Generation:
+				t.Errorf("expected ObservedGeneration value: %#+v, got: %#+v",
+					creds.Generation, ready.ObservedGeneration)
+			}
+
+			token := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.ConditionCredentialsValid)
+			if reason := conditionReason(token); reason != tc.expectedTokenReason {
+				t.Errorf("expected CredentialsValid reason: %#+v, got: %#+v",
+					tc.expectedTokenReason, reason)
+			}
+
+			if account := got.Status.Account; (account == nil) != (tc.expectedAccount == nil) ||
+				(account != nil && *account != *tc.expectedAccount) {
+				t.Errorf("expected Account value: %#+v, got: %#+v", tc.expectedAccount, account)
+			}
+
+			if expiresAt := got.Status.ExpiresAt != nil; expiresAt != tc.expectedExpiresAt {
+				t.Errorf("expected ExpiresAt set: %#+v, got: %#+v", tc.expectedExpiresAt, got.Status.ExpiresAt)
+			}
+		})
+	}
+}
+
+func conditionReason(cond *metav1.Condition) string {
+	if cond == nil {
+		return ""
+	}
+
+	return cond.Reason
+}
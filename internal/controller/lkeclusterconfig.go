@@ -27,21 +27,19 @@ import (
 	"unicode"
 
 	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/credentials"
 	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
 	"github.com/anza-labs/lke-operator/internal/lkeclient"
 	tracedlke "github.com/anza-labs/lke-operator/internal/lkeclient/traced"
-	"github.com/anza-labs/lke-operator/internal/resty/logger"
-	"github.com/anza-labs/lke-operator/internal/version"
+	"github.com/anza-labs/lke-operator/internal/policy"
 	"github.com/linode/linodego"
 	corev1 "k8s.io/api/core/v1"
 	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/log"
-)
-
-const (
-	TokenKey = "LINODE_TOKEN"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // OnChange must be idempotent
@@ -49,7 +47,7 @@ func (r *LKEClusterConfigReconciler) OnChange(
 	ctx context.Context,
 	lke *v1alpha1.LKEClusterConfig,
 ) (ctrl.Result, error) {
-	client, err := r.newLKEClient(ctx, lke.Spec.TokenSecretRef)
+	client, err := r.newLKEClient(ctx, lke)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to create client: %w", err)
 	}
@@ -83,18 +81,38 @@ func (r *LKEClusterConfigReconciler) onChangeCreate(
 	client lkeclient.Client,
 	lke *v1alpha1.LKEClusterConfig,
 ) (ctrl.Result, error) {
+	// the cluster might have been already created, but the status was lost
+	existing, err := findCluster(ctx, client, lke, r.instanceID())
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to find existing cluster: %w", err)
+	}
+
+	if existing != nil {
+		return r.onChangeRecover(ctx, client, lke, existing)
+	}
+
+	label, err := r.Labels.Render(lke)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := checkLabelAvailable(ctx, client, label); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	opts := linodego.LKEClusterCreateOptions{
-		Label:     lke.Name,
+		Label:     label,
 		Region:    lke.Spec.Region,
 		NodePools: makeNodePools(lke.Spec.NodePools),
 	}
 
-	if lke.Annotations != nil {
-		if rawTags, ok := lke.Annotations[lkeTagsAnnotation]; ok {
-			opts.Tags = extractTags(rawTags)
-		}
+	var tags []string
+	if rawTags, ok := lke.Annotations[lkeTagsAnnotation]; ok {
+		tags = extractTags(rawTags)
 	}
 
+	opts.Tags = ownedTags(lke, r.instanceID(), tags)
+
 	if lke.Spec.HighAvailability != nil {
 		opts.ControlPlane = &linodego.LKEClusterControlPlaneOptions{
 			HighAvailability: lke.Spec.HighAvailability,
@@ -117,8 +135,6 @@ func (r *LKEClusterConfigReconciler) onChangeCreate(
 		opts.K8sVersion = *lke.Spec.KubernetesVersion
 	}
 
-	var err error
-
 	cluster, err := client.CreateLKECluster(ctx, opts)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to create cluster: %w", err)
@@ -148,7 +164,7 @@ func (r *LKEClusterConfigReconciler) onChangeCreate(
 		)
 	}
 
-	return ctrl.Result{Requeue: true}, nil
+	return r.requeue(lke, v1alpha1.PhaseProvisioning), nil
 }
 
 func makeNodePools(nps map[string]v1alpha1.LKENodePool) []linodego.LKENodePoolCreateOptions {
@@ -238,46 +254,81 @@ func getMajorMinor(id string) (int, int, error) {
 	return major, minor, nil
 }
 
+// checkLabelAvailable checks that no LKE cluster in the account uses the label.
+// The cluster created for the object was already looked up by the ownership tags,
+// so the one found here belongs to another object or was created outside of the
+// operator.
+func checkLabelAvailable(ctx context.Context, client lkeclient.Client, label string) error {
+	f := linodego.Filter{}
+	f.AddField(linodego.Eq, "label", label)
+
+	filter, err := f.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to build filter: %w", err)
+	}
+
+	clusters, err := client.ListLKEClusters(ctx, &linodego.ListOptions{Filter: string(filter)})
+	if err != nil {
+		return fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	for _, cluster := range clusters {
+		if cluster.Label == label {
+			return fmt.Errorf("%w: %q is used by LKE cluster %d", internalerrors.ErrLabelInUse, label, cluster.ID)
+		}
+	}
+
+	return nil
+}
+
 func (r *LKEClusterConfigReconciler) onChangeUpdate(
 	ctx context.Context,
 	client lkeclient.Client,
 	lke *v1alpha1.LKEClusterConfig,
 	cluster *linodego.LKECluster,
 ) (ctrl.Result, error) {
-	opts := linodego.LKEClusterUpdateOptions{}
-
-	var (
-		markUpdating        bool
-		destructiveMutation bool
-	)
-
-	opts = updateTags(lke, cluster, opts)
+	pools, err := client.ListLKENodePools(ctx, cluster.ID, &linodego.ListOptions{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list node pools: %w", err)
+	}
 
-	opts, destructiveMutation = updateControlPlane(lke, cluster, opts)
-	if destructiveMutation && !markUpdating {
-		markUpdating = destructiveMutation
+	pools, err = r.handleUnmanagedNodePools(ctx, client, lke, cluster, pools)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to handle unmanaged node pools: %w", err)
 	}
 
-	if markUpdating {
-		lke.Status.Phase = mkptr(v1alpha1.PhaseUpdating)
-		if err := r.Update(ctx, lke); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+	label := cluster.Label
+	if !r.Labels.KeepsExisting(lke) {
+		if label, err = r.Labels.Render(lke); err != nil {
+			return ctrl.Result{}, err
 		}
 	}
 
-	_, err := client.UpdateLKECluster(ctx, cluster.ID, opts)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to update LKE cluster: %w", err)
+	// Once the spec is applied, differences can only come from changes made
+	// outside of the operator, which are corrected only if requested.
+	apply := true
+	if reconciled(lke, label) {
+		apply = r.recordDrift(lke, detectDrift(lke, label, cluster, pools))
 	}
 
-	err = r.reconcileNodePools(ctx, client, lke, cluster)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to update node pools: %w", err)
+	// Destructive changes requiring approval are applied only once approved.
+	pending := apply && !r.approved(lke, cluster, pools)
+
+	if apply && !pending {
+		if err := r.applySpec(ctx, client, lke, label, cluster, pools); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if err := r.consumeApproval(ctx, lke); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else if err := ensureOwnershipTags(ctx, client, lke, r.instanceID(), cluster); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	if err := r.saveKubeconfig(ctx, client, lke, cluster); err != nil {
 		if errors.Is(err, internalerrors.ErrNotReady) {
-			return ctrl.Result{Requeue: true}, nil
+			return r.requeueNotReady(lke), nil
 		}
 
 		return ctrl.Result{}, err
@@ -285,18 +336,80 @@ func (r *LKEClusterConfigReconciler) onChangeUpdate(
 
 	if err := clusterReady(ctx, client, cluster); err != nil {
 		if errors.Is(err, internalerrors.ErrNotReady) {
-			return ctrl.Result{Requeue: true}, nil
+			return r.requeueNotReady(lke), nil
 		}
 
 		return ctrl.Result{}, fmt.Errorf("failed to get cluster readiness: %w", err)
 	}
 
+	if pending {
+		if err := r.Update(ctx, lke); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+		}
+
+		return r.requeue(lke, v1alpha1.PhaseActive), nil
+	}
+
 	lke.Status.Phase = mkptr(v1alpha1.PhaseActive)
+	lke.Status.FailureMessage = nil
+	lke.Status.AppliedHash = appliedHash(lke, label)
+	meta.SetStatusCondition(&lke.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.ReasonReconciled,
+		Message:            "LKE cluster is active",
+		ObservedGeneration: lke.Generation,
+	})
+
 	if err := r.Update(ctx, lke); err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
 	}
 
-	return ctrl.Result{}, nil
+	return r.requeue(lke, v1alpha1.PhaseActive), nil
+}
+
+// applySpec updates the LKE cluster and its node pools to match the spec and
+// the rendered label.
+func (r *LKEClusterConfigReconciler) applySpec(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+	label string,
+	cluster *linodego.LKECluster,
+	pools []linodego.LKENodePool,
+) error {
+	opts := linodego.LKEClusterUpdateOptions{}
+
+	if cluster.Label != label {
+		opts.Label = label
+	}
+
+	opts = updateTags(lke, r.instanceID(), cluster, opts)
+	opts = updateVersion(lke, cluster, opts)
+
+	opts, destructiveMutation := updateControlPlane(lke, cluster, opts)
+	if destructiveMutation {
+		lke.Status.Phase = mkptr(v1alpha1.PhaseUpdating)
+		if err := r.Update(ctx, lke); err != nil {
+			return fmt.Errorf("failed to update status: %w", err)
+		}
+	}
+
+	if destructiveMutation || opts.Tags != nil || opts.Label != "" || opts.K8sVersion != "" {
+		if _, err := client.UpdateLKECluster(ctx, cluster.ID, opts); err != nil {
+			return fmt.Errorf("failed to update LKE cluster: %w", err)
+		}
+	}
+
+	if err := r.deleteUnmanagedNodePools(ctx, client, lke, cluster, pools); err != nil {
+		return err
+	}
+
+	if err := r.reconcileNodePools(ctx, client, lke, cluster, pools); err != nil {
+		return fmt.Errorf("failed to update node pools: %w", err)
+	}
+
+	return nil
 }
 
 func (r *LKEClusterConfigReconciler) saveKubeconfig(
@@ -316,7 +429,7 @@ func (r *LKEClusterConfigReconciler) saveKubeconfig(
 
 	var (
 		sc         = r.KubernetesClient.CoreV1().Secrets(lke.Namespace)
-		secretName = lke.Name + "-kubeconfig"
+		secretName = kubeconfigSecretName(lke)
 	)
 
 	// try to get secret, if not exists, create, else update
@@ -330,6 +443,7 @@ func (r *LKEClusterConfigReconciler) saveKubeconfig(
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      secretName,
 				Namespace: lke.Namespace,
+				Labels:    publishedSecretLabels(lke),
 			},
 			Data: map[string][]byte{
 				kubeconfigKey: []byte(kubeconfig.KubeConfig),
@@ -343,8 +457,18 @@ func (r *LKEClusterConfigReconciler) saveKubeconfig(
 	}
 
 	kc, ok := secret.Data[kubeconfigKey]
-	if !ok || !bytes.Equal(kc, []byte(kubeconfig.KubeConfig)) {
+	if !ok || !bytes.Equal(kc, []byte(kubeconfig.KubeConfig)) ||
+		secret.Labels[lkeOwnerUIDLabel] != string(lke.UID) {
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+
 		secret.Data[kubeconfigKey] = []byte(kubeconfig.KubeConfig)
+		secret.Labels[lkeOwnerUIDLabel] = string(lke.UID)
 
 		_, err := sc.Update(ctx, secret, metav1.UpdateOptions{})
 		if err != nil {
@@ -355,27 +479,25 @@ func (r *LKEClusterConfigReconciler) saveKubeconfig(
 	return nil
 }
 
+// updateTags sets the tags from the annotation, preserving the ownership tags.
+// Tags of the LKE cluster are left untouched if the annotation is not set.
 func updateTags(
 	lke *v1alpha1.LKEClusterConfig,
+	instance string,
 	cluster *linodego.LKECluster,
 	opts linodego.LKEClusterUpdateOptions,
 ) linodego.LKEClusterUpdateOptions {
-	if lke.Annotations == nil {
-		lke.Annotations = make(map[string]string)
-	}
+	live := slices.Clone(cluster.Tags)
+	slices.Sort(live)
 
-	rawTags, ok := lke.Annotations[lkeTagsAnnotation]
-	if !ok {
-		// nothing to do
-		return opts
+	tags := live
+	if rawTags, ok := lke.Annotations[lkeTagsAnnotation]; ok {
+		tags = extractTags(rawTags)
 	}
 
-	tags := extractTags(rawTags)
-
-	slices.Sort(tags)
-	slices.Sort(cluster.Tags)
+	tags = ownedTags(lke, instance, tags)
 
-	if slices.Equal(tags, cluster.Tags) {
+	if slices.Equal(tags, live) {
 		// nothing to do
 		return opts
 	}
@@ -385,6 +507,30 @@ func updateTags(
 	return opts
 }
 
+// updateVersion upgrades the LKE cluster to the Kubernetes version set in the spec.
+// Clusters following the latest version are not upgraded.
+func updateVersion(
+	lke *v1alpha1.LKEClusterConfig,
+	cluster *linodego.LKECluster,
+	opts linodego.LKEClusterUpdateOptions,
+) linodego.LKEClusterUpdateOptions {
+	if version := desiredVersion(lke); version != "" && version != cluster.K8sVersion {
+		opts.K8sVersion = version
+	}
+
+	return opts
+}
+
+// desiredVersion returns the Kubernetes version set in the spec, or empty string
+// if the cluster follows the latest version.
+func desiredVersion(lke *v1alpha1.LKEClusterConfig) string {
+	if lke.Spec.KubernetesVersion == nil || *lke.Spec.KubernetesVersion == "latest" {
+		return ""
+	}
+
+	return *lke.Spec.KubernetesVersion
+}
+
 func updateControlPlane(
 	lke *v1alpha1.LKEClusterConfig,
 	cluster *linodego.LKECluster,
@@ -406,11 +552,19 @@ func (r *LKEClusterConfigReconciler) reconcileNodePools(
 	client lkeclient.Client,
 	lke *v1alpha1.LKEClusterConfig,
 	cluster *linodego.LKECluster,
+	pools []linodego.LKENodePool,
 ) error {
+	pools, err := reconcileReplacements(ctx, client, lke, cluster, pools)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile replaced node pools: %w", err)
+	}
+
 	specStatuses := generateNodePoolStatusesFromSpec(lke.Spec.NodePools)
-	statusStatues := lke.Status.NodePoolStatuses
+	liveStatuses := generateNodePoolStatusesFromAPI(pools)
+
+	ignoreAutoscaledCounts(specStatuses, liveStatuses)
 
-	change, delete, create := compareNodePoolStatuses(specStatuses, statusStatues)
+	change, delete, create := compareNodePoolStatuses(specStatuses, liveStatuses)
 
 	if len(change) > 0 || len(delete) > 0 || len(create) > 0 {
 		lke.Status.Phase = mkptr(v1alpha1.PhaseUpdating)
@@ -419,11 +573,11 @@ func (r *LKEClusterConfigReconciler) reconcileNodePools(
 		}
 	}
 
-	if err := createNodePools(ctx, client, cluster, delete); err != nil {
+	if err := createNodePools(ctx, client, cluster, create); err != nil {
 		return fmt.Errorf("failed to create node pools: %w", err)
 	}
 
-	if err := updateNodePools(ctx, client, cluster, delete); err != nil {
+	if err := updateNodePools(ctx, client, cluster, change, liveStatuses); err != nil {
 		return fmt.Errorf("failed to update node pools: %w", err)
 	}
 
@@ -444,6 +598,38 @@ func (r *LKEClusterConfigReconciler) reconcileNodePools(
 	return nil
 }
 
+// ignoreAutoscaledCounts copies the live node count to the spec of autoscaled
+// node pools, as their size is managed by the autoscaler.
+func ignoreAutoscaledCounts(spec, live map[string]v1alpha1.NodePoolStatus) {
+	for name, status := range spec {
+		current, ok := live[name]
+		if !ok || status.NodePoolDetails.Autoscaler == nil {
+			continue
+		}
+
+		status.NodePoolDetails.NodeCount = current.NodePoolDetails.NodeCount
+		spec[name] = status
+	}
+}
+
+func nodePoolCreateOptions(name string, np v1alpha1.LKENodePool) linodego.LKENodePoolCreateOptions {
+	opts := linodego.LKENodePoolCreateOptions{
+		Count: np.NodeCount,
+		Type:  np.LinodeType,
+		Tags:  []string{lkeOperatorTag + name},
+	}
+
+	if np.Autoscaler != nil {
+		opts.Autoscaler = &linodego.LKENodePoolAutoscaler{
+			Enabled: true,
+			Min:     np.Autoscaler.Min,
+			Max:     np.Autoscaler.Max,
+		}
+	}
+
+	return opts
+}
+
 func createNodePools(
 	ctx context.Context,
 	client lkeclient.Client,
@@ -451,11 +637,7 @@ func createNodePools(
 	statuses map[string]v1alpha1.NodePoolStatus,
 ) error {
 	for name, status := range statuses {
-		opts := linodego.LKENodePoolCreateOptions{
-			Count: status.NodePoolDetails.NodeCount,
-			Type:  status.NodePoolDetails.LinodeType,
-			Tags:  []string{lkeOperatorTag + name},
-		}
+		opts := nodePoolCreateOptions(name, status.NodePoolDetails)
 
 		if _, err := client.CreateLKENodePool(ctx, cluster.ID, opts); err != nil {
 			return fmt.Errorf("failed to create node pool: %w", err)
@@ -465,32 +647,51 @@ func createNodePools(
 	return nil
 }
 
+// updateNodePools updates the live node pools to match the spec. Pools with
+// changed Linode type are replaced, as the type cannot be updated in place.
 func updateNodePools(
 	ctx context.Context,
 	client lkeclient.Client,
 	cluster *linodego.LKECluster,
 	statuses map[string]v1alpha1.NodePoolStatus,
+	live map[string]v1alpha1.NodePoolStatus,
 ) error {
 	for name, status := range statuses {
-		if status.ID != nil {
-			if _, err := client.UpdateLKENodePool(
-				ctx,
-				cluster.ID,
-				*status.ID,
-				linodego.LKENodePoolUpdateOptions{},
-			); err != nil {
-				return fmt.Errorf("failed to delete node pool: %w", err)
-			}
-		} else {
-			opts := linodego.LKENodePoolCreateOptions{
-				Count: status.NodePoolDetails.NodeCount,
-				Type:  status.NodePoolDetails.LinodeType,
-				Tags:  []string{lkeOperatorTag + name},
-			}
+		current := live[name]
+
+		if current.ID == nil {
+			opts := nodePoolCreateOptions(name, status.NodePoolDetails)
 
 			if _, err := client.CreateLKENodePool(ctx, cluster.ID, opts); err != nil {
 				return fmt.Errorf("failed to up-create node pool: %w", err)
 			}
+
+			continue
+		}
+
+		if current.NodePoolDetails.LinodeType != status.NodePoolDetails.LinodeType {
+			if err := replaceNodePool(ctx, client, cluster, name, status, *current.ID); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		opts := linodego.LKENodePoolUpdateOptions{
+			Count:      status.NodePoolDetails.NodeCount,
+			Autoscaler: &linodego.LKENodePoolAutoscaler{Enabled: false},
+		}
+
+		if as := status.NodePoolDetails.Autoscaler; as != nil {
+			opts.Autoscaler = &linodego.LKENodePoolAutoscaler{
+				Enabled: true,
+				Min:     as.Min,
+				Max:     as.Max,
+			}
+		}
+
+		if _, err := client.UpdateLKENodePool(ctx, cluster.ID, *current.ID, opts); err != nil {
+			return fmt.Errorf("failed to update node pool: %w", err)
 		}
 	}
 
@@ -505,11 +706,7 @@ func deleteNodePools(
 ) error {
 	for _, status := range statuses {
 		if status.ID != nil {
-			if err := client.DeleteLKENodePool(ctx, cluster.ID, *status.ID); err != nil {
-				if errors.Is(err, internalerrors.ErrLinodeNotFound) {
-					continue
-				}
-
+			if err := deleteNodePool(ctx, client, cluster, *status.ID); err != nil {
 				return fmt.Errorf("failed to delete node pool: %w", err)
 			}
 		}
@@ -589,44 +786,59 @@ func generateNodePoolStatusesFromSpec(nps map[string]v1alpha1.LKENodePool) map[s
 	return statuses
 }
 
+// generateNodePoolStatusesFromAPI returns statuses of the node pools created
+// by the operator, keyed by the name from the node pool tag.
 func generateNodePoolStatusesFromAPI(nps []linodego.LKENodePool) map[string]v1alpha1.NodePoolStatus {
 	statuses := map[string]v1alpha1.NodePoolStatus{}
 
 	for _, np := range nps {
-		name := fmt.Sprintf("unknown-%d", np.ID)
-
-	tagrange:
-		for _, tag := range np.Tags {
-			if strings.HasPrefix(tag, lkeOperatorTag) {
-				split := strings.Split(tag, "=")
-				if len(split) != 2 {
-					continue tagrange
-				}
-
-				name = split[1]
-				break tagrange
-			}
+		name, ok := nodePoolName(np)
+		if !ok {
+			continue
 		}
 
-		status := v1alpha1.NodePoolStatus{
-			ID: mkptr(np.ID),
-			NodePoolDetails: v1alpha1.LKENodePool{
-				NodeCount:  np.Count,
-				LinodeType: np.Type,
-			},
+		statuses[name] = nodePoolStatusFromAPI(np)
+	}
+
+	return statuses
+}
+
+// nodePoolName returns the name of the node pool from its tag. False is
+// returned if the node pool was not created by the operator.
+func nodePoolName(np linodego.LKENodePool) (string, bool) {
+	for _, tag := range np.Tags {
+		if !strings.HasPrefix(tag, lkeOperatorTag) {
+			continue
 		}
 
-		if np.Autoscaler.Enabled {
-			status.NodePoolDetails.Autoscaler = &v1alpha1.LKENodePoolAutoscaler{
-				Min: np.Autoscaler.Min,
-				Max: np.Autoscaler.Max,
-			}
+		split := strings.Split(tag, "=")
+		if len(split) != 2 {
+			continue
 		}
 
-		statuses[name] = status
+		return split[1], true
 	}
 
-	return statuses
+	return "", false
+}
+
+func nodePoolStatusFromAPI(np linodego.LKENodePool) v1alpha1.NodePoolStatus {
+	status := v1alpha1.NodePoolStatus{
+		ID: mkptr(np.ID),
+		NodePoolDetails: v1alpha1.LKENodePool{
+			NodeCount:  np.Count,
+			LinodeType: np.Type,
+		},
+	}
+
+	if np.Autoscaler.Enabled {
+		status.NodePoolDetails.Autoscaler = &v1alpha1.LKENodePoolAutoscaler{
+			Min: np.Autoscaler.Min,
+			Max: np.Autoscaler.Max,
+		}
+	}
+
+	return status
 }
 
 func stripSpaces(str string) string {
@@ -659,111 +871,132 @@ func extractTags(s string) []string {
 	return arr
 }
 
-// OnDelete must be idempotent
-func (r *LKEClusterConfigReconciler) OnDelete(
+// credentialsFor returns the provider of the token used to manage the LKE cluster.
+func (r *LKEClusterConfigReconciler) credentialsFor(
 	ctx context.Context,
 	lke *v1alpha1.LKEClusterConfig,
-) (ctrl.Result, error) {
-	client, err := r.newLKEClient(ctx, lke.Spec.TokenSecretRef)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to create client: %w", err)
-	}
-
-	lke.Status.Phase = mkptr(v1alpha1.PhaseDeleting)
-
-	if err := r.Update(ctx, lke); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to set phase %s: %w",
-			v1alpha1.PhaseDeleting,
-			err,
-		)
-	}
-
-	return r.onDelete(ctx, client, lke)
+) (credentials.Provider, error) {
+	return resolveCredentials(ctx, r.Client, r.KubernetesClient, r.ReferencePolicy, r.DefaultCredentials, lke)
 }
 
-func (r *LKEClusterConfigReconciler) onDelete(
+// resolveCredentials returns the provider of the token referenced by the object,
+// falling back to the default credentials.
+func resolveCredentials(
 	ctx context.Context,
-	client lkeclient.Client,
+	reader k8sclient.Reader,
+	kubernetesClient kubernetes.Interface,
+	referencePolicy *policy.ReferencePolicy,
+	defaultCredentials credentials.Provider,
 	lke *v1alpha1.LKEClusterConfig,
-) (ctrl.Result, error) {
-	if lke.Status.ClusterID == nil {
-		return ctrl.Result{}, internalerrors.ErrNoClusterID
+) (credentials.Provider, error) {
+	if ref := lke.Spec.TokenSecretRef; ref != nil {
+		if err := referencePolicy.CheckSecretRef(ctx, lke.Namespace, *ref); err != nil {
+			return nil, err
+		}
+
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = lke.Namespace
+		}
+
+		return credentials.Secret(kubernetesClient, credentials.SecretRef{
+			Namespace: namespace,
+			Name:      ref.Name,
+			Key:       ref.Key,
+		}), nil
 	}
 
-	_, err := client.GetLKECluster(ctx, *lke.Status.ClusterID)
-	if err != nil {
-		if !errors.Is(err, internalerrors.ErrLinodeNotFound) {
-			return ctrl.Result{}, fmt.Errorf("failed to get cluster: %w", err)
+	if ref := lke.Spec.CredentialsRef; ref != nil {
+		creds, err := policy.CredentialsFor(ctx, reader, lke.Namespace, *ref)
+		if err != nil {
+			return nil, err
 		}
 
-		return ctrl.Result{}, nil
+		return linodeCredentialsProvider(kubernetesClient, creds), nil
 	}
 
-	if err = client.DeleteLKECluster(ctx, *lke.Status.ClusterID); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to remove cluster: %w", err)
+	if defaultCredentials != nil {
+		return defaultCredentials, nil
 	}
 
-	return ctrl.Result{Requeue: true}, nil
+	return nil, internalerrors.ErrNoCredentials
 }
 
-func (r *LKEClusterConfigReconciler) secretFromRef(
-	ctx context.Context,
-	ref v1alpha1.SecretRef,
-) (*corev1.Secret, error) {
-	log := log.FromContext(ctx)
-
-	log.V(8).Info("fetching token for client",
-		"secret.name", ref.Name,
-		"secret.namespace", ref.Namespace)
-
-	secret, err := r.KubernetesClient.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
+// linodeCredentialsProvider returns the provider of the token referenced by the
+// LinodeCredentials, using the Linode API endpoint configured in them.
+func linodeCredentialsProvider(
+	kubernetesClient kubernetes.Interface,
+	creds *v1alpha1.LinodeCredentials,
+) credentials.Provider {
+	ref := creds.Spec.TokenSecretRef
+
+	provider := credentials.Secret(kubernetesClient, credentials.SecretRef{
+		Namespace: ref.Namespace,
+		Name:      ref.Name,
+		Key:       ref.Key,
+	})
+
+	if api := creds.Spec.API; api != nil {
+		provider = credentials.WithEndpoint(provider, lkeclient.Endpoint{
+			URL:     api.URL,
+			Version: api.Version,
+			Proxy:   api.Proxy,
+			CA:      api.CABundle,
+		})
 	}
 
-	return secret, nil
+	return provider
 }
 
 func (r *LKEClusterConfigReconciler) newLKEClient(
 	ctx context.Context,
-	ref v1alpha1.SecretRef,
+	lke *v1alpha1.LKEClusterConfig,
 ) (lkeclient.Client, error) {
-	log := log.FromContext(ctx)
+	provider, err := r.credentialsFor(ctx, lke)
+	if err != nil {
+		return nil, err
+	}
 
-	secret, err := r.secretFromRef(ctx, ref)
+	cred, err := provider.Credential(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get secret: %w", err)
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
 	}
 
-	if secret == nil {
-		return nil, fmt.Errorf("%w: %s/%s",
-			internalerrors.ErrNilSecret,
-			ref.Namespace,
-			ref.Name,
+	var linodeClient lkeclient.Client
+	if r.newLinodeClient != nil {
+		linodeClient = r.newLinodeClient(cred)
+	} else {
+		linodeClient = r.LinodeClients.Get(
+			cred.Key,
+			cred.Token,
+			lkeclient.WithEndpoint(cred.Endpoint),
 		)
 	}
 
-	token, ok := secret.Data[TokenKey]
-	if !ok {
-		return nil, fmt.Errorf("%w: %s/%s (key:%q)",
-			internalerrors.ErrTokenMissing,
-			secret.Namespace,
-			secret.Name,
-			TokenKey,
+	client := tracedlke.NewClientWithTracing(linodeClient, "dynamic_lke_traced_client")
+
+	if r.TokenValidator != nil {
+		_, changed, err := validateToken(ctx,
+			r.TokenValidator,
+			client,
+			cred,
+			r.TokenExpiryWarning,
+			&lke.Status.Conditions,
+			lke.Generation,
 		)
-	}
+		if changed {
+			recordTokenCondition(r.Recorder, lke,
+				meta.FindStatusCondition(lke.Status.Conditions, v1alpha1.ConditionCredentialsValid))
 
-	ua := fmt.Sprintf("lke-operator/%s (%s; %s)",
-		version.Version,
-		version.OS,
-		version.Arch,
-	)
+			if uerr := r.Update(ctx, lke); uerr != nil {
+				return nil, errors.Join(err, uerr)
+			}
+		}
 
-	client := lkeclient.New(string(token), ua)
-	client.SetLogger(logger.Wrap(log))
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials: %w", err)
+		}
+	}
 
-	return tracedlke.NewClientWithTracing(
-		client,
-		"dynamic_lke_traced_client",
-	), nil
+	return client, nil
 }
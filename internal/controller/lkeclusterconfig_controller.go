@@ -19,17 +19,35 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/anza-labs/lke-operator/api/v1alpha1"
 	lkev1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/credentials"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/label"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	"github.com/anza-labs/lke-operator/internal/policy"
 )
 
 // LKEClusterConfigReconciler reconciles a LKEClusterConfig object
@@ -37,13 +55,66 @@ type LKEClusterConfigReconciler struct {
 	client.Client
 	Scheme           *runtime.Scheme
 	KubernetesClient kubernetes.Interface
+
+	// LinodeClients shares Linode clients, rate limiters and circuit breakers
+	// between reconciliations of objects using the same token.
+	LinodeClients *lkeclient.Cache
+
+	// DefaultCredentials provides the token used by objects not referencing any.
+	DefaultCredentials credentials.Provider
+
+	// ReferencePolicy decides if objects may reference Secrets in other namespaces.
+	ReferencePolicy *policy.ReferencePolicy
+
+	// TokenValidator checks the scopes and expiry of the tokens.
+	TokenValidator *credentials.Validator
+
+	// TokenExpiryWarning is the time before the token expiry, from which the
+	// upcoming expiry is reported.
+	TokenExpiryWarning time.Duration
+
+	Recorder record.EventRecorder
+
+	// Labels renders the labels of the LKE clusters.
+	Labels label.Template
+
+	// InstanceID identifies the operator instance in the ownership tags of the
+	// LKE clusters. Defaults to DefaultInstanceID.
+	InstanceID string
+
+	// DeletionTimeout is the time to wait for the LKE cluster to disappear after
+	// requesting its deletion, before the timeout is reported and the deletion
+	// requested again. Defaults to DefaultDeletionTimeout.
+	DeletionTimeout time.Duration
+
+	// RequeueIntervals are the intervals after which the objects are
+	// reconciled again in the given phase.
+	RequeueIntervals RequeueIntervals
+
+	// MaxConcurrentReconciles is the maximum number of concurrent Reconciles.
+	// Defaults to 1.
+	MaxConcurrentReconciles int
+
+	// RateLimiter limits how frequently failed objects are reconciled again.
+	// Defaults to the controller-runtime default rate limiter.
+	RateLimiter ratelimiter.RateLimiter
+
+	// newWorkloadClient creates the client of the workload cluster from its kubeconfig.
+	// Defaults to newWorkloadClient.
+	newWorkloadClient func(kubeconfig []byte) (kubernetes.Interface, error)
+
+	// newLinodeClient returns the Linode client using the credential.
+	// Defaults to the client shared by LinodeClients.
+	newLinodeClient func(cred *credentials.Credential) lkeclient.Client
 }
 
 // +kubebuilder:rbac:groups=lke.anza-labs.dev,resources=lkeclusterconfigs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=lke.anza-labs.dev,resources=lkeclusterconfigs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=lke.anza-labs.dev,resources=lkeclusterconfigs/finalizers,verbs=update
+// +kubebuilder:rbac:groups=lke.anza-labs.dev,resources=referencegrants,verbs=get;list;watch
+// +kubebuilder:rbac:groups=lke.anza-labs.dev,resources=linodecredentials,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=create;delete;get;update
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=create;delete;get;list;update;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -73,14 +144,41 @@ func (r *LKEClusterConfigReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
-	if !lke.DeletionTimestamp.IsZero() && lke.Status.ClusterID != nil {
+	if paused(lke) {
+		log.Info("reconciliation paused",
+			"annotation", v1alpha1.PausedAnnotation)
+		return ctrl.Result{}, nil
+	}
+
+	if !lke.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(lke, lkeFinalizer) {
+			log.Info("object is being deleted, nothing to clean up")
+			return ctrl.Result{}, nil
+		}
+
+		if r.deletionProtected(lke) {
+			log.Info("deletion blocked by deletion protection")
+
+			// reconciled again once the protection is disabled
+			if err := r.Update(ctx, lke); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			return ctrl.Result{}, nil
+		}
+
 		res, err := r.OnDelete(ctx, lke)
+		if res, ok := backoffOnOpenCircuit(err); ok {
+			log.Info("Linode API unavailable, backing off", "requeue.after", res.RequeueAfter)
+			return res, nil
+		}
+
 		if err != nil {
 			log.Error(err, "on LKE deletion failed")
-			return ctrl.Result{}, r.setFailureMessage(ctx, lke, err)
+			return r.handleError(ctx, lke, err)
 		}
 
-		if !res.Requeue {
+		if res.IsZero() {
 			log.Info("removing finalizer",
 				"finalizer", lkeFinalizer)
 
@@ -107,31 +205,299 @@ func (r *LKEClusterConfigReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	}
 
 	res, err := r.OnChange(ctx, lke)
+	if res, ok := backoffOnOpenCircuit(err); ok {
+		log.Info("Linode API unavailable, backing off", "requeue.after", res.RequeueAfter)
+		return res, nil
+	}
+
 	if err != nil {
 		log.Error(err, "on LKE change failed")
-		return ctrl.Result{}, r.setFailureMessage(ctx, lke, err)
+		return r.handleError(ctx, lke, err)
 	}
 
 	return res, nil
 }
 
+// paused returns true if the reconciliation of the object is paused with the annotation.
+func paused(lke *v1alpha1.LKEClusterConfig) bool {
+	return lke.Annotations[v1alpha1.PausedAnnotation] == "true"
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *LKEClusterConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.LinodeClients == nil {
+		r.LinodeClients = lkeclient.NewCache(lkeclient.DefaultCacheOptions())
+	}
+
+	if r.TokenValidator == nil {
+		r.TokenValidator = credentials.NewValidator(credentials.DefaultValidationTTL)
+	}
+
+	if r.TokenExpiryWarning == 0 {
+		r.TokenExpiryWarning = DefaultTokenExpiryWarning
+	}
+
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("lkeclusterconfig-controller")
+	}
+
+	if r.RequeueIntervals == (RequeueIntervals{}) {
+		r.RequeueIntervals = DefaultRequeueIntervals()
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&lkev1alpha1.LKEClusterConfig{},
+		tokenSecretRefField,
+		indexTokenSecretRef,
+	); err != nil {
+		return fmt.Errorf("failed to index %s: %w", tokenSecretRefField, err)
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&lkev1alpha1.LKEClusterConfig{},
+		credentialsRefField,
+		indexCredentialsRef,
+	); err != nil {
+		return fmt.Errorf("failed to index %s: %w", credentialsRefField, err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&lkev1alpha1.LKEClusterConfig{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+			RateLimiter:             r.RateLimiter,
+		}).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForSecret),
+			builder.OnlyMetadata,
+		).
+		Watches(
+			&lkev1alpha1.ReferenceGrant{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForReferenceGrant),
+		).
+		Watches(
+			&lkev1alpha1.LinodeCredentials{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForCredentials),
+		).
 		Complete(r)
 }
 
-func (r *LKEClusterConfigReconciler) setFailureMessage(
+// indexTokenSecretRef indexes LKEClusterConfig by the namespaced name of the
+// Secret holding its token.
+func indexTokenSecretRef(obj client.Object) []string {
+	lke, ok := obj.(*lkev1alpha1.LKEClusterConfig)
+	if !ok {
+		return nil
+	}
+
+	ref := lke.Spec.TokenSecretRef
+	if ref == nil {
+		return nil
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = lke.Namespace
+	}
+
+	return []string{types.NamespacedName{
+		Namespace: namespace,
+		Name:      ref.Name,
+	}.String()}
+}
+
+// requestsForSecret enqueues all LKEClusterConfigs referencing the Secret.
+func (r *LKEClusterConfigReconciler) requestsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := log.FromContext(ctx)
+
+	key := client.ObjectKeyFromObject(obj).String()
+
+	list := &lkev1alpha1.LKEClusterConfigList{}
+	if err := r.List(ctx, list, client.MatchingFields{tokenSecretRefField: key}); err != nil {
+		log.Error(err, "failed to list LKEClusterConfigs referencing secret",
+			"secret", key)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, lke := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&lke),
+		})
+	}
+
+	return requests
+}
+
+// indexCredentialsRef indexes LKEClusterConfig by the name of the
+// LinodeCredentials it uses.
+func indexCredentialsRef(obj client.Object) []string {
+	lke, ok := obj.(*lkev1alpha1.LKEClusterConfig)
+	if !ok {
+		return nil
+	}
+
+	if lke.Spec.CredentialsRef == nil {
+		return nil
+	}
+
+	return []string{lke.Spec.CredentialsRef.Name}
+}
+
+// requestsForCredentials enqueues all LKEClusterConfigs using the LinodeCredentials.
+func (r *LKEClusterConfigReconciler) requestsForCredentials(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := log.FromContext(ctx)
+
+	list := &lkev1alpha1.LKEClusterConfigList{}
+	if err := r.List(ctx, list, client.MatchingFields{credentialsRefField: obj.GetName()}); err != nil {
+		log.Error(err, "failed to list LKEClusterConfigs using credentials",
+			"credentials", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, lke := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&lke),
+		})
+	}
+
+	return requests
+}
+
+// backoffOnOpenCircuit returns result delaying the next reconciliation until
+// the circuit breaker for the token closes.
+func backoffOnOpenCircuit(err error) (ctrl.Result, bool) {
+	var coe *internalerrors.CircuitOpenError
+	if !errors.As(err, &coe) {
+		return ctrl.Result{}, false
+	}
+
+	return ctrl.Result{RequeueAfter: coe.RetryAfter}, true
+}
+
+// requestsForReferenceGrant enqueues all LKEClusterConfigs from the namespaces
+// listed in the grant, that reference Secrets in the namespace of the grant.
+func (r *LKEClusterConfigReconciler) requestsForReferenceGrant(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := log.FromContext(ctx)
+
+	grant, ok := obj.(*lkev1alpha1.ReferenceGrant)
+	if !ok {
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+
+	for _, from := range grant.Spec.From {
+		list := &lkev1alpha1.LKEClusterConfigList{}
+		if err := r.List(ctx, list, client.InNamespace(from.Namespace)); err != nil {
+			log.Error(err, "failed to list LKEClusterConfigs",
+				"namespace", from.Namespace)
+			continue
+		}
+
+		for _, lke := range list.Items {
+			if ref := lke.Spec.TokenSecretRef; ref != nil && ref.Namespace == grant.Namespace {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: client.ObjectKeyFromObject(&lke),
+				})
+			}
+		}
+	}
+
+	return requests
+}
+
+// event emits the Event for the object, if the recorder is configured.
+func (r *LKEClusterConfigReconciler) event(lke *lkev1alpha1.LKEClusterConfig, eventType, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+
+	r.Recorder.Event(lke, eventType, reason, message)
+}
+
+// handleError records the failure in the status, and decides if the
+// reconciliation should be retried with backoff, or the object is in the
+// terminal state until its spec or credentials change.
+func (r *LKEClusterConfigReconciler) handleError(
 	ctx context.Context,
 	lke *lkev1alpha1.LKEClusterConfig,
 	err error,
-) error {
+) (ctrl.Result, error) {
+	reason, terminal, retryAfter := classifyError(err)
+
+	meta.SetStatusCondition(&lke.Status.Conditions, metav1.Condition{
+		Type:               lkev1alpha1.ConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            conditionMessage(err),
+		ObservedGeneration: lke.Generation,
+	})
+
 	lke.Status.FailureMessage = mkptr(err.Error())
-	lke.Status.Phase = mkptr(lkev1alpha1.PhaseError)
+	if terminal {
+		lke.Status.Phase = mkptr(lkev1alpha1.PhaseError)
+	}
+
 	if uerr := r.Update(ctx, lke); uerr != nil {
-		return errors.Join(err, uerr)
+		return ctrl.Result{}, errors.Join(err, uerr)
+	}
+
+	switch {
+	case terminal:
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	case retryAfter > 0:
+		return ctrl.Result{RequeueAfter: retryAfter}, nil
+	default:
+		return ctrl.Result{}, err
+	}
+}
+
+// classifyError returns the condition reason for the error, reports if
+// retrying cannot succeed, and the delay requested by the Linode API.
+func classifyError(err error) (string, bool, time.Duration) {
+	if lerr := internalerrors.Classify(err); lerr != nil {
+		return lerr.Reason(), lerr.Terminal(), lerr.RetryAfter
+	}
+
+	switch {
+	case errors.Is(err, internalerrors.ErrNoCredentials),
+		errors.Is(err, internalerrors.ErrReferenceNotPermitted),
+		errors.Is(err, internalerrors.ErrTokenExpired),
+		errors.Is(err, internalerrors.ErrTokenMissingScopes),
+		errors.Is(err, internalerrors.ErrTokenMissingGrants):
+		return lkev1alpha1.ReasonInvalidCredentials, true, 0
+	case errors.Is(err, internalerrors.ErrInvalidLabel):
+		return lkev1alpha1.ReasonInvalidLabel, true, 0
+	case errors.Is(err, internalerrors.ErrLabelInUse):
+		// retried, as the LKE cluster using the label may be renamed or deleted
+		return lkev1alpha1.ReasonInvalidLabel, false, 0
+	default:
+		return lkev1alpha1.ReasonReconcileFailed, false, 0
+	}
+}
+
+// conditionMessage returns the reasons returned by the Linode API, or the
+// error message if there are none.
+func conditionMessage(err error) string {
+	lerr := internalerrors.Classify(err)
+	if lerr == nil || len(lerr.Reasons) == 0 {
+		return err.Error()
+	}
+
+	reasons := make([]string, 0, len(lerr.Reasons))
+	for _, r := range lerr.Reasons {
+		if r.Field == "" {
+			reasons = append(reasons, r.Reason)
+			continue
+		}
+
+		reasons = append(reasons, r.Field+": "+r.Reason)
 	}
 
-	return err
+	return strings.Join(reasons, "; ")
 }
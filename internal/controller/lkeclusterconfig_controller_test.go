@@ -0,0 +1,194 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add core/v1 to scheme: %v", err)
+	}
+
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add lke/v1alpha1 to scheme: %v", err)
+	}
+
+	return scheme
+}
+
+func newTestLKEClusterConfig(namespace, name string, ref *v1alpha1.SecretRef) *v1alpha1.LKEClusterConfig {
+	return &v1alpha1.LKEClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: v1alpha1.LKEClusterConfigSpec{
+			Region:         "us-east",
+			TokenSecretRef: ref,
+			NodePools: map[string]v1alpha1.LKENodePool{
+				"default": {NodeCount: 1, LinodeType: "g6-standard-1"},
+			},
+		},
+	}
+}
+
+func TestLKEClusterConfigReconciler_requestsForSecret(t *testing.T) {
+	t.Parallel()
+
+	scheme := newTestScheme(t)
+
+	for name, tc := range map[string]struct {
+		secret           types.NamespacedName
+		expectedRequests []reconcile.Request
+	}{
+		"same_namespace": {
+			secret: types.NamespacedName{Namespace: "foo", Name: "token"},
+			expectedRequests: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "a"}},
+				{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "b"}},
+			},
+		},
+		"cross_namespace": {
+			secret: types.NamespacedName{Namespace: "shared", Name: "token"},
+			expectedRequests: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "bar", Name: "c"}},
+			},
+		},
+		"unreferenced": {
+			secret:           types.NamespacedName{Namespace: "foo", Name: "other"},
+			expectedRequests: []reconcile.Request{},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cli := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithIndex(&v1alpha1.LKEClusterConfig{}, tokenSecretRefField, indexTokenSecretRef).
+				WithObjects(
+					newTestLKEClusterConfig("foo", "a", &v1alpha1.SecretRef{Namespace: "foo", Name: "token"}),
+					newTestLKEClusterConfig("foo", "b", &v1alpha1.SecretRef{Namespace: "foo", Name: "token"}),
+					newTestLKEClusterConfig("bar", "c", &v1alpha1.SecretRef{Namespace: "shared", Name: "token"}),
+					newTestLKEClusterConfig("bar", "d", nil),
+				).
+				Build()
+
+			r := &LKEClusterConfigReconciler{Client: cli, Scheme: scheme}
+
+			secret := &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: tc.secret.Namespace,
+					Name:      tc.secret.Name,
+				},
+			}
+
+			requests := r.requestsForSecret(context.Background(), secret)
+
+			compareFunc := func(a, b reconcile.Request) int {
+				return strings.Compare(a.String(), b.String())
+			}
+
+			slices.SortFunc(requests, compareFunc)
+			slices.SortFunc(tc.expectedRequests, compareFunc)
+
+			if !reflect.DeepEqual(requests, tc.expectedRequests) {
+				t.Errorf("expected Requests value: %#+v, got: %#+v",
+					tc.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestLKEClusterConfigReconciler_Reconcile_paused(t *testing.T) {
+	t.Parallel()
+
+	scheme := newTestScheme(t)
+
+	for name, tc := range map[string]struct {
+		finalizers         []string
+		deletionTimestamp  *metav1.Time
+		expectedFinalizers []string
+	}{
+		"new": {},
+		"existing": {
+			finalizers:         []string{lkeFinalizer},
+			expectedFinalizers: []string{lkeFinalizer},
+		},
+		"deleted": {
+			finalizers:         []string{lkeFinalizer},
+			deletionTimestamp:  mkptr(metav1.Now()),
+			expectedFinalizers: []string{lkeFinalizer},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lke := newTestLKEClusterConfig("foo", "a", nil)
+			lke.Annotations = map[string]string{v1alpha1.PausedAnnotation: "true"}
+			lke.Finalizers = tc.finalizers
+			lke.DeletionTimestamp = tc.deletionTimestamp
+
+			cli := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(lke).
+				Build()
+
+			// no Linode clients, any attempt to reconcile the LKE cluster would panic
+			r := &LKEClusterConfigReconciler{Client: cli, Scheme: scheme}
+
+			res, err := r.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "foo", Name: "a"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !res.IsZero() {
+				t.Errorf("expected empty Result, got: %#+v", res)
+			}
+
+			actual := &v1alpha1.LKEClusterConfig{}
+			if err := cli.Get(context.Background(), client.ObjectKeyFromObject(lke), actual); err != nil {
+				t.Fatalf("failed to get object: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual.Finalizers, tc.expectedFinalizers) {
+				t.Errorf("expected Finalizers value: %#+v, got: %#+v", tc.expectedFinalizers, actual.Finalizers)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"slices"
@@ -26,8 +27,14 @@ import (
 
 	"github.com/anza-labs/lke-operator/api/v1alpha1"
 	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/label"
+	lkefake "github.com/anza-labs/lke-operator/internal/lkeclient/fake"
 	"github.com/linode/linodego"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func Test_extractTags(t *testing.T) {
@@ -65,53 +72,73 @@ func Test_extractTags(t *testing.T) {
 func Test_updateTags(t *testing.T) {
 	t.Parallel()
 
+	meta := v1.ObjectMeta{Namespace: "default", Name: "test", UID: "uid"}
+	uidTag, refTag := "lke-operator.uid=uid", "lke-operator.ref=default/test"
+	instanceTag := "lke-operator.instance=default"
+
 	for name, tc := range map[string]struct {
-		lke          *v1alpha1.LKEClusterConfig
+		annotations  map[string]string
 		cluster      *linodego.LKECluster
 		expectedOpts linodego.LKEClusterUpdateOptions
 	}{
 		"noop": {
-			lke: &v1alpha1.LKEClusterConfig{ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{
-				lkeTagsAnnotation: "foo",
-			}}},
+			annotations: map[string]string{lkeTagsAnnotation: "foo"},
 			cluster: &linodego.LKECluster{
-				Tags: []string{"foo"},
+				Tags: []string{"foo", uidTag, refTag, instanceTag},
 			},
 			expectedOpts: linodego.LKEClusterUpdateOptions{},
 		},
 		"replace": {
-			lke: &v1alpha1.LKEClusterConfig{ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{
-				lkeTagsAnnotation: "foo",
-			}}},
+			annotations: map[string]string{lkeTagsAnnotation: "foo"},
 			cluster: &linodego.LKECluster{
-				Tags: []string{"bar"},
+				Tags: []string{"bar", uidTag, refTag, instanceTag},
 			},
-			expectedOpts: linodego.LKEClusterUpdateOptions{Tags: mkptr([]string{"foo"})},
+			expectedOpts: linodego.LKEClusterUpdateOptions{Tags: mkptr([]string{"foo", instanceTag, refTag, uidTag})},
 		},
 		"replace_multiple": {
-			lke: &v1alpha1.LKEClusterConfig{ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{
-				lkeTagsAnnotation: "foo,bar",
-			}}},
+			annotations: map[string]string{lkeTagsAnnotation: "foo,bar"},
 			cluster: &linodego.LKECluster{
-				Tags: []string{"baz"},
+				Tags: []string{"baz", uidTag, refTag, instanceTag},
 			},
-			expectedOpts: linodego.LKEClusterUpdateOptions{Tags: mkptr([]string{"bar", "foo"})},
+			expectedOpts: linodego.LKEClusterUpdateOptions{Tags: mkptr([]string{"bar", "foo", instanceTag, refTag, uidTag})},
 		},
 		"empty": {
-			lke: &v1alpha1.LKEClusterConfig{ObjectMeta: v1.ObjectMeta{}},
 			cluster: &linodego.LKECluster{
-				Tags: []string{"baz"},
+				Tags: []string{"baz", uidTag, refTag, instanceTag},
 			},
 			expectedOpts: linodego.LKEClusterUpdateOptions{},
 		},
+		"add_ownership": {
+			cluster: &linodego.LKECluster{
+				Tags: []string{"baz"},
+			},
+			expectedOpts: linodego.LKEClusterUpdateOptions{Tags: mkptr([]string{"baz", instanceTag, refTag, uidTag})},
+		},
+		"replace_stale_ownership": {
+			annotations: map[string]string{lkeTagsAnnotation: "foo"},
+			cluster: &linodego.LKECluster{
+				Tags: []string{"foo", "lke-operator.uid=old", refTag, instanceTag},
+			},
+			expectedOpts: linodego.LKEClusterUpdateOptions{Tags: mkptr([]string{"foo", instanceTag, refTag, uidTag})},
+		},
+		"replace_stale_instance": {
+			annotations: map[string]string{lkeTagsAnnotation: "foo"},
+			cluster: &linodego.LKECluster{
+				Tags: []string{"foo", "lke-operator.instance=other", refTag, uidTag},
+			},
+			expectedOpts: linodego.LKEClusterUpdateOptions{Tags: mkptr([]string{"foo", instanceTag, refTag, uidTag})},
+		},
 	} {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
+			lke := &v1alpha1.LKEClusterConfig{ObjectMeta: *meta.DeepCopy()}
+			lke.Annotations = tc.annotations
+
 			opts := linodego.LKEClusterUpdateOptions{}
 
-			opts = updateTags(tc.lke, tc.cluster, opts)
+			opts = updateTags(lke, DefaultInstanceID, tc.cluster, opts)
 
 			if tc.expectedOpts.Tags == nil {
 				if tc.expectedOpts.Tags != opts.Tags {
@@ -413,6 +440,18 @@ func Test_generateNodePoolStatusesFromAPI(t *testing.T) {
 			lkeNP:       []linodego.LKENodePool{},
 			expectedNPS: map[string]v1alpha1.NodePoolStatus{},
 		},
+		"skips_unmanaged": {
+			lkeNP: []linodego.LKENodePool{
+				{ID: 1, Count: 3, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"}},
+				{ID: 2, Count: 1, Type: "g6-standard-2", Tags: []string{"foo"}},
+			},
+			expectedNPS: map[string]v1alpha1.NodePoolStatus{
+				"default": {
+					ID:              mkptr(1),
+					NodePoolDetails: v1alpha1.LKENodePool{NodeCount: 3, LinodeType: "g6-standard-1"},
+				},
+			},
+		},
 	} {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
@@ -465,3 +504,285 @@ func Test_compareNodePoolStatuses(t *testing.T) {
 		})
 	}
 }
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+
+	return *p
+}
+
+// poolCounts returns the node counts of the node pools created by the operator.
+func poolCounts(pools []linodego.LKENodePool) map[string]int {
+	counts := map[string]int{}
+
+	for _, np := range pools {
+		if name, ok := nodePoolName(np); ok {
+			counts[name] = np.Count
+		}
+	}
+
+	return counts
+}
+
+func TestLKEClusterConfigReconciler_onChange(t *testing.T) {
+	t.Parallel()
+
+	spec := map[string]v1alpha1.LKENodePool{
+		"default": {NodeCount: 2, LinodeType: "g6-standard-1"},
+		"extra":   {NodeCount: 1, LinodeType: "g6-standard-2"},
+	}
+
+	for name, tc := range map[string]struct {
+		existing        bool
+		clusterID       *int
+		status          linodego.LKEClusterStatus
+		inject          func(c *lkefake.Client)
+		reconciles      int
+		expectedErr     bool
+		expectedRequeue bool
+		expectedPhase   *v1alpha1.Phase
+		expectedPools   map[string]int
+		expectedCalls   []string
+		expectedSecret  bool
+	}{
+		"create": {
+			expectedRequeue: true,
+			expectedPhase:   mkptr(v1alpha1.PhaseProvisioning),
+			expectedPools:   map[string]int{"default": 2, "extra": 1},
+			expectedCalls:   []string{"ListLKEClusters", "ListLKEVersions", "CreateLKECluster", "ListLKENodePools"},
+		},
+		"recreate_missing": {
+			clusterID:       mkptr(42),
+			expectedRequeue: true,
+			expectedPhase:   mkptr(v1alpha1.PhaseProvisioning),
+			expectedPools:   map[string]int{"default": 2, "extra": 1},
+			expectedCalls:   []string{"GetLKECluster", "ListLKEClusters", "ListLKEVersions", "CreateLKECluster", "ListLKENodePools"},
+		},
+		"recover_lost_status": {
+			existing:       true,
+			expectedPhase:  mkptr(v1alpha1.PhaseActive),
+			expectedPools:  map[string]int{"default": 2, "extra": 1},
+			expectedSecret: true,
+		},
+		"update": {
+			existing:       true,
+			clusterID:      mkptr(1),
+			expectedPhase:  mkptr(v1alpha1.PhaseActive),
+			expectedPools:  map[string]int{"default": 2, "extra": 1},
+			expectedCalls:  []string{"CreateLKENodePool", "UpdateLKENodePool", "GetLKEClusterKubeconfig"},
+			expectedSecret: true,
+		},
+		"not_ready": {
+			existing:        true,
+			clusterID:       mkptr(1),
+			status:          linodego.LKEClusterNotReady,
+			expectedRequeue: true,
+			expectedPhase:   mkptr(v1alpha1.PhaseUpdating),
+			expectedPools:   map[string]int{"default": 2, "extra": 1},
+		},
+		"create_failure": {
+			inject: func(c *lkefake.Client) {
+				c.InjectError("CreateLKECluster", lkefake.Unavailable(), 0)
+			},
+			expectedErr:   true,
+			expectedPools: map[string]int{},
+		},
+		"label_in_use": {
+			inject: func(c *lkefake.Client) {
+				c.AddCluster(linodego.LKECluster{ID: 7, Label: "default-test", Region: "us-east"})
+			},
+			expectedErr:   true,
+			expectedPools: map[string]int{},
+		},
+		"partial_failure": {
+			existing:  true,
+			clusterID: mkptr(1),
+			inject: func(c *lkefake.Client) {
+				c.InjectError("UpdateLKENodePool", lkefake.Unavailable(), 1)
+			},
+			expectedErr:   true,
+			expectedPhase: mkptr(v1alpha1.PhaseUpdating),
+			expectedPools: map[string]int{"default": 1, "extra": 1},
+		},
+		"partial_failure_retried": {
+			existing:  true,
+			clusterID: mkptr(1),
+			inject: func(c *lkefake.Client) {
+				c.InjectError("UpdateLKENodePool", lkefake.Unavailable(), 1)
+			},
+			reconciles:     2,
+			expectedPhase:  mkptr(v1alpha1.PhaseActive),
+			expectedPools:  map[string]int{"default": 2, "extra": 1},
+			expectedSecret: true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: "test", UID: "test-uid"},
+				Spec: v1alpha1.LKEClusterConfigSpec{
+					Region:    "us-east",
+					NodePools: spec,
+				},
+				Status: v1alpha1.LKEClusterConfigStatus{
+					ClusterID: tc.clusterID,
+				},
+			}
+
+			client := lkefake.NewClient()
+			if tc.existing {
+				client.AddCluster(
+					linodego.LKECluster{
+						ID:         1,
+						Label:      "default-test",
+						Region:     "us-east",
+						K8sVersion: "1.30",
+						Status:     tc.status,
+						Tags:       ownershipTags(obj, DefaultInstanceID),
+					},
+					linodego.LKENodePool{Count: 1, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"}},
+				)
+			}
+
+			if tc.inject != nil {
+				tc.inject(client)
+			}
+
+			kubernetesClient := kubefake.NewSimpleClientset()
+
+			r := &LKEClusterConfigReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme(t)).
+					WithObjects(obj).
+					Build(),
+				KubernetesClient: kubernetesClient,
+				Recorder:         record.NewFakeRecorder(100),
+			}
+
+			reconciles := max(tc.reconciles, 1)
+
+			var (
+				res ctrl.Result
+				err error
+			)
+
+			for range reconciles {
+				res, err = r.onChange(context.Background(), client, obj)
+			}
+
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %#+v, got: %#+v", tc.expectedErr, err)
+			}
+
+			if requeue := !res.IsZero(); requeue != tc.expectedRequeue {
+				t.Errorf("expected Requeue value: %#+v, got: %#+v", tc.expectedRequeue, requeue)
+			}
+
+			if phase, expected := deref(obj.Status.Phase), deref(tc.expectedPhase); phase != expected {
+				t.Errorf("expected Phase value: %#+v, got: %#+v", expected, phase)
+			}
+
+			var pools []linodego.LKENodePool
+			if obj.Status.ClusterID != nil {
+				pools = client.NodePools(*obj.Status.ClusterID)
+			}
+
+			if counts := poolCounts(pools); !reflect.DeepEqual(counts, tc.expectedPools) {
+				t.Errorf("expected Pools value: %#+v, got: %#+v", tc.expectedPools, counts)
+			}
+
+			methods := client.Methods()
+			for _, method := range tc.expectedCalls {
+				if !slices.Contains(methods, method) {
+					t.Errorf("expected call to %s, got: %#+v", method, methods)
+				}
+			}
+
+			_, err = kubernetesClient.CoreV1().Secrets("default").
+				Get(context.Background(), kubeconfigSecretName(obj), v1.GetOptions{})
+			if exists := err == nil; exists != tc.expectedSecret {
+				t.Errorf("expected kubeconfig Secret: %#+v, got: %#+v", tc.expectedSecret, err)
+			}
+		})
+	}
+}
+
+func TestLKEClusterConfigReconciler_onChange_upgradeLabel(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		template      string
+		expectedErr   bool
+		expectedLabel string
+	}{
+		"keep_existing": {
+			expectedLabel: "production-cluster",
+		},
+		"configured_template": {
+			template:      "{{ .Name }}-lke",
+			expectedLabel: "production-cluster-lke",
+		},
+		"configured_template_too_long": {
+			template:      label.DefaultTemplate,
+			expectedErr:   true,
+			expectedLabel: "production-cluster",
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// created before the default label included the namespace, which
+			// makes the default label longer than allowed
+			obj := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "platform-engineering-team",
+					Name:      "production-cluster",
+					UID:       "test-uid",
+				},
+				Spec: v1alpha1.LKEClusterConfigSpec{
+					Region:    "us-east",
+					NodePools: map[string]v1alpha1.LKENodePool{"default": {NodeCount: 1, LinodeType: "g6-standard-1"}},
+				},
+				Status: v1alpha1.LKEClusterConfigStatus{ClusterID: mkptr(1)},
+			}
+
+			client := lkefake.NewClient()
+			client.AddCluster(
+				linodego.LKECluster{
+					ID:         1,
+					Label:      "production-cluster",
+					Region:     "us-east",
+					K8sVersion: "1.30",
+					Tags:       ownershipTags(obj, DefaultInstanceID),
+				},
+				linodego.LKENodePool{Count: 1, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"}},
+			)
+
+			r := &LKEClusterConfigReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme(t)).
+					WithObjects(obj).
+					Build(),
+				KubernetesClient: kubefake.NewSimpleClientset(),
+				Recorder:         record.NewFakeRecorder(100),
+				Labels:           label.Template{Default: tc.template},
+			}
+
+			_, err := r.onChange(context.Background(), client, obj)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %#+v, got: %#+v", tc.expectedErr, err)
+			}
+
+			cluster, _ := client.Cluster(1)
+			if cluster.Label != tc.expectedLabel {
+				t.Errorf("expected Label value: %#+v, got: %#+v", tc.expectedLabel, cluster.Label)
+			}
+		})
+	}
+}
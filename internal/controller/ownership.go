@@ -0,0 +1,228 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/linode/linodego"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+// DefaultInstanceID is the default ID of the operator instance, used in the
+// ownership tags of the LKE clusters.
+const DefaultInstanceID = "default"
+
+const (
+	ownerUIDTagPrefix      = "lke-operator.uid="
+	ownerRefTagPrefix      = "lke-operator.ref="
+	ownerInstanceTagPrefix = "lke-operator.instance="
+
+	// maxTagLength is the maximum length of the tag accepted by the Linode API.
+	maxTagLength = 50
+)
+
+// ownerUIDTag returns the tag identifying the LKE cluster created for the object.
+// Dashes are stripped from the UID to fit in the maximum tag length.
+func ownerUIDTag(lke *v1alpha1.LKEClusterConfig) string {
+	return ownerUIDTagPrefix + strings.ReplaceAll(string(lke.UID), "-", "")
+}
+
+// ownerRefTag returns the tag identifying the LKE cluster created for the object
+// with the namespace and name. The namespace and name are hashed if the tag would
+// exceed the maximum tag length.
+func ownerRefTag(lke *v1alpha1.LKEClusterConfig) string {
+	ref := lke.Namespace + "/" + lke.Name
+
+	if tag := ownerRefTagPrefix + ref; len(tag) <= maxTagLength {
+		return tag
+	}
+
+	sum := sha256.Sum256([]byte(ref))
+
+	return ownerRefTagPrefix + hex.EncodeToString(sum[:])[:maxTagLength-len(ownerRefTagPrefix)]
+}
+
+// ownerInstanceTag returns the tag identifying the operator instance managing
+// the LKE cluster, so that operators sharing the account leave each other's
+// clusters alone.
+func ownerInstanceTag(instance string) string {
+	return ownerInstanceTagPrefix + instance
+}
+
+// ValidateInstanceID checks if the ID of the operator instance can be used in the
+// ownership tags.
+func ValidateInstanceID(instance string) error {
+	if instance == "" {
+		return errors.New("instance ID must not be empty")
+	}
+
+	if max := maxTagLength - len(ownerInstanceTagPrefix); len(instance) > max {
+		return fmt.Errorf("instance ID must be at most %d characters long", max)
+	}
+
+	return nil
+}
+
+// ownershipTags returns the tags identifying the LKE cluster created for the object
+// by the operator instance.
+func ownershipTags(lke *v1alpha1.LKEClusterConfig, instance string) []string {
+	return []string{ownerUIDTag(lke), ownerRefTag(lke), ownerInstanceTag(instance)}
+}
+
+func isOwnershipTag(tag string) bool {
+	return strings.HasPrefix(tag, ownerUIDTagPrefix) ||
+		strings.HasPrefix(tag, ownerRefTagPrefix) ||
+		strings.HasPrefix(tag, ownerInstanceTagPrefix)
+}
+
+// userTags returns the tags without the ownership tags.
+func userTags(tags []string) []string {
+	return slices.DeleteFunc(slices.Clone(tags), isOwnershipTag)
+}
+
+// ownedTags returns the sorted tags extended with the current ownership tags of
+// the object. Stale ownership tags, e.g. with the UID of the object restored from
+// a backup, are replaced.
+func ownedTags(lke *v1alpha1.LKEClusterConfig, instance string, tags []string) []string {
+	owned := append(userTags(tags), ownershipTags(lke, instance)...)
+
+	slices.Sort(owned)
+
+	return slices.Compact(owned)
+}
+
+// findCluster looks up the LKE cluster created for the object using the ownership
+// tags. Cluster tagged with the UID of the object is preferred over the one tagged
+// only with its namespace and name, which is the case after restoring the object
+// from a backup. The latter must be tagged with the operator instance, as objects
+// of other instances may share the namespace and name. Nil is returned if there
+// is no such cluster.
+func findCluster(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+	instance string,
+) (*linodego.LKECluster, error) {
+	uidTag, refTag, instanceTag := ownerUIDTag(lke), ownerRefTag(lke), ownerInstanceTag(instance)
+
+	filter, err := linodego.Or("", "",
+		&linodego.Comp{Column: "tags", Operator: linodego.Eq, Value: uidTag},
+		&linodego.Comp{Column: "tags", Operator: linodego.Eq, Value: refTag},
+	).MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build filter: %w", err)
+	}
+
+	clusters, err := client.ListLKEClusters(ctx, &linodego.ListOptions{Filter: string(filter)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	// prefer the oldest cluster, if duplicates were already created
+	slices.SortFunc(clusters, func(a, b linodego.LKECluster) int {
+		return a.ID - b.ID
+	})
+
+	var byRef *linodego.LKECluster
+
+	for i := range clusters {
+		switch {
+		case slices.Contains(clusters[i].Tags, uidTag):
+			return &clusters[i], nil
+		case byRef == nil && slices.Contains(clusters[i].Tags, refTag) &&
+			slices.Contains(clusters[i].Tags, instanceTag):
+			byRef = &clusters[i]
+		}
+	}
+
+	return byRef, nil
+}
+
+// onChangeRecover rebuilds the status of the object from the existing LKE cluster,
+// and continues with updating it.
+func (r *LKEClusterConfigReconciler) onChangeRecover(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+	cluster *linodego.LKECluster,
+) (ctrl.Result, error) {
+	nps, err := client.ListLKENodePools(ctx, cluster.ID, &linodego.ListOptions{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list node pools: %w", err)
+	}
+
+	if lke.Status.Phase == nil {
+		lke.Status.Phase = mkptr(v1alpha1.PhaseProvisioning)
+	}
+
+	lke.Status.ClusterID = mkptr(cluster.ID)
+	lke.Status.NodePoolStatuses = generateNodePoolStatusesFromAPI(nps)
+
+	if err := r.Update(ctx, lke); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	r.event(lke, corev1.EventTypeNormal, "ClusterRecovered",
+		fmt.Sprintf("found existing LKE cluster %d", cluster.ID))
+
+	return r.onChangeUpdate(ctx, client, lke, cluster)
+}
+
+func (r *LKEClusterConfigReconciler) instanceID() string {
+	if r.InstanceID != "" {
+		return r.InstanceID
+	}
+
+	return DefaultInstanceID
+}
+
+// ensureOwnershipTags tags the LKE cluster with the current ownership tags of the
+// object, preserving all other tags.
+func ensureOwnershipTags(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+	instance string,
+	cluster *linodego.LKECluster,
+) error {
+	live := slices.Clone(cluster.Tags)
+	slices.Sort(live)
+
+	tags := ownedTags(lke, instance, live)
+	if slices.Equal(tags, live) {
+		return nil
+	}
+
+	if _, err := client.UpdateLKECluster(ctx, cluster.ID, linodego.LKEClusterUpdateOptions{
+		Tags: &tags,
+	}); err != nil {
+		return fmt.Errorf("failed to update ownership tags: %w", err)
+	}
+
+	return nil
+}
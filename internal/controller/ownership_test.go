@@ -0,0 +1,183 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/linode/linodego"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+type clustersClient struct {
+	lkeclient.Client
+
+	clusters []linodego.LKECluster
+}
+
+func (c *clustersClient) ListLKEClusters(context.Context, *linodego.ListOptions) ([]linodego.LKECluster, error) {
+	return c.clusters, nil
+}
+
+func Test_ownershipTags(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		meta         v1.ObjectMeta
+		expectedTags []string
+	}{
+		"short": {
+			meta: v1.ObjectMeta{
+				Namespace: "default",
+				Name:      "test",
+				UID:       "6f1c6b1e-9d1c-4a8e-8d0e-3c1f1a2b3c4d",
+			},
+			expectedTags: []string{
+				"lke-operator.uid=6f1c6b1e9d1c4a8e8d0e3c1f1a2b3c4d",
+				"lke-operator.ref=default/test",
+				"lke-operator.instance=default",
+			},
+		},
+		"long": {
+			meta: v1.ObjectMeta{
+				Namespace: "production",
+				Name:      "very-long-name-of-the-cluster",
+				UID:       "6f1c6b1e-9d1c-4a8e-8d0e-3c1f1a2b3c4d",
+			},
+			expectedTags: []string{
+				"lke-operator.uid=6f1c6b1e9d1c4a8e8d0e3c1f1a2b3c4d",
+				"lke-operator.ref=6b87528790789d81445088b93e45a3d7e",
+				"lke-operator.instance=default",
+			},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tags := ownershipTags(&v1alpha1.LKEClusterConfig{ObjectMeta: tc.meta}, DefaultInstanceID)
+
+			for i, tag := range tags {
+				if len(tag) > maxTagLength {
+					t.Errorf("expected Tag length at most: %d, got: %d", maxTagLength, len(tag))
+				}
+
+				if tag != tc.expectedTags[i] {
+					t.Errorf("expected Tag value: %#+v, got: %#+v", tc.expectedTags[i], tag)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateInstanceID(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		instance    string
+		expectedErr bool
+	}{
+		"valid":    {instance: "production"},
+		"empty":    {expectedErr: true},
+		"too_long": {instance: "very-long-id-of-the-operator-instance", expectedErr: true},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateInstanceID(tc.instance)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func Test_findCluster(t *testing.T) {
+	t.Parallel()
+
+	lke := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: "test", UID: "uid"},
+	}
+	uidTag, refTag, instanceTag := ownerUIDTag(lke), ownerRefTag(lke), ownerInstanceTag(DefaultInstanceID)
+
+	for name, tc := range map[string]struct {
+		clusters   []linodego.LKECluster
+		expectedID int
+	}{
+		"not_found": {
+			clusters:   []linodego.LKECluster{{ID: 1, Tags: []string{"foo"}}},
+			expectedID: 0,
+		},
+		"by_uid": {
+			clusters: []linodego.LKECluster{
+				{ID: 1, Tags: []string{"lke-operator.uid=old", refTag, instanceTag}},
+				{ID: 2, Tags: []string{uidTag, refTag, instanceTag}},
+			},
+			expectedID: 2,
+		},
+		"by_ref": {
+			clusters: []linodego.LKECluster{
+				{ID: 1, Tags: []string{"lke-operator.uid=old", refTag, instanceTag}},
+			},
+			expectedID: 1,
+		},
+		"by_ref_other_instance": {
+			clusters: []linodego.LKECluster{
+				{ID: 1, Tags: []string{"lke-operator.uid=other", refTag, ownerInstanceTag("other")}},
+			},
+			expectedID: 0,
+		},
+		"by_ref_both_instances": {
+			clusters: []linodego.LKECluster{
+				{ID: 1, Tags: []string{"lke-operator.uid=other", refTag, ownerInstanceTag("other")}},
+				{ID: 2, Tags: []string{"lke-operator.uid=old", refTag, instanceTag}},
+			},
+			expectedID: 2,
+		},
+		"oldest_duplicate": {
+			clusters: []linodego.LKECluster{
+				{ID: 3, Tags: []string{uidTag, refTag}},
+				{ID: 2, Tags: []string{uidTag, refTag}},
+			},
+			expectedID: 2,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cluster, err := findCluster(context.Background(), &clustersClient{clusters: tc.clusters}, lke, DefaultInstanceID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			id := 0
+			if cluster != nil {
+				id = cluster.ID
+			}
+
+			if id != tc.expectedID {
+				t.Errorf("expected ClusterID value: %#+v, got: %#+v", tc.expectedID, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/linode/linodego"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+// lkeReplacementTag tags the node pool replacing the one with the same name and
+// a different Linode type. The replacement is tagged with the name only once the
+// replaced node pool is deleted, so that no two node pools share the name.
+const lkeReplacementTag = "lke-operator.replaces="
+
+// replacementName returns the name of the node pool replaced by the node pool.
+// False is returned if the node pool is not a replacement.
+func replacementName(np linodego.LKENodePool) (string, bool) {
+	for _, tag := range np.Tags {
+		if name, ok := strings.CutPrefix(tag, lkeReplacementTag); ok && name != "" {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// replaceNodePool recreates the node pool with the changed Linode type, as the type
+// cannot be updated in place. The replacement is created before the replaced node
+// pool is deleted, to keep the capacity of the node pool.
+func replaceNodePool(
+	ctx context.Context,
+	client lkeclient.Client,
+	cluster *linodego.LKECluster,
+	name string,
+	status v1alpha1.NodePoolStatus,
+	replacedID int,
+) error {
+	opts := nodePoolCreateOptions(name, status.NodePoolDetails)
+	opts.Tags = []string{lkeReplacementTag + name}
+
+	np, err := client.CreateLKENodePool(ctx, cluster.ID, opts)
+	if err != nil {
+		return fmt.Errorf("failed to create replacement node pool: %w", err)
+	}
+
+	if err := deleteNodePool(ctx, client, cluster, replacedID); err != nil {
+		return fmt.Errorf("failed to delete replaced node pool: %w", err)
+	}
+
+	return promoteReplacement(ctx, client, cluster, *np, name)
+}
+
+// promoteReplacement tags the replacement with the name of the replaced node pool.
+func promoteReplacement(
+	ctx context.Context,
+	client lkeclient.Client,
+	cluster *linodego.LKECluster,
+	np linodego.LKENodePool,
+	name string,
+) error {
+	tags := slices.DeleteFunc(slices.Clone(np.Tags), func(tag string) bool {
+		return strings.HasPrefix(tag, lkeReplacementTag)
+	})
+	tags = append(tags, lkeOperatorTag+name)
+
+	if _, err := client.UpdateLKENodePool(ctx, cluster.ID, np.ID, linodego.LKENodePoolUpdateOptions{
+		Tags: &tags,
+	}); err != nil {
+		return fmt.Errorf("failed to tag replacement node pool %d: %w", np.ID, err)
+	}
+
+	return nil
+}
+
+func deleteNodePool(ctx context.Context, client lkeclient.Client, cluster *linodego.LKECluster, id int) error {
+	if err := client.DeleteLKENodePool(ctx, cluster.ID, id); err != nil &&
+		!errors.Is(err, internalerrors.ErrLinodeNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// reconcileReplacements completes the replacements of node pools interrupted before
+// the replaced node pool was deleted, and deletes the replacements no longer matching
+// the spec. Node pools sharing the name, e.g. left by the replacements made before
+// the replacement tag was introduced, are deleted except for the one matching the
+// spec. It returns the node pools of the cluster after the changes.
+func reconcileReplacements(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+	cluster *linodego.LKECluster,
+	nps []linodego.LKENodePool,
+) ([]linodego.LKENodePool, error) {
+	nps = slices.Clone(nps)
+	slices.SortFunc(nps, func(a, b linodego.LKENodePool) int { return a.ID - b.ID })
+
+	named := map[string][]linodego.LKENodePool{}
+
+	for _, np := range nps {
+		if name, ok := nodePoolName(np); ok {
+			named[name] = append(named[name], np)
+		}
+	}
+
+	changed := false
+
+	for _, np := range nps {
+		name, ok := replacementName(np)
+		if !ok {
+			continue
+		}
+
+		changed = true
+
+		if spec, ok := lke.Spec.NodePools[name]; !ok || spec.LinodeType != np.Type {
+			if err := deleteNodePool(ctx, client, cluster, np.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete stale replacement node pool %d: %w", np.ID, err)
+			}
+
+			continue
+		}
+
+		for _, replaced := range named[name] {
+			if err := deleteNodePool(ctx, client, cluster, replaced.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete replaced node pool %d: %w", replaced.ID, err)
+			}
+		}
+
+		if err := promoteReplacement(ctx, client, cluster, np, name); err != nil {
+			return nil, err
+		}
+
+		named[name] = nil
+	}
+
+	for name, duplicates := range named {
+		if len(duplicates) < 2 {
+			continue
+		}
+
+		changed = true
+
+		// keep the oldest node pool matching the spec, or the oldest one
+		keep := slices.IndexFunc(duplicates, func(np linodego.LKENodePool) bool {
+			return np.Type == lke.Spec.NodePools[name].LinodeType
+		})
+		keep = max(keep, 0)
+
+		for i, np := range duplicates {
+			if i == keep {
+				continue
+			}
+
+			if err := deleteNodePool(ctx, client, cluster, np.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete duplicate node pool %d: %w", np.ID, err)
+			}
+		}
+	}
+
+	if !changed {
+		return nps, nil
+	}
+
+	nps, err := client.ListLKENodePools(ctx, cluster.ID, &linodego.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node pools: %w", err)
+	}
+
+	return nps, nil
+}
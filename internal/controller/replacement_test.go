@@ -0,0 +1,198 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/linode/linodego"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	lkefake "github.com/anza-labs/lke-operator/internal/lkeclient/fake"
+)
+
+// poolTypes returns the Linode types of the node pools by their name, with the
+// replacements keyed by the replacement tag.
+func poolTypes(pools []linodego.LKENodePool) map[string][]string {
+	types := map[string][]string{}
+
+	for _, np := range pools {
+		if name, ok := nodePoolName(np); ok {
+			types[name] = append(types[name], np.Type)
+		}
+
+		if name, ok := replacementName(np); ok {
+			types[lkeReplacementTag+name] = append(types[lkeReplacementTag+name], np.Type)
+		}
+	}
+
+	return types
+}
+
+func Test_updateNodePools_replace(t *testing.T) {
+	t.Parallel()
+
+	lke := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: v1alpha1.LKEClusterConfigSpec{
+			NodePools: map[string]v1alpha1.LKENodePool{"default": {NodeCount: 1, LinodeType: "g6-standard-2"}},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		inject        func(c *lkefake.Client)
+		expectedErr   bool
+		expectedTypes map[string][]string
+	}{
+		"replace": {
+			expectedTypes: map[string][]string{"default": {"g6-standard-2"}},
+		},
+		"delete_failure": {
+			inject: func(c *lkefake.Client) {
+				c.InjectError("DeleteLKENodePool", lkefake.Unavailable(), 1)
+			},
+			expectedErr: true,
+			expectedTypes: map[string][]string{
+				"default":                     {"g6-standard-1"},
+				lkeReplacementTag + "default": {"g6-standard-2"},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := lkefake.NewClient()
+			id := client.AddCluster(linodego.LKECluster{Region: "us-east"},
+				linodego.LKENodePool{Count: 1, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"}},
+			)
+			cluster, _ := client.Cluster(id)
+
+			if tc.inject != nil {
+				tc.inject(client)
+			}
+
+			err := updateNodePools(context.Background(), client, &cluster,
+				generateNodePoolStatusesFromSpec(lke.Spec.NodePools),
+				generateNodePoolStatusesFromAPI(client.NodePools(id)),
+			)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %#+v, got: %#+v", tc.expectedErr, err)
+			}
+
+			if types := poolTypes(client.NodePools(id)); !reflect.DeepEqual(types, tc.expectedTypes) {
+				t.Errorf("expected Types value: %#+v, got: %#+v", tc.expectedTypes, types)
+			}
+
+			// the next reconciliation completes the interrupted replacement
+			pools, err := reconcileReplacements(context.Background(), client, lke, &cluster, client.NodePools(id))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := map[string][]string{"default": {"g6-standard-2"}}
+			if types := poolTypes(pools); !reflect.DeepEqual(types, expected) {
+				t.Errorf("expected Types value: %#+v, got: %#+v", expected, types)
+			}
+		})
+	}
+}
+
+func Test_reconcileReplacements(t *testing.T) {
+	t.Parallel()
+
+	lke := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: v1alpha1.LKEClusterConfigSpec{
+			NodePools: map[string]v1alpha1.LKENodePool{"default": {NodeCount: 1, LinodeType: "g6-standard-2"}},
+		},
+	}
+
+	named := func(name, linodeType string) linodego.LKENodePool {
+		return linodego.LKENodePool{Count: 1, Type: linodeType, Tags: []string{lkeOperatorTag + name}}
+	}
+
+	replacement := func(name, linodeType string) linodego.LKENodePool {
+		return linodego.LKENodePool{Count: 1, Type: linodeType, Tags: []string{lkeReplacementTag + name}}
+	}
+
+	for name, tc := range map[string]struct {
+		pools         []linodego.LKENodePool
+		expectedTypes map[string][]string
+		expectedCalls int
+	}{
+		"nothing_to_do": {
+			pools:         []linodego.LKENodePool{named("default", "g6-standard-2")},
+			expectedTypes: map[string][]string{"default": {"g6-standard-2"}},
+		},
+		"interrupted": {
+			pools:         []linodego.LKENodePool{named("default", "g6-standard-1"), replacement("default", "g6-standard-2")},
+			expectedTypes: map[string][]string{"default": {"g6-standard-2"}},
+			expectedCalls: 3,
+		},
+		"replaced_already_deleted": {
+			pools:         []linodego.LKENodePool{replacement("default", "g6-standard-2")},
+			expectedTypes: map[string][]string{"default": {"g6-standard-2"}},
+			expectedCalls: 2,
+		},
+		"stale_replacement": {
+			pools:         []linodego.LKENodePool{named("default", "g6-standard-1"), replacement("default", "g6-standard-4")},
+			expectedTypes: map[string][]string{"default": {"g6-standard-1"}},
+			expectedCalls: 2,
+		},
+		"removed_from_spec": {
+			pools:         []linodego.LKENodePool{named("default", "g6-standard-2"), replacement("gone", "g6-standard-2")},
+			expectedTypes: map[string][]string{"default": {"g6-standard-2"}},
+			expectedCalls: 2,
+		},
+		"duplicates": {
+			pools:         []linodego.LKENodePool{named("default", "g6-standard-1"), named("default", "g6-standard-2")},
+			expectedTypes: map[string][]string{"default": {"g6-standard-2"}},
+			expectedCalls: 2,
+		},
+		"duplicates_not_matching": {
+			pools:         []linodego.LKENodePool{named("default", "g6-standard-1"), named("default", "g6-standard-4")},
+			expectedTypes: map[string][]string{"default": {"g6-standard-1"}},
+			expectedCalls: 2,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := lkefake.NewClient()
+			id := client.AddCluster(linodego.LKECluster{Region: "us-east"}, tc.pools...)
+			cluster, _ := client.Cluster(id)
+
+			pools, err := reconcileReplacements(context.Background(), client, lke, &cluster, client.NodePools(id))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if types := poolTypes(pools); !reflect.DeepEqual(types, tc.expectedTypes) {
+				t.Errorf("expected Types value: %#+v, got: %#+v", tc.expectedTypes, types)
+			}
+
+			if calls := len(client.Calls()); calls != tc.expectedCalls {
+				t.Errorf("expected Calls value: %#+v, got: %#+v", tc.expectedCalls, client.Methods())
+			}
+		})
+	}
+}
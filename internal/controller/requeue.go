@@ -0,0 +1,118 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+// RequeueIntervals are the intervals after which the LKEClusterConfig in the
+// given phase is reconciled again. Zero Resync disables periodic reconciliation
+// of active clusters, other zero intervals requeue using the rate limiter.
+// Intervals overridden by the object spec are raised to the Minimum, as they
+// share the rate limit of the token with other objects.
+type RequeueIntervals struct {
+	Provisioning time.Duration
+	Updating     time.Duration
+	Deleting     time.Duration
+	Resync       time.Duration
+	Minimum      time.Duration
+}
+
+// DefaultRequeueIntervals returns the default RequeueIntervals.
+func DefaultRequeueIntervals() RequeueIntervals {
+	return RequeueIntervals{
+		Provisioning: 30 * time.Second,
+		Updating:     30 * time.Second,
+		Deleting:     15 * time.Second,
+		Resync:       10 * time.Minute,
+		Minimum:      5 * time.Second,
+	}
+}
+
+// For returns the interval for the phase, respecting the override from the
+// object spec.
+func (i RequeueIntervals) For(phase v1alpha1.Phase, override *v1alpha1.RequeueIntervals) time.Duration {
+	if override == nil {
+		override = &v1alpha1.RequeueIntervals{}
+	}
+
+	var (
+		interval time.Duration
+		custom   *metav1.Duration
+	)
+
+	switch phase {
+	case v1alpha1.PhaseProvisioning:
+		interval, custom = i.Provisioning, override.Provisioning
+	case v1alpha1.PhaseUpdating:
+		interval, custom = i.Updating, override.Updating
+	case v1alpha1.PhaseDeleting:
+		interval, custom = i.Deleting, override.Deleting
+	case v1alpha1.PhaseActive:
+		interval, custom = i.Resync, override.Resync
+	}
+
+	if custom != nil {
+		return max(custom.Duration, i.Minimum)
+	}
+
+	return interval
+}
+
+// requeue returns the result reconciling the LKEClusterConfig again after the
+// interval for the phase.
+func (r *LKEClusterConfigReconciler) requeue(lke *v1alpha1.LKEClusterConfig, phase v1alpha1.Phase) ctrl.Result {
+	after := r.RequeueIntervals.For(phase, lke.Spec.RequeueIntervals)
+
+	switch {
+	case after > 0:
+		return ctrl.Result{RequeueAfter: after}
+	case phase == v1alpha1.PhaseActive:
+		return ctrl.Result{}
+	default:
+		return ctrl.Result{Requeue: true}
+	}
+}
+
+// requeueNotReady returns the result for the LKEClusterConfig waiting for the
+// LKE cluster to become ready. Active clusters are polled as updating ones.
+func (r *LKEClusterConfigReconciler) requeueNotReady(lke *v1alpha1.LKEClusterConfig) ctrl.Result {
+	phase := v1alpha1.PhaseUpdating
+	if lke.Status.Phase != nil && *lke.Status.Phase == v1alpha1.PhaseProvisioning {
+		phase = v1alpha1.PhaseProvisioning
+	}
+
+	return r.requeue(lke, phase)
+}
+
+// NewRateLimiter returns the workqueue rate limiter, retrying failed items
+// with exponential backoff between baseDelay and maxDelay, and limiting the
+// overall rate of retries to qps with the burst.
+func NewRateLimiter(baseDelay, maxDelay time.Duration, qps float64, burst int) workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
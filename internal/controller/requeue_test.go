@@ -0,0 +1,111 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestLKEClusterConfigReconciler_requeue(t *testing.T) {
+	t.Parallel()
+
+	r := &LKEClusterConfigReconciler{
+		RequeueIntervals: RequeueIntervals{
+			Provisioning: time.Minute,
+			Deleting:     time.Second,
+			Minimum:      5 * time.Second,
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		override       *v1alpha1.RequeueIntervals
+		phase          v1alpha1.Phase
+		expectedResult ctrl.Result
+	}{
+		"provisioning": {
+			phase:          v1alpha1.PhaseProvisioning,
+			expectedResult: ctrl.Result{RequeueAfter: time.Minute},
+		},
+		"deleting": {
+			phase:          v1alpha1.PhaseDeleting,
+			expectedResult: ctrl.Result{RequeueAfter: time.Second},
+		},
+		"updating_unset": {
+			phase:          v1alpha1.PhaseUpdating,
+			expectedResult: ctrl.Result{Requeue: true},
+		},
+		"active_unset": {
+			phase:          v1alpha1.PhaseActive,
+			expectedResult: ctrl.Result{},
+		},
+		"override": {
+			override: &v1alpha1.RequeueIntervals{
+				Provisioning: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			phase:          v1alpha1.PhaseProvisioning,
+			expectedResult: ctrl.Result{RequeueAfter: 5 * time.Minute},
+		},
+		"override_other_phase": {
+			override: &v1alpha1.RequeueIntervals{
+				Resync: &metav1.Duration{Duration: time.Hour},
+			},
+			phase:          v1alpha1.PhaseDeleting,
+			expectedResult: ctrl.Result{RequeueAfter: time.Second},
+		},
+		"override_below_minimum": {
+			override: &v1alpha1.RequeueIntervals{
+				Updating: &metav1.Duration{Duration: time.Millisecond},
+			},
+			phase:          v1alpha1.PhaseUpdating,
+			expectedResult: ctrl.Result{RequeueAfter: 5 * time.Second},
+		},
+		"override_zero": {
+			override: &v1alpha1.RequeueIntervals{
+				Resync: &metav1.Duration{},
+			},
+			phase:          v1alpha1.PhaseActive,
+			expectedResult: ctrl.Result{RequeueAfter: 5 * time.Second},
+		},
+		"override_resync": {
+			override: &v1alpha1.RequeueIntervals{
+				Resync: &metav1.Duration{Duration: time.Hour},
+			},
+			phase:          v1alpha1.PhaseActive,
+			expectedResult: ctrl.Result{RequeueAfter: time.Hour},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lke := &v1alpha1.LKEClusterConfig{
+				Spec: v1alpha1.LKEClusterConfigSpec{RequeueIntervals: tc.override},
+			}
+
+			res := r.requeue(lke, tc.phase)
+			if res != tc.expectedResult {
+				t.Errorf("expected Result value: %#+v, got: %#+v",
+					tc.expectedResult, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/linode/linodego"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/credentials"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	lkefake "github.com/anza-labs/lke-operator/internal/lkeclient/fake"
+)
+
+const (
+	// simMaxSteps limits the steps of the single simulation.
+	simMaxSteps = 200
+
+	// simConvergeReconciles is the number of reconciles within which the object
+	// must converge to the spec once the failures stop.
+	simConvergeReconciles = 10
+
+	// simRuns is the number of random simulations run by the unit test.
+	simRuns = 100
+)
+
+// simPoolTypes are the node pools the simulation may add to the spec. The type
+// of each pool is fixed, so the node pools are never replaced.
+var simPoolTypes = map[string]string{
+	"default": "g6-standard-1",
+	"workers": "g6-standard-2",
+	"batch":   "g6-standard-4",
+	"gpu":     "g1-gpu-rtx6000-1",
+}
+
+// simPoolNames are the names of simPoolTypes, in the order of the choices.
+var simPoolNames = []string{"batch", "default", "gpu", "workers"}
+
+// simMethods are the Linode API methods the simulation injects errors into.
+var simMethods = []string{
+	"ListLKEVersions",
+	"ListLKEClusters",
+	"GetLKECluster",
+	"CreateLKECluster",
+	"UpdateLKECluster",
+	"GetLKEClusterKubeconfig",
+	"ListLKENodePools",
+	"CreateLKENodePool",
+	"UpdateLKENodePool",
+	"DeleteLKENodePool",
+}
+
+// simErrors are the retryable errors returned by the Linode API.
+var simErrors = []error{
+	lkefake.Unavailable(),
+	&linodego.Error{Code: http.StatusInternalServerError, Message: "Internal server error"},
+	&linodego.Error{Code: http.StatusBadGateway, Message: "Bad gateway"},
+}
+
+// choices draws the decisions of the simulation from the input, so the same input
+// always replays the same simulation. Exhausted input always chooses 0.
+type choices struct {
+	data []byte
+}
+
+func (c *choices) next(n int) int {
+	if len(c.data) == 0 || n <= 1 {
+		return 0
+	}
+
+	v := int(c.data[0]) % n
+	c.data = c.data[1:]
+
+	return v
+}
+
+func (c *choices) done() bool {
+	return len(c.data) == 0
+}
+
+// simulation drives Reconcile of the single object against the fake Linode
+// backend, while mutating the spec and injecting failures, and checks the
+// invariants after each step.
+type simulation struct {
+	t       *testing.T
+	choices *choices
+	log     []string
+
+	backend *lkefake.Client
+	kube    client.WithWatch
+	r       *LKEClusterConfigReconciler
+	key     types.NamespacedName
+
+	// spec is the current spec, as seen by the reactor checking the deletions.
+	spec v1alpha1.LKEClusterConfigSpec
+
+	// failedCalls are the errors returned by the next calls to the methods.
+	failedCalls map[string][]error
+
+	// failedUpdates is the number of the next object updates which fail.
+	failedUpdates int
+
+	violations []string
+}
+
+func newSimulation(t *testing.T, data []byte) *simulation {
+	t.Helper()
+
+	obj := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "sim",
+			UID:        "sim-uid",
+			Generation: 1,
+		},
+		Spec: v1alpha1.LKEClusterConfigSpec{
+			Region: "us-east",
+			NodePools: map[string]v1alpha1.LKENodePool{
+				"default": {NodeCount: 1, LinodeType: simPoolTypes["default"]},
+			},
+		},
+	}
+
+	sim := &simulation{
+		t:           t,
+		choices:     &choices{data: data},
+		backend:     lkefake.NewClient(),
+		kube:        fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(obj).Build(),
+		key:         client.ObjectKeyFromObject(obj),
+		spec:        *obj.Spec.DeepCopy(),
+		failedCalls: map[string][]error{},
+	}
+
+	sim.backend.PrependReactor(lkefake.AnyMethod, sim.react)
+
+	sim.r = &LKEClusterConfigReconciler{
+		Client: interceptor.NewClient(sim.kube, interceptor.Funcs{
+			Update: sim.update,
+		}),
+		KubernetesClient:   kubefake.NewSimpleClientset(),
+		DefaultCredentials: credentials.Static("token"),
+		Recorder:           &record.FakeRecorder{},
+		newLinodeClient: func(*credentials.Credential) lkeclient.Client {
+			return sim.backend
+		},
+	}
+
+	return sim
+}
+
+// react fails the calls with the injected errors, and records the deletion of
+// node pools still present in the spec.
+func (s *simulation) react(call lkefake.Call) (bool, any, error) {
+	if errs := s.failedCalls[call.Method]; len(errs) > 0 {
+		s.failedCalls[call.Method] = errs[1:]
+		return true, nil, errs[0]
+	}
+
+	if call.Method == "DeleteLKENodePool" {
+		clusterID, poolID := call.Args[0].(int), call.Args[1].(int)
+		pools := s.backend.NodePools(clusterID)
+
+		i := slices.IndexFunc(pools, func(np linodego.LKENodePool) bool { return np.ID == poolID })
+		if i < 0 {
+			return false, nil, nil
+		}
+
+		name, ok := nodePoolName(pools[i])
+		same := slices.IndexFunc(pools, func(np linodego.LKENodePool) bool {
+			other, _ := nodePoolName(np)
+			return np.ID != poolID && other == name
+		})
+
+		// duplicates of the node pool may be removed, as long as one remains
+		if _, inSpec := s.spec.NodePools[name]; ok && inSpec && same < 0 {
+			s.violate("node pool %q (%d) deleted while still in the spec", name, poolID)
+		}
+	}
+
+	return false, nil, nil
+}
+
+// update fails the object updates while failedUpdates are injected.
+func (s *simulation) update(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+	if s.failedUpdates > 0 {
+		s.failedUpdates--
+
+		return apierrors.NewConflict(schema.GroupResource{Group: v1alpha1.GroupVersion.Group, Resource: "lkeclusterconfigs"},
+			obj.GetName(), errors.New("injected conflict"))
+	}
+
+	return c.Update(ctx, obj, opts...)
+}
+
+func (s *simulation) logf(format string, args ...any) {
+	s.log = append(s.log, fmt.Sprintf(format, args...))
+}
+
+func (s *simulation) violate(format string, args ...any) {
+	s.violations = append(s.violations, fmt.Sprintf(format, args...))
+}
+
+func (s *simulation) object() *v1alpha1.LKEClusterConfig {
+	s.t.Helper()
+
+	lke := &v1alpha1.LKEClusterConfig{}
+	if err := s.kube.Get(context.Background(), s.key, lke); err != nil {
+		s.t.Fatalf("failed to get object: %v", err)
+	}
+
+	return lke
+}
+
+// ownedClusters returns the LKE clusters owned by the object.
+func (s *simulation) ownedClusters() []linodego.LKECluster {
+	tag := ownerUIDTag(s.object())
+
+	owned := []linodego.LKECluster{}
+
+	for _, cluster := range s.backend.Clusters() {
+		if slices.Contains(cluster.Tags, tag) {
+			owned = append(owned, cluster)
+		}
+	}
+
+	return owned
+}
+
+func (s *simulation) reconcile() (ctrl.Result, error) {
+	res, err := s.r.Reconcile(context.Background(), ctrl.Request{NamespacedName: s.key})
+	s.logf("reconcile: %+v, %v", res, err)
+
+	if owned := s.ownedClusters(); len(owned) > 1 {
+		s.violate("%d LKE clusters owned by the object", len(owned))
+	}
+
+	return res, err
+}
+
+// mutate applies the random change to the spec, as the user would.
+func (s *simulation) mutate() {
+	lke := s.object()
+	pools := lke.Spec.NodePools
+
+	name := simPoolNames[s.choices.next(len(simPoolNames))]
+	count := 1 + s.choices.next(3)
+
+	switch _, exists := pools[name]; {
+	case s.choices.next(4) == 0:
+		lke.Spec.HighAvailability = mkptr(!deref(lke.Spec.HighAvailability))
+		s.logf("mutate: high availability %v", *lke.Spec.HighAvailability)
+
+	case exists && len(pools) > 1 && s.choices.next(2) == 0:
+		delete(pools, name)
+		s.logf("mutate: remove pool %s", name)
+
+	default:
+		pools[name] = v1alpha1.LKENodePool{NodeCount: count, LinodeType: simPoolTypes[name]}
+		s.logf("mutate: pool %s with %d nodes", name, count)
+	}
+
+	// the API server bumps the generation on spec changes
+	lke.Generation++
+
+	if err := s.kube.Update(context.Background(), lke); err != nil {
+		s.t.Fatalf("failed to update spec: %v", err)
+	}
+
+	s.spec = *lke.Spec.DeepCopy()
+}
+
+func (s *simulation) injectAPIError() {
+	method := simMethods[s.choices.next(len(simMethods))]
+	err := simErrors[s.choices.next(len(simErrors))]
+
+	s.failedCalls[method] = append(s.failedCalls[method], err)
+	s.logf("inject: %s fails with %v", method, err)
+}
+
+func (s *simulation) injectUpdateFailure() {
+	s.failedUpdates++
+	s.logf("inject: update fails")
+}
+
+// run performs the random steps, then stops the failures and checks that the
+// object converges to the spec.
+func (s *simulation) run() {
+	for step := 0; step < simMaxSteps && !s.choices.done(); step++ {
+		switch s.choices.next(8) {
+		case 0, 1, 2, 3:
+			_, _ = s.reconcile()
+		case 4, 5:
+			s.mutate()
+		case 6:
+			s.injectAPIError()
+		case 7:
+			s.injectUpdateFailure()
+		}
+	}
+
+	s.failedCalls = map[string][]error{}
+	s.failedUpdates = 0
+	s.logf("failures stopped")
+
+	converged := false
+
+	for range simConvergeReconciles {
+		res, err := s.reconcile()
+		if err == nil && res.IsZero() {
+			converged = true
+			break
+		}
+	}
+
+	if !converged {
+		s.violate("not converged within %d reconciles", simConvergeReconciles)
+	}
+
+	s.checkConverged()
+
+	if len(s.violations) > 0 {
+		s.t.Fatalf("invariants violated: %q\nsimulation:\n%q", s.violations, s.log)
+	}
+}
+
+// checkConverged checks that the LKE cluster matches the spec, and the object is reported Active.
+func (s *simulation) checkConverged() {
+	lke := s.object()
+
+	cond := meta.FindStatusCondition(lke.Status.Conditions, v1alpha1.ConditionReady)
+	if deref(lke.Status.Phase) != v1alpha1.PhaseActive || cond == nil || cond.Status != metav1.ConditionTrue ||
+		cond.ObservedGeneration != lke.Generation {
+		s.violate("object not active: phase %q, condition %+v", deref(lke.Status.Phase), cond)
+	}
+
+	owned := s.ownedClusters()
+	if len(owned) != 1 {
+		s.violate("expected 1 LKE cluster owned by the object, got: %d", len(owned))
+		return
+	}
+
+	cluster := owned[0]
+
+	if id := deref(lke.Status.ClusterID); id != cluster.ID {
+		s.violate("expected ClusterID value: %d, got: %d", cluster.ID, id)
+	}
+
+	if ha := deref(s.spec.HighAvailability); cluster.ControlPlane.HighAvailability != ha {
+		s.violate("expected HighAvailability value: %v, got: %v", ha, cluster.ControlPlane.HighAvailability)
+	}
+
+	actual := map[string]v1alpha1.LKENodePool{}
+
+	for _, np := range s.backend.NodePools(cluster.ID) {
+		name, ok := nodePoolName(np)
+		if !ok {
+			continue
+		}
+
+		if _, duplicate := actual[name]; duplicate {
+			s.violate("duplicate node pool %q", name)
+		}
+
+		actual[name] = v1alpha1.LKENodePool{NodeCount: np.Count, LinodeType: np.Type}
+	}
+
+	if !maps.Equal(actual, s.spec.NodePools) {
+		s.violate("expected node pools: %+v, got: %+v", s.spec.NodePools, actual)
+	}
+}
+
+func TestReconcileSimulation(t *testing.T) {
+	t.Parallel()
+
+	for seed := range uint64(simRuns) {
+		seed := seed
+		t.Run(fmt.Sprintf("seed_%d", seed), func(t *testing.T) {
+			t.Parallel()
+
+			rng := rand.New(rand.NewPCG(seed, seed))
+
+			data := make([]byte, 1+rng.IntN(simMaxSteps*2))
+			for i := range data {
+				data[i] = byte(rng.Uint32())
+			}
+
+			newSimulation(t, data).run()
+		})
+	}
+}
+
+// FuzzReconcileSimulation runs the simulations chosen by the fuzzer, e.g.
+// go test ./internal/controller/ -run '^$' -fuzz FuzzReconcileSimulation
+func FuzzReconcileSimulation(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 4, 0, 0, 0})
+	f.Add([]byte{6, 3, 0, 0, 4, 1, 1, 0, 0, 7, 0, 0, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		newSimulation(t, data).run()
+	})
+}
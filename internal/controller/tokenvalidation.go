@@ -0,0 +1,151 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/credentials"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+// DefaultTokenExpiryWarning is the default time before the token expiry,
+// from which the upcoming expiry is reported.
+const DefaultTokenExpiryWarning = 7 * 24 * time.Hour
+
+// tokenCondition evaluates the token details into the CredentialsValid
+// condition. Error is returned if the token cannot be used to manage LKE clusters.
+func tokenCondition(
+	info *credentials.TokenInfo,
+	warnBefore time.Duration,
+	now time.Time,
+) (metav1.Condition, error) {
+	if left, ok := info.ExpiresIn(now); ok && left <= 0 {
+		return metav1.Condition{
+			Type:    v1alpha1.ConditionCredentialsValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.ReasonTokenExpired,
+			Message: fmt.Sprintf("token expired at %s", info.Expiry.Format(time.RFC3339)),
+		}, internalerrors.ErrTokenExpired
+	}
+
+	if missing := info.MissingScopes(); len(missing) != 0 {
+		return metav1.Condition{
+			Type:    v1alpha1.ConditionCredentialsValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.ReasonTokenMissingScopes,
+			Message: fmt.Sprintf("token is missing scopes: %s", strings.Join(missing, ", ")),
+		}, fmt.Errorf("%w: %s", internalerrors.ErrTokenMissingScopes, strings.Join(missing, ", "))
+	}
+
+	if len(info.MissingGrants) != 0 {
+		return metav1.Condition{
+			Type:    v1alpha1.ConditionCredentialsValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.ReasonTokenMissingGrants,
+			Message: fmt.Sprintf("user %s is missing grants: %s", info.Username, strings.Join(info.MissingGrants, ", ")),
+		}, fmt.Errorf("%w: %s", internalerrors.ErrTokenMissingGrants, strings.Join(info.MissingGrants, ", "))
+	}
+
+	if left, ok := info.ExpiresIn(now); ok && left < warnBefore {
+		return metav1.Condition{
+			Type:    v1alpha1.ConditionCredentialsValid,
+			Status:  metav1.ConditionTrue,
+			Reason:  v1alpha1.ReasonTokenExpiringSoon,
+			Message: fmt.Sprintf("token expires at %s", info.Expiry.Format(time.RFC3339)),
+		}, nil
+	}
+
+	if !info.ScopesKnown() {
+		return metav1.Condition{
+			Type:   v1alpha1.ConditionCredentialsValid,
+			Status: metav1.ConditionUnknown,
+			Reason: v1alpha1.ReasonTokenScopesUnknown,
+			Message: fmt.Sprintf("scopes of the token could not be listed, read access to LKE was confirmed, "+
+				"but %s scope could not be verified", credentials.RequiredScope),
+		}, nil
+	}
+
+	return metav1.Condition{
+		Type:    v1alpha1.ConditionCredentialsValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.ReasonTokenValid,
+		Message: fmt.Sprintf("token belongs to user %s", info.Username),
+	}, nil
+}
+
+// validateToken checks the token through the Linode API, and sets the
+// CredentialsValid condition in the conditions. It returns the token details
+// and reports if the condition changed. Error is returned if the token cannot
+// be used.
+func validateToken(
+	ctx context.Context,
+	validator *credentials.Validator,
+	client lkeclient.Client,
+	cred *credentials.Credential,
+	warnBefore time.Duration,
+	conditions *[]metav1.Condition,
+	generation int64,
+) (*credentials.TokenInfo, bool, error) {
+	var cond metav1.Condition
+
+	info, err := validator.Validate(ctx, client, cred)
+	if err != nil {
+		if errors.Is(err, internalerrors.ErrCircuitOpen) {
+			return nil, false, err
+		}
+
+		cond = metav1.Condition{
+			Type:    v1alpha1.ConditionCredentialsValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.ReasonValidationFailed,
+			Message: err.Error(),
+		}
+	} else {
+		cond, err = tokenCondition(info, warnBefore, time.Now())
+	}
+
+	cond.ObservedGeneration = generation
+
+	return info, meta.SetStatusCondition(conditions, cond), err
+}
+
+// recordTokenCondition emits the Event describing the CredentialsValid condition.
+func recordTokenCondition(recorder record.EventRecorder, obj runtime.Object, cond *metav1.Condition) {
+	if recorder == nil || cond == nil {
+		return
+	}
+
+	eventType := corev1.EventTypeWarning
+	if cond.Reason == v1alpha1.ReasonTokenValid {
+		eventType = corev1.EventTypeNormal
+	}
+
+	recorder.Event(obj, eventType, cond.Reason, cond.Message)
+}
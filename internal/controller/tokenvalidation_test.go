@@ -0,0 +1,102 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/credentials"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTokenCondition(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		info           *credentials.TokenInfo
+		expectedStatus metav1.ConditionStatus
+		expectedReason string
+		targetError    error
+	}{
+		"valid": {
+			info:           &credentials.TokenInfo{Scopes: []string{"*"}},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: v1alpha1.ReasonTokenValid,
+		},
+		"unknown_scopes": {
+			info:           &credentials.TokenInfo{},
+			expectedStatus: metav1.ConditionUnknown,
+			expectedReason: v1alpha1.ReasonTokenScopesUnknown,
+		},
+		"unknown_scopes_lke_denied": {
+			info:           &credentials.TokenInfo{LKEAccessDenied: true},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: v1alpha1.ReasonTokenMissingScopes,
+			targetError:    internalerrors.ErrTokenMissingScopes,
+		},
+		"expiring_soon": {
+			info:           &credentials.TokenInfo{Expiry: mkptr(now.Add(24 * time.Hour))},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: v1alpha1.ReasonTokenExpiringSoon,
+		},
+		"expired": {
+			info:           &credentials.TokenInfo{Expiry: mkptr(now.Add(-time.Hour))},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: v1alpha1.ReasonTokenExpired,
+			targetError:    internalerrors.ErrTokenExpired,
+		},
+		"missing_scopes": {
+			info:           &credentials.TokenInfo{Scopes: []string{"linodes:read_write"}},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: v1alpha1.ReasonTokenMissingScopes,
+			targetError:    internalerrors.ErrTokenMissingScopes,
+		},
+		"missing_grants": {
+			info:           &credentials.TokenInfo{MissingGrants: []string{"add_linodes"}},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: v1alpha1.ReasonTokenMissingGrants,
+			targetError:    internalerrors.ErrTokenMissingGrants,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cond, err := tokenCondition(tc.info, DefaultTokenExpiryWarning, now)
+			if !errors.Is(err, tc.targetError) {
+				t.Errorf("expected Error value: %#+v, got: %#+v",
+					tc.targetError, err)
+			}
+
+			if cond.Status != tc.expectedStatus {
+				t.Errorf("expected Status value: %#+v, got: %#+v",
+					tc.expectedStatus, cond.Status)
+			}
+
+			if cond.Reason != tc.expectedReason {
+				t.Errorf("expected Reason value: %#+v, got: %#+v",
+					tc.expectedReason, cond.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/linode/linodego"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+// adoptedNodePoolPrefix is the prefix of the names given to adopted node pools.
+const adoptedNodePoolPrefix = "adopted-"
+
+// unmanagedNodePools returns the node pools not created by the operator. The
+// replacements of node pools are created by the operator, but not yet named.
+func unmanagedNodePools(nps []linodego.LKENodePool) []linodego.LKENodePool {
+	unmanaged := []linodego.LKENodePool{}
+
+	for _, np := range nps {
+		_, named := nodePoolName(np)
+		_, replacement := replacementName(np)
+
+		if !named && !replacement {
+			unmanaged = append(unmanaged, np)
+		}
+	}
+
+	return unmanaged
+}
+
+// handleUnmanagedNodePools adopts the unmanaged node pools if requested, and
+// reports the remaining unmanaged node pools in the status. It returns the node
+// pools of the cluster after the adoption. Unmanaged node pools are deleted with
+// the rest of the spec by deleteUnmanagedNodePools, so their deletion follows the
+// drift policy and requires approval like the deletion of any other node pool.
+func (r *LKEClusterConfigReconciler) handleUnmanagedNodePools(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+	cluster *linodego.LKECluster,
+	nps []linodego.LKENodePool,
+) ([]linodego.LKENodePool, error) {
+	unmanaged := unmanagedNodePools(nps)
+
+	if lke.Spec.UnmanagedNodePools != v1alpha1.UnmanagedNodePoolsAdopt {
+		lke.Status.UnmanagedNodePools = nil
+		for _, np := range unmanaged {
+			lke.Status.UnmanagedNodePools = append(lke.Status.UnmanagedNodePools, nodePoolStatusFromAPI(np))
+		}
+
+		return nps, nil
+	}
+
+	for _, np := range unmanaged {
+		if err := r.adoptNodePool(ctx, client, lke, cluster, np); err != nil {
+			return nil, err
+		}
+	}
+
+	lke.Status.UnmanagedNodePools = nil
+
+	if len(unmanaged) == 0 {
+		return nps, nil
+	}
+
+	nps, err := client.ListLKENodePools(ctx, cluster.ID, &linodego.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node pools: %w", err)
+	}
+
+	return nps, nil
+}
+
+// deleteUnmanagedNodePools deletes the node pools not created by the operator,
+// if the unmanaged node pools policy requests it.
+func (r *LKEClusterConfigReconciler) deleteUnmanagedNodePools(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+	cluster *linodego.LKECluster,
+	nps []linodego.LKENodePool,
+) error {
+	if lke.Spec.UnmanagedNodePools != v1alpha1.UnmanagedNodePoolsDelete {
+		return nil
+	}
+
+	for _, np := range unmanagedNodePools(nps) {
+		if err := client.DeleteLKENodePool(ctx, cluster.ID, np.ID); err != nil &&
+			!errors.Is(err, internalerrors.ErrLinodeNotFound) {
+			return fmt.Errorf("failed to delete unmanaged node pool %d: %w", np.ID, err)
+		}
+
+		r.event(lke, corev1.EventTypeNormal, "NodePoolDeleted",
+			fmt.Sprintf("deleted unmanaged node pool %d", np.ID))
+	}
+
+	lke.Status.UnmanagedNodePools = nil
+
+	return nil
+}
+
+// adoptNodePool adds the node pool to the spec, and tags it as created by the
+// operator. The spec is updated first, so the node pool is never seen as
+// managed and missing from the spec.
+func (r *LKEClusterConfigReconciler) adoptNodePool(
+	ctx context.Context,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+	cluster *linodego.LKECluster,
+	np linodego.LKENodePool,
+) error {
+	name := fmt.Sprintf("%s%d", adoptedNodePoolPrefix, np.ID)
+
+	if _, ok := lke.Spec.NodePools[name]; !ok {
+		if lke.Spec.NodePools == nil {
+			lke.Spec.NodePools = make(map[string]v1alpha1.LKENodePool)
+		}
+
+		lke.Spec.NodePools[name] = nodePoolStatusFromAPI(np).NodePoolDetails
+
+		if err := r.Update(ctx, lke); err != nil {
+			return fmt.Errorf("failed to add adopted node pool %d to spec: %w", np.ID, err)
+		}
+	}
+
+	tags := append(slices.Clone(np.Tags), lkeOperatorTag+name)
+
+	if _, err := client.UpdateLKENodePool(ctx, cluster.ID, np.ID, linodego.LKENodePoolUpdateOptions{
+		Tags: &tags,
+	}); err != nil {
+		return fmt.Errorf("failed to tag adopted node pool %d: %w", np.ID, err)
+	}
+
+	r.event(lke, corev1.EventTypeNormal, "NodePoolAdopted",
+		fmt.Sprintf("adopted node pool %d as %s", np.ID, name))
+
+	return nil
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/linode/linodego"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	lkefake "github.com/anza-labs/lke-operator/internal/lkeclient/fake"
+)
+
+type nodePoolsClient struct {
+	lkeclient.Client
+
+	pools   []linodego.LKENodePool
+	deleted []int
+}
+
+func (c *nodePoolsClient) ListLKENodePools(
+	context.Context, int, *linodego.ListOptions,
+) ([]linodego.LKENodePool, error) {
+	return c.pools, nil
+}
+
+func (c *nodePoolsClient) DeleteLKENodePool(_ context.Context, _ int, poolID int) error {
+	c.deleted = append(c.deleted, poolID)
+
+	pools := []linodego.LKENodePool{}
+	for _, np := range c.pools {
+		if np.ID != poolID {
+			pools = append(pools, np)
+		}
+	}
+
+	c.pools = pools
+
+	return nil
+}
+
+func TestLKEClusterConfigReconciler_handleUnmanagedNodePools(t *testing.T) {
+	t.Parallel()
+
+	managed := linodego.LKENodePool{
+		ID: 1, Count: 3, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"},
+	}
+	unmanaged := linodego.LKENodePool{ID: 2, Count: 1, Type: "g6-standard-2"}
+
+	for name, tc := range map[string]struct {
+		policy            v1alpha1.UnmanagedNodePoolsPolicy
+		expectedPools     []linodego.LKENodePool
+		expectedDeleted   []int
+		expectedUnmanaged []v1alpha1.NodePoolStatus
+	}{
+		"ignore": {
+			policy:          v1alpha1.UnmanagedNodePoolsIgnore,
+			expectedPools:   []linodego.LKENodePool{managed, unmanaged},
+			expectedDeleted: nil,
+			expectedUnmanaged: []v1alpha1.NodePoolStatus{{
+				ID:              mkptr(2),
+				NodePoolDetails: v1alpha1.LKENodePool{NodeCount: 1, LinodeType: "g6-standard-2"},
+			}},
+		},
+		"delete": {
+			policy:          v1alpha1.UnmanagedNodePoolsDelete,
+			expectedPools:   []linodego.LKENodePool{managed, unmanaged},
+			expectedDeleted: nil,
+			expectedUnmanaged: []v1alpha1.NodePoolStatus{{
+				ID:              mkptr(2),
+				NodePoolDetails: v1alpha1.LKENodePool{NodeCount: 1, LinodeType: "g6-standard-2"},
+			}},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &nodePoolsClient{pools: []linodego.LKENodePool{managed, unmanaged}}
+			r := &LKEClusterConfigReconciler{Recorder: record.NewFakeRecorder(10)}
+
+			lke := &v1alpha1.LKEClusterConfig{
+				Spec: v1alpha1.LKEClusterConfigSpec{UnmanagedNodePools: tc.policy},
+			}
+
+			pools, err := r.handleUnmanagedNodePools(context.Background(), client, lke,
+				&linodego.LKECluster{ID: 1}, client.pools)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(pools, tc.expectedPools) {
+				t.Errorf("expected Pools value: %#+v, got: %#+v",
+					tc.expectedPools, pools)
+			}
+
+			if !reflect.DeepEqual(client.deleted, tc.expectedDeleted) {
+				t.Errorf("expected Deleted value: %#+v, got: %#+v",
+					tc.expectedDeleted, client.deleted)
+			}
+
+			if !reflect.DeepEqual(lke.Status.UnmanagedNodePools, tc.expectedUnmanaged) {
+				t.Errorf("expected UnmanagedNodePools value: %#+v, got: %#+v",
+					tc.expectedUnmanaged, lke.Status.UnmanagedNodePools)
+			}
+		})
+	}
+}
+
+func TestLKEClusterConfigReconciler_onChange_deleteUnmanaged(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		reconciled      bool
+		driftPolicy     v1alpha1.DriftPolicy
+		requireApproval []v1alpha1.OperationKind
+		expectedDeleted bool
+		expectedPending bool
+	}{
+		"not_reconciled": {
+			expectedDeleted: true,
+		},
+		"drift_report": {
+			reconciled:  true,
+			driftPolicy: v1alpha1.DriftPolicyReport,
+		},
+		"drift_correct": {
+			reconciled:      true,
+			driftPolicy:     v1alpha1.DriftPolicyCorrect,
+			expectedDeleted: true,
+		},
+		"approval_required": {
+			requireApproval: []v1alpha1.OperationKind{v1alpha1.OperationNodePoolDelete},
+			expectedPending: true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "test-uid", Generation: 1},
+				Spec: v1alpha1.LKEClusterConfigSpec{
+					Region:             "us-east",
+					NodePools:          map[string]v1alpha1.LKENodePool{"default": {NodeCount: 1, LinodeType: "g6-standard-1"}},
+					UnmanagedNodePools: v1alpha1.UnmanagedNodePoolsDelete,
+					DriftPolicy:        tc.driftPolicy,
+				},
+				Status: v1alpha1.LKEClusterConfigStatus{ClusterID: mkptr(1)},
+			}
+
+			if tc.requireApproval != nil {
+				obj.Spec.Protection = &v1alpha1.Protection{RequireApproval: tc.requireApproval}
+			}
+
+			r := &LKEClusterConfigReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(newTestScheme(t)).
+					WithObjects(obj).
+					Build(),
+				KubernetesClient: kubefake.NewSimpleClientset(),
+				Recorder:         record.NewFakeRecorder(100),
+			}
+
+			label, err := r.Labels.Render(obj)
+			if err != nil {
+				t.Fatalf("failed to render label: %v", err)
+			}
+
+			if tc.reconciled {
+				obj.Status.AppliedHash = appliedHash(obj, label)
+				meta.SetStatusCondition(&obj.Status.Conditions, metav1.Condition{
+					Type:               v1alpha1.ConditionReady,
+					Status:             metav1.ConditionTrue,
+					Reason:             v1alpha1.ReasonReconciled,
+					ObservedGeneration: obj.Generation,
+				})
+			}
+
+			client := lkefake.NewClient()
+			client.AddCluster(
+				linodego.LKECluster{
+					ID:         1,
+					Label:      label,
+					Region:     "us-east",
+					K8sVersion: "1.30",
+					Tags:       ownershipTags(obj, DefaultInstanceID),
+				},
+				linodego.LKENodePool{Count: 1, Type: "g6-standard-1", Tags: []string{lkeOperatorTag + "default"}},
+				linodego.LKENodePool{Count: 1, Type: "g6-standard-2"},
+			)
+
+			if _, err := r.onChange(context.Background(), client, obj); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			deleted := len(unmanagedNodePools(client.NodePools(1))) == 0
+			if deleted != tc.expectedDeleted {
+				t.Errorf("expected unmanaged node pool deleted: %#+v, got: %#+v",
+					tc.expectedDeleted, deleted)
+			}
+
+			if slices.Contains(client.Methods(), "DeleteLKENodePool") != tc.expectedDeleted {
+				t.Errorf("expected DeleteLKENodePool call: %#+v, got: %#+v",
+					tc.expectedDeleted, client.Methods())
+			}
+
+			if pending := obj.Status.PendingApproval != nil; pending != tc.expectedPending {
+				t.Errorf("expected PendingApproval: %#+v, got: %#+v",
+					tc.expectedPending, obj.Status.PendingApproval)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package credentials provides sources of Linode API tokens.
+package credentials
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultTokenKey is the key in the Secret data holding the token, used
+	// when the reference does not specify one.
+	DefaultTokenKey = "LINODE_TOKEN"
+
+	// TokenEnv is the environment variable holding the default token.
+	TokenEnv = "LINODE_TOKEN"
+)
+
+// Credential is a Linode API token along with the identity of its source.
+type Credential struct {
+	// Token is the Linode API token.
+	Token string
+
+	// Key identifies the source of the token and changes each time the token
+	// might have changed.
+	Key lkeclient.Key
+
+	// Endpoint configures how the Linode API is reached with the token. Zero
+	// Endpoint uses the defaults configured for the operator.
+	Endpoint lkeclient.Endpoint
+}
+
+// Provider returns Linode API credentials.
+type Provider interface {
+	Credential(ctx context.Context) (*Credential, error)
+}
+
+// Static returns Provider always returning the same token.
+func Static(token string) Provider {
+	return &staticProvider{token: token}
+}
+
+type staticProvider struct {
+	token string
+}
+
+func (p *staticProvider) Credential(context.Context) (*Credential, error) {
+	return &Credential{
+		Token: p.token,
+		Key: lkeclient.Key{
+			ID:      "static",
+			Version: digest(p.token),
+		},
+	}, nil
+}
+
+// File returns Provider reading the token from the file, e.g. a mounted Secret.
+// The file is read on each call, so the rotated token is picked up without restart.
+func File(path string) Provider {
+	return &fileProvider{path: filepath.Clean(path)}
+}
+
+type fileProvider struct {
+	path string
+}
+
+func (p *fileProvider) Credential(context.Context) (*Credential, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return nil, fmt.Errorf("%w: %s", internalerrors.ErrTokenMissing, p.path)
+	}
+
+	return &Credential{
+		Token: token,
+		Key: lkeclient.Key{
+			ID:      "file:" + p.path,
+			Version: digest(token),
+		},
+	}, nil
+}
+
+// Default returns Provider for the operator-wide default token. Token file
+// takes precedence over the token passed directly, which takes precedence over
+// the token from the environment. Nil is returned if no default token is configured.
+func Default(token, tokenFile string) Provider {
+	switch {
+	case tokenFile != "":
+		return File(tokenFile)
+	case token != "":
+		return Static(token)
+	}
+
+	if token, ok := os.LookupEnv(TokenEnv); ok && token != "" {
+		return Static(token)
+	}
+
+	return nil
+}
+
+// WithEndpoint returns Provider setting the endpoint of the credentials returned
+// by the provider. Clients using different endpoints do not share the state, even
+// if the token is the same.
+func WithEndpoint(provider Provider, endpoint lkeclient.Endpoint) Provider {
+	if endpoint.IsZero() {
+		return provider
+	}
+
+	return &endpointProvider{provider: provider, endpoint: endpoint}
+}
+
+type endpointProvider struct {
+	provider Provider
+	endpoint lkeclient.Endpoint
+}
+
+func (p *endpointProvider) Credential(ctx context.Context) (*Credential, error) {
+	if err := p.endpoint.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Linode API endpoint: %w", err)
+	}
+
+	cred, err := p.provider.Credential(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cred.Endpoint = p.endpoint
+	cred.Key.ID += "@" + p.endpoint.Digest()
+
+	return cred, nil
+}
+
+// SecretRef identifies the key of a Secret holding the token.
+type SecretRef struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+// Secret returns Provider reading the token from the Kubernetes Secret.
+func Secret(client kubernetes.Interface, ref SecretRef) Provider {
+	if ref.Key == "" {
+		ref.Key = DefaultTokenKey
+	}
+
+	return &secretProvider{client: client, ref: ref}
+}
+
+type secretProvider struct {
+	client kubernetes.Interface
+	ref    SecretRef
+}
+
+func (p *secretProvider) Credential(ctx context.Context) (*Credential, error) {
+	log := log.FromContext(ctx)
+
+	log.V(8).Info("fetching token for client",
+		"secret.name", p.ref.Name,
+		"secret.namespace", p.ref.Namespace)
+
+	secret, err := p.client.CoreV1().Secrets(p.ref.Namespace).Get(ctx, p.ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	if secret == nil {
+		return nil, fmt.Errorf("%w: %s/%s",
+			internalerrors.ErrNilSecret,
+			p.ref.Namespace,
+			p.ref.Name,
+		)
+	}
+
+	token, ok := secret.Data[p.ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s (key:%q)",
+			internalerrors.ErrTokenMissing,
+			secret.Namespace,
+			secret.Name,
+			p.ref.Key,
+		)
+	}
+
+	return &Credential{
+		Token: string(token),
+		Key: lkeclient.Key{
+			ID:      string(secret.UID) + "/" + p.ref.Key,
+			Version: secret.ResourceVersion,
+		},
+	}, nil
+}
+
+func digest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
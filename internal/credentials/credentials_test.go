@@ -0,0 +1,204 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSecret(t *testing.T) {
+	t.Parallel()
+
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "linode",
+			UID:             "uid",
+			ResourceVersion: "42",
+		},
+		Data: map[string][]byte{
+			DefaultTokenKey: []byte("default-token"),
+			"custom":        []byte("custom-token"),
+		},
+	})
+
+	for name, tc := range map[string]struct {
+		ref           SecretRef
+		expectedToken string
+		targetError   error
+	}{
+		"default_key": {
+			ref:           SecretRef{Namespace: "default", Name: "linode"},
+			expectedToken: "default-token",
+		},
+		"custom_key": {
+			ref:           SecretRef{Namespace: "default", Name: "linode", Key: "custom"},
+			expectedToken: "custom-token",
+		},
+		"missing_key": {
+			ref:         SecretRef{Namespace: "default", Name: "linode", Key: "missing"},
+			targetError: internalerrors.ErrTokenMissing,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cred, err := Secret(client, tc.ref).Credential(context.Background())
+			if !errors.Is(err, tc.targetError) {
+				t.Fatalf("expected Error value: %#+v, got: %#+v",
+					tc.targetError, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if cred.Token != tc.expectedToken {
+				t.Errorf("expected Token value: %#+v, got: %#+v",
+					tc.expectedToken, cred.Token)
+			}
+
+			if cred.Key.Version != "42" {
+				t.Errorf("expected Version value: %#+v, got: %#+v",
+					"42", cred.Key.Version)
+			}
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "token")
+
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	provider := File(path)
+
+	first, err := provider.Credential(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first.Token != "first" {
+		t.Errorf("expected Token value: %#+v, got: %#+v", "first", first.Token)
+	}
+
+	if err := os.WriteFile(path, []byte("second"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	second, err := provider.Credential(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if second.Key.ID != first.Key.ID || second.Key.Version == first.Key.Version {
+		t.Errorf("expected the same ID and new Version after rotation, got: %#+v and %#+v",
+			first.Key, second.Key)
+	}
+}
+
+func TestWithEndpoint(t *testing.T) {
+	t.Parallel()
+
+	plain, err := Static("token").Credential(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		endpoint    lkeclient.Endpoint
+		expectedID  bool
+		expectedErr bool
+	}{
+		"zero": {
+			endpoint:   lkeclient.Endpoint{},
+			expectedID: true,
+		},
+		"proxy": {
+			endpoint: lkeclient.Endpoint{Proxy: "http://proxy:3128"},
+		},
+		"invalid_url": {
+			endpoint:    lkeclient.Endpoint{URL: "api.linode.com"},
+			expectedErr: true,
+		},
+		"invalid_ca": {
+			endpoint:    lkeclient.Endpoint{CA: []byte("not a certificate")},
+			expectedErr: true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cred, err := WithEndpoint(Static("token"), tc.endpoint).Credential(context.Background())
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %#+v, got: %#+v", tc.expectedErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if cred.Endpoint.Proxy != tc.endpoint.Proxy {
+				t.Errorf("expected Endpoint value: %#+v, got: %#+v", tc.endpoint, cred.Endpoint)
+			}
+
+			if (cred.Key.ID == plain.Key.ID) != tc.expectedID {
+				t.Errorf("expected the same ID: %#+v, got: %#+v and %#+v",
+					tc.expectedID, plain.Key.ID, cred.Key.ID)
+			}
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	t.Setenv(TokenEnv, "")
+
+	if p := Default("", ""); p != nil {
+		t.Errorf("expected no provider, got: %#+v", p)
+	}
+
+	if _, ok := Default("token", "").(*staticProvider); !ok {
+		t.Errorf("expected static provider")
+	}
+
+	if _, ok := Default("token", "/path").(*fileProvider); !ok {
+		t.Errorf("expected file provider")
+	}
+
+	t.Setenv(TokenEnv, "env-token")
+
+	p, ok := Default("", "").(*staticProvider)
+	if !ok || p.token != "env-token" {
+		t.Errorf("expected static provider with token from environment, got: %#+v", p)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	"github.com/linode/linodego"
+)
+
+// TokenInfo describes the token as seen by the Linode API.
+type TokenInfo struct {
+	// Username is the name of the Linode user owning the token.
+	Username string
+
+	// Email is the email address of the Linode user owning the token.
+	Email string
+
+	// Scopes lists the OAuth scopes of the token. Nil if the token could not
+	// be found on the token list.
+	Scopes []string
+
+	// Expiry is the time after which the token can no longer be used. Nil if the
+	// token does not expire, or could not be found on the token list.
+	Expiry *time.Time
+
+	// Restricted is true if the user owning the token has restricted access
+	// to the account.
+	Restricted bool
+
+	// MissingGrants lists the global grants the restricted user lacks to
+	// manage LKE clusters.
+	MissingGrants []string
+
+	// LKEAccessDenied is true if the scopes are unknown, and the read-only
+	// request to the LKE endpoints was rejected.
+	LKEAccessDenied bool
+}
+
+// RequiredScope is the OAuth scope needed to manage LKE clusters.
+const RequiredScope = "lke:read_write"
+
+// ScopesKnown reports if the scopes of the token were found on the token list.
+func (i *TokenInfo) ScopesKnown() bool {
+	return i.Scopes != nil
+}
+
+// MissingScopes returns the required scopes the token lacks. If the scopes of
+// the token are unknown, the required scope is missing only if the access to
+// the LKE endpoints was denied.
+func (i *TokenInfo) MissingScopes() []string {
+	if !i.ScopesKnown() {
+		if i.LKEAccessDenied {
+			return []string{RequiredScope}
+		}
+
+		return nil
+	}
+
+	for _, scope := range i.Scopes {
+		if scope == "*" || scope == RequiredScope {
+			return nil
+		}
+	}
+
+	return []string{RequiredScope}
+}
+
+// ExpiresIn returns the time left until the token expires. False is returned
+// if the token does not expire.
+func (i *TokenInfo) ExpiresIn(now time.Time) (time.Duration, bool) {
+	if i.Expiry == nil {
+		return 0, false
+	}
+
+	return i.Expiry.Sub(now), true
+}
+
+// Inspect fetches details of the token from the Linode profile endpoints. If the
+// scopes of the token cannot be listed, access to the LKE endpoints is probed.
+// Tokens scoped only to LKE may be denied access to the profile endpoints, in
+// which case the details they return are left empty, as long as the access to
+// LKE is not denied too.
+func Inspect(ctx context.Context, client lkeclient.Client, token string) (*TokenInfo, error) {
+	info := &TokenInfo{}
+
+	profile, profileErr := client.GetProfile(ctx)
+	if profileErr != nil && !denied(profileErr) {
+		return nil, fmt.Errorf("failed to get profile: %w", profileErr)
+	}
+
+	if profile != nil {
+		info.Username = profile.Username
+		info.Email = profile.Email
+		info.Restricted = profile.Restricted
+	}
+
+	if info.Restricted {
+		grants, err := client.GrantsList(ctx)
+		if err != nil && !denied(err) {
+			return nil, fmt.Errorf("failed to list grants: %w", err)
+		}
+
+		if err == nil && (grants == nil || !grants.Global.AddLinodes) {
+			info.MissingGrants = append(info.MissingGrants, "add_linodes")
+		}
+	}
+
+	tokens, err := client.ListTokens(ctx, nil)
+	// Listing tokens requires the account scope, which is not required
+	// to manage LKE clusters, so lack of it is not an error.
+	if err != nil && !denied(err) {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	for _, t := range tokens {
+		// Only the prefix of the token is returned by the API.
+		if t.Token == "" || !strings.HasPrefix(token, t.Token) {
+			continue
+		}
+
+		info.Scopes = strings.FieldsFunc(t.Scopes, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+		info.Expiry = t.Expiry
+
+		break
+	}
+
+	if !info.ScopesKnown() {
+		lkeDenied, err := probeLKE(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		// the token denied access to both the profile and LKE is not usable at all,
+		// e.g. it is invalid
+		if lkeDenied && profileErr != nil {
+			return nil, fmt.Errorf("failed to get profile: %w", profileErr)
+		}
+
+		info.LKEAccessDenied = lkeDenied
+	}
+
+	return info, nil
+}
+
+// probeLKE sends the read-only request to the LKE endpoints, and reports if it
+// was rejected. Only the read access is verified this way.
+func probeLKE(ctx context.Context, client lkeclient.Client) (bool, error) {
+	_, err := client.ListLKEClusters(ctx, &linodego.ListOptions{
+		PageOptions: &linodego.PageOptions{Page: 1},
+		PageSize:    25,
+	})
+	if err != nil {
+		if denied(err) {
+			return true, nil
+		}
+
+		return false, fmt.Errorf("failed to list LKE clusters: %w", err)
+	}
+
+	return false, nil
+}
+
+// denied reports if the request was rejected for the lack of access.
+func denied(err error) bool {
+	return linodego.ErrHasStatus(err, http.StatusUnauthorized, http.StatusForbidden)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// DefaultValidationTTL is the default duration for which the validation
+// result of a token is reused.
+const DefaultValidationTTL = 15 * time.Minute
+
+var tokenExpiryDays = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "lke_operator_linode_token_expiry_days",
+		Help: "Number of days until the Linode API token expires.",
+	},
+	[]string{"credentials"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(tokenExpiryDays)
+}
+
+// Validator inspects tokens through the Linode API, caching the results.
+type Validator struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*validation
+}
+
+type validation struct {
+	version   string
+	info      *TokenInfo
+	checkedAt time.Time
+}
+
+// NewValidator returns Validator reusing the results for the ttl. Results are
+// dropped earlier, if the token changes.
+func NewValidator(ttl time.Duration) *Validator {
+	return &Validator{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*validation),
+	}
+}
+
+// Validate returns the details of the credential token. The Linode API is
+// queried only if there is no valid cached result.
+func (v *Validator) Validate(
+	ctx context.Context,
+	client lkeclient.Client,
+	cred *Credential,
+) (*TokenInfo, error) {
+	now := v.now()
+
+	v.mu.Lock()
+	entry, ok := v.entries[cred.Key.ID]
+	v.mu.Unlock()
+
+	if ok && entry.version == cred.Key.Version && now.Sub(entry.checkedAt) < v.ttl {
+		return entry.info, nil
+	}
+
+	info, err := Inspect(ctx, client, cred.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	v.entries[cred.Key.ID] = &validation{
+		version:   cred.Key.Version,
+		info:      info,
+		checkedAt: now,
+	}
+	v.mu.Unlock()
+
+	if left, ok := info.ExpiresIn(now); ok {
+		tokenExpiryDays.WithLabelValues(cred.Key.ID).Set(left.Hours() / 24)
+	} else {
+		tokenExpiryDays.DeleteLabelValues(cred.Key.ID)
+	}
+
+	return info, nil
+}
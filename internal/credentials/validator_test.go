@@ -0,0 +1,222 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	"github.com/linode/linodego"
+)
+
+// profileClient implements the profile endpoints of lkeclient.Client.
+type profileClient struct {
+	lkeclient.Client
+
+	profile *linodego.Profile
+	tokens  []linodego.Token
+	grants  *linodego.GrantsListResponse
+
+	profileErr error
+	grantsErr  error
+	tokensErr  error
+	lkeErr     error
+	calls      int
+}
+
+func (c *profileClient) GetProfile(context.Context) (*linodego.Profile, error) {
+	c.calls++
+	if c.profileErr != nil {
+		return nil, c.profileErr
+	}
+
+	return c.profile, nil
+}
+
+func (c *profileClient) ListTokens(context.Context, *linodego.ListOptions) ([]linodego.Token, error) {
+	return c.tokens, c.tokensErr
+}
+
+func (c *profileClient) ListLKEClusters(context.Context, *linodego.ListOptions) ([]linodego.LKECluster, error) {
+	return nil, c.lkeErr
+}
+
+func (c *profileClient) GrantsList(context.Context) (*linodego.GrantsListResponse, error) {
+	return c.grants, c.grantsErr
+}
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+
+	expiry := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		client                *profileClient
+		expectedErr           bool
+		expectedScopes        []string
+		expectedMissingScopes []string
+		expectedMissingGrants []string
+		expectedExpiry        *time.Time
+	}{
+		"all_scopes": {
+			client: &profileClient{
+				profile: &linodego.Profile{Username: "user"},
+				tokens:  []linodego.Token{{Token: "abc", Scopes: "*", Expiry: &expiry}},
+			},
+			expectedScopes: []string{"*"},
+			expectedExpiry: &expiry,
+		},
+		"missing_scope": {
+			client: &profileClient{
+				profile: &linodego.Profile{Username: "user"},
+				tokens: []linodego.Token{
+					{Token: "xyz", Scopes: "*"},
+					{Token: "abc", Scopes: "linodes:read_write lke:read_only"},
+				},
+			},
+			expectedScopes:        []string{"linodes:read_write", "lke:read_only"},
+			expectedMissingScopes: []string{RequiredScope},
+		},
+		"tokens_forbidden": {
+			client: &profileClient{
+				profile:   &linodego.Profile{Username: "user"},
+				tokensErr: &linodego.Error{Code: http.StatusUnauthorized},
+			},
+		},
+		"tokens_forbidden_lke_denied": {
+			client: &profileClient{
+				profile:   &linodego.Profile{Username: "user"},
+				tokensErr: &linodego.Error{Code: http.StatusUnauthorized},
+				lkeErr:    &linodego.Error{Code: http.StatusUnauthorized},
+			},
+			expectedMissingScopes: []string{RequiredScope},
+		},
+		"token_not_listed_lke_denied": {
+			client: &profileClient{
+				profile: &linodego.Profile{Username: "user"},
+				tokens:  []linodego.Token{{Token: "xyz", Scopes: "*"}},
+				lkeErr:  &linodego.Error{Code: http.StatusForbidden},
+			},
+			expectedMissingScopes: []string{RequiredScope},
+		},
+		"profile_forbidden": {
+			client: &profileClient{
+				profileErr: &linodego.Error{Code: http.StatusForbidden},
+				tokensErr:  &linodego.Error{Code: http.StatusForbidden},
+			},
+		},
+		"profile_forbidden_lke_denied": {
+			client: &profileClient{
+				profileErr: &linodego.Error{Code: http.StatusUnauthorized},
+				tokensErr:  &linodego.Error{Code: http.StatusUnauthorized},
+				lkeErr:     &linodego.Error{Code: http.StatusUnauthorized},
+			},
+			expectedErr: true,
+		},
+		"grants_forbidden": {
+			client: &profileClient{
+				profile:   &linodego.Profile{Username: "user", Restricted: true},
+				grantsErr: &linodego.Error{Code: http.StatusForbidden},
+				tokensErr: &linodego.Error{Code: http.StatusForbidden},
+			},
+		},
+		"restricted_user": {
+			client: &profileClient{
+				profile: &linodego.Profile{Username: "user", Restricted: true},
+				tokens:  []linodego.Token{{Token: "abc", Scopes: "lke:read_write"}},
+				grants:  &linodego.GrantsListResponse{},
+			},
+			expectedScopes:        []string{RequiredScope},
+			expectedMissingGrants: []string{"add_linodes"},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			info, err := Inspect(context.Background(), tc.client, "abcdef")
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %#+v, got: %v", tc.expectedErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(info.Scopes, tc.expectedScopes) {
+				t.Errorf("expected Scopes value: %#+v, got: %#+v",
+					tc.expectedScopes, info.Scopes)
+			}
+
+			if missing := info.MissingScopes(); !reflect.DeepEqual(missing, tc.expectedMissingScopes) {
+				t.Errorf("expected MissingScopes value: %#+v, got: %#+v",
+					tc.expectedMissingScopes, missing)
+			}
+
+			if !reflect.DeepEqual(info.MissingGrants, tc.expectedMissingGrants) {
+				t.Errorf("expected MissingGrants value: %#+v, got: %#+v",
+					tc.expectedMissingGrants, info.MissingGrants)
+			}
+
+			if !reflect.DeepEqual(info.Expiry, tc.expectedExpiry) {
+				t.Errorf("expected Expiry value: %#+v, got: %#+v",
+					tc.expectedExpiry, info.Expiry)
+			}
+		})
+	}
+}
+
+func TestValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	v := NewValidator(time.Minute)
+	v.now = func() time.Time { return now }
+
+	client := &profileClient{profile: &linodego.Profile{Username: "user"}}
+	cred := &Credential{Token: "token", Key: lkeclient.Key{ID: "id", Version: "1"}}
+
+	for _, step := range []struct {
+		advance       time.Duration
+		version       string
+		expectedCalls int
+	}{
+		{expectedCalls: 1},
+		{advance: 30 * time.Second, expectedCalls: 1},
+		{advance: 30 * time.Second, expectedCalls: 2},
+		{version: "2", expectedCalls: 3},
+	} {
+		now = now.Add(step.advance)
+		if step.version != "" {
+			cred.Key.Version = step.version
+		}
+
+		if _, err := v.Validate(context.Background(), client, cred); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if client.calls != step.expectedCalls {
+			t.Errorf("expected calls value: %#+v, got: %#+v",
+				step.expectedCalls, client.calls)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package emulator implements an in-memory emulator of the Linode API endpoints
+// used by the operator. It keeps stateful LKE clusters, node pools, Linodes,
+// versions and kubeconfigs, simulates provisioning and deletion delays, and
+// responds with injected errors, so the operator can be tested without a Linode
+// account.
+package emulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
+// APIVersion is the version of the Linode API served by the emulator.
+const APIVersion = "v4"
+
+// AdminPath prefixes the endpoints controlling the emulator. Faults are injected
+// by posting the JSON encoded Fault to AdminPath/faults, and cleared by deleting it.
+const AdminPath = "/emulator"
+
+// Options configures the emulator.
+type Options struct {
+	// Versions are the Kubernetes versions offered for the LKE clusters.
+	// Defaults to DefaultVersions.
+	Versions []string
+
+	// ProvisioningDelay is the time after which the new LKE clusters and nodes
+	// become ready. Kubeconfig is not available until the cluster is ready.
+	ProvisioningDelay time.Duration
+
+	// DeletionDelay is the time for which the deleted LKE clusters are still
+	// returned by the API.
+	DeletionDelay time.Duration
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// DefaultVersions returns the Kubernetes versions offered by default.
+func DefaultVersions() []string {
+	return []string{"1.29", "1.30"}
+}
+
+// Fault makes the emulator respond with the error status to the matching requests.
+type Fault struct {
+	// Method of the matching requests. Empty matches all methods.
+	Method string `json:"method,omitempty"`
+
+	// Path prefix of the matching requests, e.g. /v4/lke/clusters. Empty matches
+	// all paths.
+	Path string `json:"path,omitempty"`
+
+	// Status is the HTTP status of the response, e.g. 404, 429 or 503.
+	Status int `json:"status"`
+
+	// Count is the number of requests failing. Zero fails all matching requests,
+	// until the faults are cleared.
+	Count int `json:"count,omitempty"`
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	return (f.Method == "" || strings.EqualFold(f.Method, r.Method)) &&
+		strings.HasPrefix(r.URL.Path, f.Path)
+}
+
+// Emulator is the http.Handler emulating the Linode API.
+type Emulator struct {
+	opts    Options
+	handler http.Handler
+	admin   *http.ServeMux
+
+	mu            sync.Mutex
+	nextID        int
+	clusters      map[int]*cluster
+	linodes       map[int]*linodego.Instance
+	nodeBalancers map[int]*linodego.NodeBalancer
+	volumes       map[int]*linodego.Volume
+	faults        []*Fault
+}
+
+var _ http.Handler = (*Emulator)(nil)
+
+// New returns the emulator without any resources.
+func New(opts Options) *Emulator {
+	if len(opts.Versions) == 0 {
+		opts.Versions = DefaultVersions()
+	}
+
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	e := &Emulator{
+		opts:          opts,
+		nextID:        1,
+		clusters:      make(map[int]*cluster),
+		linodes:       make(map[int]*linodego.Instance),
+		nodeBalancers: make(map[int]*linodego.NodeBalancer),
+		volumes:       make(map[int]*linodego.Volume),
+	}
+
+	mux := http.NewServeMux()
+	prefix := "/" + APIVersion
+
+	mux.HandleFunc("GET "+prefix+"/lke/versions", e.listVersions)
+	mux.HandleFunc("GET "+prefix+"/lke/clusters", e.listClusters)
+	mux.HandleFunc("POST "+prefix+"/lke/clusters", e.createCluster)
+	mux.HandleFunc("GET "+prefix+"/lke/clusters/{clusterID}", e.getCluster)
+	mux.HandleFunc("PUT "+prefix+"/lke/clusters/{clusterID}", e.updateCluster)
+	mux.HandleFunc("DELETE "+prefix+"/lke/clusters/{clusterID}", e.deleteCluster)
+	mux.HandleFunc("GET "+prefix+"/lke/clusters/{clusterID}/api-endpoints", e.listAPIEndpoints)
+	mux.HandleFunc("GET "+prefix+"/lke/clusters/{clusterID}/kubeconfig", e.getKubeconfig)
+	mux.HandleFunc("GET "+prefix+"/lke/clusters/{clusterID}/dashboard", e.getDashboard)
+	mux.HandleFunc("GET "+prefix+"/lke/clusters/{clusterID}/pools", e.listPools)
+	mux.HandleFunc("POST "+prefix+"/lke/clusters/{clusterID}/pools", e.createPool)
+	mux.HandleFunc("GET "+prefix+"/lke/clusters/{clusterID}/pools/{poolID}", e.getPool)
+	mux.HandleFunc("PUT "+prefix+"/lke/clusters/{clusterID}/pools/{poolID}", e.updatePool)
+	mux.HandleFunc("DELETE "+prefix+"/lke/clusters/{clusterID}/pools/{poolID}", e.deletePool)
+	mux.HandleFunc("DELETE "+prefix+"/lke/clusters/{clusterID}/nodes/{nodeID}", e.recycleNode)
+
+	mux.HandleFunc("GET "+prefix+"/linode/instances", e.listLinodes)
+	mux.HandleFunc("GET "+prefix+"/linode/instances/{linodeID}", e.getLinode)
+	mux.HandleFunc("GET "+prefix+"/nodebalancers", e.listNodeBalancers)
+	mux.HandleFunc("DELETE "+prefix+"/nodebalancers/{nodeBalancerID}", e.deleteNodeBalancer)
+	mux.HandleFunc("GET "+prefix+"/volumes", e.listVolumes)
+	mux.HandleFunc("DELETE "+prefix+"/volumes/{volumeID}", e.deleteVolume)
+
+	mux.HandleFunc("GET "+prefix+"/profile", e.getProfile)
+	mux.HandleFunc("GET "+prefix+"/profile/tokens", e.listTokens)
+	mux.HandleFunc("GET "+prefix+"/profile/grants", e.getGrants)
+
+	e.handler = mux
+
+	e.admin = http.NewServeMux()
+	e.admin.HandleFunc("POST "+AdminPath+"/faults", e.injectFault)
+	e.admin.HandleFunc("DELETE "+AdminPath+"/faults", e.clearFaults)
+
+	return e
+}
+
+// ServeHTTP implements http.Handler.
+func (e *Emulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, AdminPath+"/") {
+		e.admin.ServeHTTP(w, r)
+		return
+	}
+
+	if token(r) == "" {
+		writeError(w, http.StatusUnauthorized, "Invalid Token")
+		return
+	}
+
+	if status, ok := e.fault(r); ok {
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+
+		writeError(w, status, http.StatusText(status))
+
+		return
+	}
+
+	e.handler.ServeHTTP(w, r)
+}
+
+// InjectFault adds the fault. Faults are matched in the order they were added.
+func (e *Emulator) InjectFault(f Fault) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.faults = append(e.faults, &f)
+}
+
+// ClearFaults removes all faults.
+func (e *Emulator) ClearFaults() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.faults = nil
+}
+
+func (e *Emulator) injectFault(w http.ResponseWriter, r *http.Request) {
+	f := Fault{}
+	if !readJSON(w, r, &f) {
+		return
+	}
+
+	if f.Status < 400 || f.Status > 599 {
+		writeFieldError(w, "status", "status must be an HTTP error status")
+		return
+	}
+
+	e.InjectFault(f)
+
+	writeJSON(w, http.StatusOK, f)
+}
+
+func (e *Emulator) clearFaults(w http.ResponseWriter, _ *http.Request) {
+	e.ClearFaults()
+
+	writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+func (e *Emulator) fault(r *http.Request) (int, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i, f := range e.faults {
+		if !f.matches(r) {
+			continue
+		}
+
+		if f.Count > 0 {
+			f.Count--
+			if f.Count == 0 {
+				e.faults = append(e.faults[:i], e.faults[i+1:]...)
+			}
+		}
+
+		return f.Status, true
+	}
+
+	return 0, false
+}
+
+// id returns the next unique ID. Must be called with the lock held.
+func (e *Emulator) id() int {
+	id := e.nextID
+	e.nextID++
+
+	return id
+}
+
+func token(r *http.Request) string {
+	return strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer"))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeList writes all items as the single page of the paged response.
+func writeList[T any](w http.ResponseWriter, items []T) {
+	if items == nil {
+		items = []T{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data":    items,
+		"page":    1,
+		"pages":   1,
+		"results": len(items),
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, reason string) {
+	writeJSON(w, status, linodego.APIError{
+		Errors: []linodego.APIErrorReason{{Reason: reason}},
+	})
+}
+
+func writeFieldError(w http.ResponseWriter, field, reason string) {
+	writeJSON(w, http.StatusBadRequest, linodego.APIError{
+		Errors: []linodego.APIErrorReason{{Field: field, Reason: reason}},
+	})
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return false
+	}
+
+	return true
+}
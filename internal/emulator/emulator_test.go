@@ -0,0 +1,337 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emulator_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+
+	"github.com/anza-labs/lke-operator/internal/emulator"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func newTestServer(t *testing.T) (*emulator.Server, *clock, *linodego.Client) {
+	t.Helper()
+
+	clk := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	srv := emulator.NewServer(emulator.Options{
+		ProvisioningDelay: time.Minute,
+		DeletionDelay:     time.Minute,
+		Now:               clk.Now,
+	})
+	t.Cleanup(srv.Close)
+
+	client := lkeclient.New("token", "test", lkeclient.WithAPIURL(srv.URL()))
+	// errors are asserted, instead of being retried
+	client.SetRetryCount(0)
+
+	return srv, clk, client
+}
+
+func createCluster(ctx context.Context, t *testing.T, client *linodego.Client) *linodego.LKECluster {
+	t.Helper()
+
+	cluster, err := client.CreateLKECluster(ctx, linodego.LKEClusterCreateOptions{
+		Label:      "test",
+		Region:     "us-east",
+		K8sVersion: "1.30",
+		Tags:       []string{"lke-operator.uid=test"},
+		NodePools: []linodego.LKENodePoolCreateOptions{
+			{Type: "g6-standard-1", Count: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return cluster
+}
+
+func statusCode(err error) int {
+	apiErr := &linodego.Error{}
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+
+	return 0
+}
+
+func TestEmulator_Provisioning(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, clk, client := newTestServer(t)
+
+	cluster := createCluster(ctx, t, client)
+
+	if cluster.Status != linodego.LKEClusterNotReady {
+		t.Errorf("expected cluster status: %#+v, got: %#+v", linodego.LKEClusterNotReady, cluster.Status)
+	}
+
+	if _, err := client.GetLKEClusterKubeconfig(ctx, cluster.ID); statusCode(err) != http.StatusServiceUnavailable {
+		t.Errorf("expected kubeconfig to be unavailable, got: %v", err)
+	}
+
+	clk.Advance(time.Minute)
+
+	cluster, err := client.GetLKECluster(ctx, cluster.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cluster.Status != linodego.LKEClusterReady {
+		t.Errorf("expected cluster status: %#+v, got: %#+v", linodego.LKEClusterReady, cluster.Status)
+	}
+
+	kubeconfig, err := client.GetLKEClusterKubeconfig(ctx, cluster.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(kubeconfig.KubeConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(string(data), "kind: Config") {
+		t.Errorf("expected kubeconfig, got: %s", data)
+	}
+
+	pools, err := client.ListLKENodePools(ctx, cluster.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pools) != 1 || len(pools[0].Linodes) != 2 {
+		t.Fatalf("expected single pool with 2 nodes, got: %#+v", pools)
+	}
+
+	for _, n := range pools[0].Linodes {
+		if n.Status != linodego.LKELinodeReady {
+			t.Errorf("expected node status: %#+v, got: %#+v", linodego.LKELinodeReady, n.Status)
+		}
+
+		if _, err := client.GetInstance(ctx, n.InstanceID); err != nil {
+			t.Errorf("expected Linode %d to exist, got: %v", n.InstanceID, err)
+		}
+	}
+}
+
+func TestEmulator_NodePools(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, _, client := newTestServer(t)
+
+	cluster := createCluster(ctx, t, client)
+
+	pools, err := client.ListLKENodePools(ctx, cluster.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pool, err := client.UpdateLKENodePool(ctx, cluster.ID, pools[0].ID, linodego.LKENodePoolUpdateOptions{Count: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pool.Count != 3 || len(pool.Linodes) != 3 {
+		t.Errorf("expected 3 nodes, got: %#+v", pool)
+	}
+
+	added, err := client.CreateLKENodePool(ctx, cluster.ID, linodego.LKENodePoolCreateOptions{
+		Type:  "g6-standard-2",
+		Count: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := client.DeleteLKENodePool(ctx, cluster.ID, pools[0].ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pools, err = client.ListLKENodePools(ctx, cluster.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pools) != 1 || pools[0].ID != added.ID {
+		t.Errorf("expected only pool %d, got: %#+v", added.ID, pools)
+	}
+}
+
+func TestEmulator_Filter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, _, client := newTestServer(t)
+
+	cluster := createCluster(ctx, t, client)
+
+	for name, tc := range map[string]struct {
+		filter   string
+		expected int
+	}{
+		"matching tag": {
+			filter:   `{"tags":"lke-operator.uid=test"}`,
+			expected: 1,
+		},
+		"other tag": {
+			filter:   `{"tags":"lke-operator.uid=other"}`,
+			expected: 0,
+		},
+		"any of labels": {
+			filter:   `{"+or":[{"label":"missing"},{"label":"test"}]}`,
+			expected: 1,
+		},
+		"label contains": {
+			filter:   `{"label":{"+contains":"es"}}`,
+			expected: 1,
+		},
+	} {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			clusters, err := client.ListLKEClusters(ctx, linodego.NewListOptions(0, tc.filter))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(clusters) != tc.expected {
+				t.Errorf("expected clusters count: %#+v, got: %#+v", tc.expected, len(clusters))
+			}
+
+			if tc.expected > 0 && clusters[0].ID != cluster.ID {
+				t.Errorf("expected cluster ID: %#+v, got: %#+v", cluster.ID, clusters[0].ID)
+			}
+		})
+	}
+}
+
+func TestEmulator_Faults(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		fault    emulator.Fault
+		expected int
+	}{
+		"not found": {
+			fault:    emulator.Fault{Method: http.MethodGet, Path: "/v4/lke/clusters", Status: http.StatusNotFound, Count: 1},
+			expected: http.StatusNotFound,
+		},
+		"too many requests": {
+			fault:    emulator.Fault{Path: "/v4/lke", Status: http.StatusTooManyRequests, Count: 1},
+			expected: http.StatusTooManyRequests,
+		},
+		"service unavailable": {
+			fault:    emulator.Fault{Status: http.StatusServiceUnavailable, Count: 1},
+			expected: http.StatusServiceUnavailable,
+		},
+		"other method": {
+			fault:    emulator.Fault{Method: http.MethodDelete, Status: http.StatusNotFound},
+			expected: 0,
+		},
+	} {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			srv, _, client := newTestServer(t)
+
+			srv.InjectFault(tc.fault)
+
+			_, err := client.ListLKEClusters(ctx, nil)
+			if code := statusCode(err); code != tc.expected {
+				t.Errorf("expected status: %#+v, got: %#+v", tc.expected, code)
+			}
+
+			// faults with count are removed once exhausted
+			if _, err := client.ListLKEClusters(ctx, nil); err != nil && tc.fault.Count > 0 {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEmulator_Deletion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	srv, clk, client := newTestServer(t)
+
+	cluster := createCluster(ctx, t, client)
+
+	if err := client.DeleteLKECluster(ctx, cluster.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := client.GetLKECluster(ctx, cluster.ID); err != nil {
+		t.Errorf("expected cluster to be returned during the deletion, got: %v", err)
+	}
+
+	clk.Advance(time.Minute)
+
+	if _, err := client.GetLKECluster(ctx, cluster.ID); statusCode(err) != http.StatusNotFound {
+		t.Errorf("expected cluster to be deleted, got: %v", err)
+	}
+
+	if _, _, ok := srv.Cluster(cluster.ID); ok {
+		t.Errorf("expected cluster to be removed from the emulator")
+	}
+
+	linodes, err := client.ListInstances(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(linodes) != 0 {
+		t.Errorf("expected Linodes to be deleted, got: %#+v", linodes)
+	}
+}
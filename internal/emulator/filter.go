@@ -0,0 +1,174 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// filter is the parsed X-Filter header. Supported are the equality of the top-level
+// fields, with arrays matching if they contain the value, the +or and +and operators,
+// and the +contains and +neq comparisons. Ordering keys are ignored.
+type filter map[string]any
+
+// parseFilter parses the X-Filter header of the request. Nil filter matches everything.
+func parseFilter(r *http.Request) (filter, error) {
+	header := r.Header.Get("X-Filter")
+	if header == "" {
+		return nil, nil
+	}
+
+	f := filter{}
+	if err := json.Unmarshal([]byte(header), &f); err != nil {
+		return nil, fmt.Errorf("invalid X-Filter: %w", err)
+	}
+
+	return f, nil
+}
+
+// filterList returns the items matching the filter.
+func filterList[T any](f filter, items []T) ([]T, error) {
+	if len(f) == 0 {
+		return items, nil
+	}
+
+	out := []T{}
+
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+
+		fields := map[string]any{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+
+		if f.matches(fields) {
+			out = append(out, item)
+		}
+	}
+
+	return out, nil
+}
+
+func (f filter) matches(fields map[string]any) bool {
+	for key, value := range f {
+		switch key {
+		case "+order_by", "+order":
+			continue
+
+		case "+or":
+			if !anyMatches(value, fields) {
+				return false
+			}
+
+		case "+and":
+			if !allMatch(value, fields) {
+				return false
+			}
+
+		default:
+			if !compare(fields[key], value) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func subfilters(value any) []filter {
+	list, _ := value.([]any)
+	out := make([]filter, 0, len(list))
+
+	for _, v := range list {
+		if m, ok := v.(map[string]any); ok {
+			out = append(out, filter(m))
+		}
+	}
+
+	return out
+}
+
+func anyMatches(value any, fields map[string]any) bool {
+	for _, f := range subfilters(value) {
+		if f.matches(fields) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func allMatch(value any, fields map[string]any) bool {
+	for _, f := range subfilters(value) {
+		if !f.matches(fields) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func compare(field, value any) bool {
+	if op, ok := value.(map[string]any); ok {
+		for name, operand := range op {
+			switch name {
+			case "+contains":
+				s, _ := field.(string)
+				sub, _ := operand.(string)
+
+				if !strings.Contains(s, sub) {
+					return false
+				}
+
+			case "+neq":
+				if equal(field, operand) {
+					return false
+				}
+
+			default:
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return equal(field, value)
+}
+
+// equal checks if the field equals the value, or contains it if the field is an array.
+func equal(field, value any) bool {
+	if list, ok := field.([]any); ok {
+		for _, item := range list {
+			if reflect.DeepEqual(item, value) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return reflect.DeepEqual(field, value)
+}
@@ -0,0 +1,637 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emulator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
+// cluster is the state of the emulated LKE cluster.
+type cluster struct {
+	linodego.LKECluster
+
+	pools   []*pool
+	readyAt time.Time
+
+	// deletedAt is set once the deletion was requested.
+	deletedAt time.Time
+}
+
+// pool is the state of the emulated node pool.
+type pool struct {
+	linodego.LKENodePool
+
+	nodes []*node
+}
+
+// node is the state of the emulated node, backed by the Linode.
+type node struct {
+	id         string
+	instanceID int
+	readyAt    time.Time
+}
+
+const kubeconfigTemplate = `apiVersion: v1
+kind: Config
+clusters:
+- name: lke%[1]d
+  cluster:
+    server: https://lke%[1]d.emulator.invalid:443
+    insecure-skip-tls-verify: true
+users:
+- name: lke%[1]d-admin
+  user:
+    token: emulator-token-%[1]d
+contexts:
+- name: lke%[1]d-ctx
+  context:
+    cluster: lke%[1]d
+    user: lke%[1]d-admin
+    namespace: default
+current-context: lke%[1]d-ctx
+`
+
+func (e *Emulator) now() time.Time {
+	return e.opts.Now()
+}
+
+// expire removes the deleted clusters, once the deletion delay passed. Must be
+// called with the lock held.
+func (e *Emulator) expire() {
+	now := e.now()
+
+	for id, c := range e.clusters {
+		if c.deletedAt.IsZero() || now.Sub(c.deletedAt) < e.opts.DeletionDelay {
+			continue
+		}
+
+		for _, p := range c.pools {
+			for _, n := range p.nodes {
+				delete(e.linodes, n.instanceID)
+			}
+		}
+
+		delete(e.clusters, id)
+	}
+}
+
+// lookupCluster returns the cluster from the path, or writes the error. Must be
+// called with the lock held.
+func (e *Emulator) lookupCluster(w http.ResponseWriter, r *http.Request) (*cluster, bool) {
+	e.expire()
+
+	id, err := strconv.Atoi(r.PathValue("clusterID"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not found")
+		return nil, false
+	}
+
+	c, ok := e.clusters[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return nil, false
+	}
+
+	return c, true
+}
+
+// lookupActiveCluster returns the cluster from the path, which is not being deleted.
+func (e *Emulator) lookupActiveCluster(w http.ResponseWriter, r *http.Request) (*cluster, bool) {
+	c, ok := e.lookupCluster(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	if !c.deletedAt.IsZero() {
+		writeError(w, http.StatusBadRequest, "Cluster is being deleted")
+		return nil, false
+	}
+
+	return c, true
+}
+
+func (c *cluster) lookupPool(w http.ResponseWriter, r *http.Request) (*pool, int, bool) {
+	id, err := strconv.Atoi(r.PathValue("poolID"))
+	if err == nil {
+		for i, p := range c.pools {
+			if p.ID == id {
+				return p, i, true
+			}
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "Not found")
+
+	return nil, 0, false
+}
+
+func (e *Emulator) renderCluster(c *cluster) linodego.LKECluster {
+	out := c.LKECluster
+	out.Tags = slices.Clone(c.Tags)
+	out.Status = linodego.LKEClusterNotReady
+
+	if c.deletedAt.IsZero() && !e.now().Before(c.readyAt) {
+		out.Status = linodego.LKEClusterReady
+	}
+
+	return out
+}
+
+func (e *Emulator) renderPool(p *pool) linodego.LKENodePool {
+	out := p.LKENodePool
+	out.Tags = slices.Clone(p.Tags)
+	out.Disks = slices.Clone(p.Disks)
+	out.Count = len(p.nodes)
+	out.Linodes = make([]linodego.LKENodePoolLinode, 0, len(p.nodes))
+
+	for _, n := range p.nodes {
+		status := linodego.LKELinodeNotReady
+		if !e.now().Before(n.readyAt) {
+			status = linodego.LKELinodeReady
+		}
+
+		out.Linodes = append(out.Linodes, linodego.LKENodePoolLinode{
+			ID:         n.id,
+			InstanceID: n.instanceID,
+			Status:     status,
+		})
+	}
+
+	return out
+}
+
+// addNode adds the node backed by the new Linode to the pool. Must be called with
+// the lock held.
+func (e *Emulator) addNode(c *cluster, p *pool) {
+	instanceID := e.id()
+	id := fmt.Sprintf("%d-%08x", p.ID, instanceID)
+
+	e.linodes[instanceID] = &linodego.Instance{
+		ID:     instanceID,
+		Label:  fmt.Sprintf("lke%d-%s", c.ID, id),
+		Region: c.Region,
+		Type:   p.Type,
+		Status: linodego.InstanceRunning,
+		Tags:   slices.Clone(p.Tags),
+	}
+
+	p.nodes = append(p.nodes, &node{
+		id:         id,
+		instanceID: instanceID,
+		readyAt:    e.now().Add(e.opts.ProvisioningDelay),
+	})
+}
+
+// removeNode removes the node and its Linode from the pool. Must be called with
+// the lock held.
+func (e *Emulator) removeNode(p *pool, i int) {
+	delete(e.linodes, p.nodes[i].instanceID)
+	p.nodes = slices.Delete(p.nodes, i, i+1)
+}
+
+// addPool adds the pool with the nodes to the cluster. Must be called with the
+// lock held.
+func (e *Emulator) addPool(c *cluster, opts linodego.LKENodePoolCreateOptions) *pool {
+	p := &pool{
+		LKENodePool: linodego.LKENodePool{
+			ID:    e.id(),
+			Type:  opts.Type,
+			Disks: opts.Disks,
+			Tags:  slices.Clone(opts.Tags),
+		},
+	}
+
+	if p.Tags == nil {
+		p.Tags = []string{}
+	}
+
+	if opts.Autoscaler != nil {
+		p.Autoscaler = *opts.Autoscaler
+	} else {
+		p.Autoscaler = linodego.LKENodePoolAutoscaler{Min: opts.Count, Max: opts.Count}
+	}
+
+	for range opts.Count {
+		e.addNode(c, p)
+	}
+
+	c.pools = append(c.pools, p)
+
+	return p
+}
+
+func validatePool(w http.ResponseWriter, field string, opts linodego.LKENodePoolCreateOptions) bool {
+	switch {
+	case opts.Type == "":
+		writeFieldError(w, field+".type", "type is required")
+	case opts.Count < 1 || opts.Count > 100:
+		writeFieldError(w, field+".count", "count must be between 1 and 100")
+	case opts.Autoscaler != nil && opts.Autoscaler.Enabled &&
+		(opts.Autoscaler.Min < 1 || opts.Autoscaler.Max < opts.Autoscaler.Min):
+		writeFieldError(w, field+".autoscaler", "invalid autoscaler bounds")
+	default:
+		return true
+	}
+
+	return false
+}
+
+func (e *Emulator) listVersions(w http.ResponseWriter, _ *http.Request) {
+	versions := make([]linodego.LKEVersion, 0, len(e.opts.Versions))
+	for _, v := range e.opts.Versions {
+		versions = append(versions, linodego.LKEVersion{ID: v})
+	}
+
+	writeList(w, versions)
+}
+
+func (e *Emulator) listClusters(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.expire()
+
+	clusters := make([]linodego.LKECluster, 0, len(e.clusters))
+	for _, c := range e.clusters {
+		clusters = append(clusters, e.renderCluster(c))
+	}
+
+	slices.SortFunc(clusters, func(a, b linodego.LKECluster) int {
+		return a.ID - b.ID
+	})
+
+	clusters, err = filterList(f, clusters)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeList(w, clusters)
+}
+
+func (e *Emulator) createCluster(w http.ResponseWriter, r *http.Request) {
+	opts := linodego.LKEClusterCreateOptions{}
+	if !readJSON(w, r, &opts) {
+		return
+	}
+
+	switch {
+	case opts.Label == "":
+		writeFieldError(w, "label", "label is required")
+		return
+	case opts.Region == "":
+		writeFieldError(w, "region", "region is required")
+		return
+	case !slices.Contains(e.opts.Versions, opts.K8sVersion):
+		writeFieldError(w, "k8s_version", "k8s_version is not supported")
+		return
+	case len(opts.NodePools) == 0:
+		writeFieldError(w, "node_pools", "at least one node pool is required")
+		return
+	}
+
+	for i, np := range opts.NodePools {
+		if !validatePool(w, fmt.Sprintf("node_pools[%d]", i), np) {
+			return
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+
+	c := &cluster{
+		LKECluster: linodego.LKECluster{
+			ID:         e.id(),
+			Created:    &now,
+			Updated:    &now,
+			Label:      opts.Label,
+			Region:     opts.Region,
+			K8sVersion: opts.K8sVersion,
+			Tags:       slices.Clone(opts.Tags),
+		},
+		readyAt: now.Add(e.opts.ProvisioningDelay),
+	}
+
+	if c.Tags == nil {
+		c.Tags = []string{}
+	}
+
+	if opts.ControlPlane != nil && opts.ControlPlane.HighAvailability != nil {
+		c.ControlPlane.HighAvailability = *opts.ControlPlane.HighAvailability
+	}
+
+	for _, np := range opts.NodePools {
+		e.addPool(c, np)
+	}
+
+	e.clusters[c.ID] = c
+
+	writeJSON(w, http.StatusOK, e.renderCluster(c))
+}
+
+func (e *Emulator) getCluster(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupCluster(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, e.renderCluster(c))
+}
+
+func (e *Emulator) updateCluster(w http.ResponseWriter, r *http.Request) {
+	opts := linodego.LKEClusterUpdateOptions{}
+	if !readJSON(w, r, &opts) {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupActiveCluster(w, r)
+	if !ok {
+		return
+	}
+
+	if opts.K8sVersion != "" && !slices.Contains(e.opts.Versions, opts.K8sVersion) {
+		writeFieldError(w, "k8s_version", "k8s_version is not supported")
+		return
+	}
+
+	if cp := opts.ControlPlane; cp != nil && cp.HighAvailability != nil &&
+		c.ControlPlane.HighAvailability && !*cp.HighAvailability {
+		writeFieldError(w, "control_plane.high_availability", "high availability cannot be disabled")
+		return
+	}
+
+	if opts.K8sVersion != "" {
+		c.K8sVersion = opts.K8sVersion
+	}
+
+	if opts.Label != "" {
+		c.Label = opts.Label
+	}
+
+	if opts.Tags != nil {
+		c.Tags = slices.Clone(*opts.Tags)
+	}
+
+	if cp := opts.ControlPlane; cp != nil && cp.HighAvailability != nil {
+		c.ControlPlane.HighAvailability = *cp.HighAvailability
+	}
+
+	now := e.now()
+	c.Updated = &now
+
+	writeJSON(w, http.StatusOK, e.renderCluster(c))
+}
+
+func (e *Emulator) deleteCluster(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupCluster(w, r)
+	if !ok {
+		return
+	}
+
+	if c.deletedAt.IsZero() {
+		c.deletedAt = e.now()
+	}
+
+	e.expire()
+
+	writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+func (e *Emulator) listAPIEndpoints(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupCluster(w, r)
+	if !ok {
+		return
+	}
+
+	writeList(w, []linodego.LKEClusterAPIEndpoint{{
+		Endpoint: fmt.Sprintf("https://lke%d.emulator.invalid:443", c.ID),
+	}})
+}
+
+func (e *Emulator) getKubeconfig(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupCluster(w, r)
+	if !ok {
+		return
+	}
+
+	if e.renderCluster(c).Status != linodego.LKEClusterReady {
+		writeError(w, http.StatusServiceUnavailable, "Cluster kubeconfig is not yet available")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, linodego.LKEClusterKubeconfig{
+		KubeConfig: base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf(kubeconfigTemplate, c.ID))),
+	})
+}
+
+func (e *Emulator) getDashboard(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupCluster(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, linodego.LKEClusterDashboard{
+		URL: fmt.Sprintf("https://lke%d.dashboard.emulator.invalid", c.ID),
+	})
+}
+
+func (e *Emulator) listPools(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupCluster(w, r)
+	if !ok {
+		return
+	}
+
+	pools := make([]linodego.LKENodePool, 0, len(c.pools))
+	for _, p := range c.pools {
+		pools = append(pools, e.renderPool(p))
+	}
+
+	writeList(w, pools)
+}
+
+func (e *Emulator) createPool(w http.ResponseWriter, r *http.Request) {
+	opts := linodego.LKENodePoolCreateOptions{}
+	if !readJSON(w, r, &opts) {
+		return
+	}
+
+	if !validatePool(w, "", opts) {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupActiveCluster(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, e.renderPool(e.addPool(c, opts)))
+}
+
+func (e *Emulator) getPool(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupCluster(w, r)
+	if !ok {
+		return
+	}
+
+	p, _, ok := c.lookupPool(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, e.renderPool(p))
+}
+
+func (e *Emulator) updatePool(w http.ResponseWriter, r *http.Request) {
+	opts := linodego.LKENodePoolUpdateOptions{}
+	if !readJSON(w, r, &opts) {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupActiveCluster(w, r)
+	if !ok {
+		return
+	}
+
+	p, _, ok := c.lookupPool(w, r)
+	if !ok {
+		return
+	}
+
+	if opts.Count < 0 || opts.Count > 100 {
+		writeFieldError(w, "count", "count must be between 1 and 100")
+		return
+	}
+
+	if opts.Count > 0 {
+		for len(p.nodes) < opts.Count {
+			e.addNode(c, p)
+		}
+
+		for len(p.nodes) > opts.Count {
+			e.removeNode(p, len(p.nodes)-1)
+		}
+	}
+
+	if opts.Tags != nil {
+		p.Tags = slices.Clone(*opts.Tags)
+	}
+
+	if opts.Autoscaler != nil {
+		p.Autoscaler = *opts.Autoscaler
+	}
+
+	writeJSON(w, http.StatusOK, e.renderPool(p))
+}
+
+func (e *Emulator) deletePool(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupActiveCluster(w, r)
+	if !ok {
+		return
+	}
+
+	p, i, ok := c.lookupPool(w, r)
+	if !ok {
+		return
+	}
+
+	for len(p.nodes) > 0 {
+		e.removeNode(p, 0)
+	}
+
+	c.pools = slices.Delete(c.pools, i, i+1)
+
+	writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+// recycleNode deletes the node, which is replaced by a new one, as the pool keeps
+// its node count.
+func (e *Emulator) recycleNode(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, ok := e.lookupActiveCluster(w, r)
+	if !ok {
+		return
+	}
+
+	nodeID := r.PathValue("nodeID")
+
+	for _, p := range c.pools {
+		for i, n := range p.nodes {
+			if n.id != nodeID {
+				continue
+			}
+
+			e.removeNode(p, i)
+			e.addNode(c, p)
+
+			writeJSON(w, http.StatusOK, map[string]any{})
+
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "Not found")
+}
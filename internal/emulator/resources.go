@@ -0,0 +1,206 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emulator
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/linode/linodego"
+)
+
+// Cluster returns the LKE cluster and its node pools, as returned by the API.
+func (e *Emulator) Cluster(id int) (linodego.LKECluster, []linodego.LKENodePool, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.expire()
+
+	c, ok := e.clusters[id]
+	if !ok {
+		return linodego.LKECluster{}, nil, false
+	}
+
+	pools := make([]linodego.LKENodePool, 0, len(c.pools))
+	for _, p := range c.pools {
+		pools = append(pools, e.renderPool(p))
+	}
+
+	return e.renderCluster(c), pools, true
+}
+
+// AddNodeBalancer adds the NodeBalancer, e.g. one created by the cloud controller
+// manager of the LKE cluster, and returns its ID.
+func (e *Emulator) AddNodeBalancer(label string, tags ...string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := e.id()
+	e.nodeBalancers[id] = &linodego.NodeBalancer{ID: id, Label: &label, Tags: tags}
+
+	return id
+}
+
+// AddVolume adds the Volume, e.g. one created by the CSI driver of the LKE
+// cluster, and returns its ID.
+func (e *Emulator) AddVolume(label string, tags ...string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := e.id()
+	e.volumes[id] = &linodego.Volume{ID: id, Label: label, Status: linodego.VolumeActive, Tags: tags}
+
+	return id
+}
+
+func sortedValues[T any](m map[int]*T, id func(*T) int) []T {
+	out := make([]T, 0, len(m))
+	for _, v := range m {
+		out = append(out, *v)
+	}
+
+	slices.SortFunc(out, func(a, b T) int {
+		return id(&a) - id(&b)
+	})
+
+	return out
+}
+
+func listFiltered[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	f, err := parseFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err = filterList(f, items)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeList(w, items)
+}
+
+func (e *Emulator) listLinodes(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	e.expire()
+	linodes := sortedValues(e.linodes, func(i *linodego.Instance) int { return i.ID })
+	e.mu.Unlock()
+
+	listFiltered(w, r, linodes)
+}
+
+func (e *Emulator) getLinode(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.expire()
+
+	id, _ := strconv.Atoi(r.PathValue("linodeID"))
+
+	linode, ok := e.linodes[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, linode)
+}
+
+func (e *Emulator) listNodeBalancers(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	nodeBalancers := sortedValues(e.nodeBalancers, func(nb *linodego.NodeBalancer) int { return nb.ID })
+	e.mu.Unlock()
+
+	listFiltered(w, r, nodeBalancers)
+}
+
+func (e *Emulator) deleteNodeBalancer(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id, _ := strconv.Atoi(r.PathValue("nodeBalancerID"))
+
+	if _, ok := e.nodeBalancers[id]; !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	delete(e.nodeBalancers, id)
+
+	writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+func (e *Emulator) listVolumes(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	volumes := sortedValues(e.volumes, func(v *linodego.Volume) int { return v.ID })
+	e.mu.Unlock()
+
+	listFiltered(w, r, volumes)
+}
+
+func (e *Emulator) deleteVolume(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id, _ := strconv.Atoi(r.PathValue("volumeID"))
+
+	if _, ok := e.volumes[id]; !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	delete(e.volumes, id)
+
+	writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+func (e *Emulator) getProfile(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, linodego.Profile{
+		UID:      1,
+		Username: "emulator",
+		Email:    "emulator@example.com",
+	})
+}
+
+// listTokens returns the token used for the request, with all scopes and without
+// the expiry. Only the prefix of the token is returned, as by the Linode API.
+func (e *Emulator) listTokens(w http.ResponseWriter, r *http.Request) {
+	prefix := token(r)
+	if len(prefix) > 16 {
+		prefix = prefix[:16]
+	}
+
+	writeList(w, []linodego.Token{{
+		ID:     1,
+		Label:  "emulator",
+		Scopes: "*",
+		Token:  prefix,
+	}})
+}
+
+func (e *Emulator) getGrants(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, linodego.GrantsListResponse{
+		Global: linodego.GlobalUserGrants{
+			AddLinodes:       true,
+			AddNodeBalancers: true,
+			AddVolumes:       true,
+		},
+	})
+}
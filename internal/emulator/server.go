@@ -0,0 +1,48 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emulator
+
+import (
+	"net/http/httptest"
+)
+
+// Server is the emulator listening on the local loopback interface.
+type Server struct {
+	*Emulator
+
+	server *httptest.Server
+}
+
+// NewServer starts the emulator. Server must be closed once no longer used.
+func NewServer(opts Options) *Server {
+	e := New(opts)
+
+	return &Server{
+		Emulator: e,
+		server:   httptest.NewServer(e),
+	}
+}
+
+// URL returns the URL of the emulated Linode API, including the API version.
+func (s *Server) URL() string {
+	return s.server.URL + "/" + APIVersion
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
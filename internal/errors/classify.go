@@ -0,0 +1,230 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
+// Class is the category of the error returned by the Linode API.
+type Class string
+
+const (
+	ClassUnknown      Class = "Unknown"
+	ClassNotFound     Class = "NotFound"
+	ClassUnauthorized Class = "Unauthorized"
+	ClassForbidden    Class = "Forbidden"
+	ClassRateLimited  Class = "RateLimited"
+	ClassValidation   Class = "Validation"
+	ClassUnavailable  Class = "Unavailable"
+	ClassConflict     Class = "Conflict"
+	ClassTransient    Class = "Transient"
+)
+
+// FieldReason is a single reason for rejecting the request, as returned by
+// the Linode API.
+type FieldReason struct {
+	// Field is the name of the invalid field. Empty if the reason is not
+	// related to any field.
+	Field string
+
+	// Reason is the human readable reason.
+	Reason string
+}
+
+// LinodeError is the classified error returned by the Linode API.
+type LinodeError struct {
+	Class Class
+
+	// Code is the HTTP status code of the response. Codes lower than 100
+	// identify errors raised before the response was received.
+	Code int
+
+	// Reasons lists the reasons for rejecting the request.
+	Reasons []FieldReason
+
+	// RetryAfter is the time after which the request may be retried, as
+	// requested by the Linode API. Zero if not provided.
+	RetryAfter time.Duration
+
+	err error
+}
+
+func (e *LinodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *LinodeError) Unwrap() error {
+	return e.err
+}
+
+// Terminal reports if retrying the request without changing it, or the
+// credentials used, cannot succeed.
+func (e *LinodeError) Terminal() bool {
+	switch e.Class {
+	case ClassUnauthorized, ClassForbidden, ClassValidation:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reason returns the CamelCase reason, usable in conditions and Events.
+func (e *LinodeError) Reason() string {
+	return "Linode" + string(e.Class)
+}
+
+// capacityHints are the fragments of reasons, returned by the Linode API when
+// the request is valid, but cannot be fulfilled right now.
+var capacityHints = []string{
+	"capacity",
+	"currently unavailable",
+	"not available",
+	"temporarily",
+}
+
+// Classify returns the classified Linode API error found in the chain of err.
+// Nil is returned if there is no Linode API error in the chain.
+func Classify(err error) *LinodeError {
+	if err == nil {
+		return nil
+	}
+
+	var classified *LinodeError
+	if errors.As(err, &classified) {
+		return classified
+	}
+
+	code, message, resp, ok := linodeError(err)
+	if !ok {
+		return nil
+	}
+
+	lerr := &LinodeError{
+		Class:   ClassUnknown,
+		Code:    code,
+		Reasons: parseReasons(message),
+		err:     err,
+	}
+
+	switch {
+	case code < 100:
+		lerr.Class = ClassTransient
+	case code == http.StatusUnauthorized:
+		lerr.Class = ClassUnauthorized
+	case code == http.StatusForbidden:
+		lerr.Class = ClassForbidden
+	case code == http.StatusNotFound:
+		lerr.Class = ClassNotFound
+	case code == http.StatusConflict:
+		lerr.Class = ClassConflict
+	case code == http.StatusTooManyRequests:
+		lerr.Class = ClassRateLimited
+	case code == http.StatusServiceUnavailable:
+		lerr.Class = ClassUnavailable
+	case code == http.StatusBadRequest, code == http.StatusUnprocessableEntity:
+		lerr.Class = ClassValidation
+		if hasCapacityHint(lerr.Reasons) {
+			lerr.Class = ClassUnavailable
+		}
+	case code >= http.StatusInternalServerError:
+		lerr.Class = ClassTransient
+	}
+
+	if resp != nil {
+		lerr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+
+	return lerr
+}
+
+func linodeError(err error) (int, string, *http.Response, bool) {
+	var perr *linodego.Error
+	if errors.As(err, &perr) && perr != nil {
+		return perr.Code, perr.Message, perr.Response, true
+	}
+
+	var verr linodego.Error
+	if errors.As(err, &verr) {
+		return verr.Code, verr.Message, verr.Response, true
+	}
+
+	return 0, "", nil, false
+}
+
+// parseReasons splits the message built by linodego from the list of reasons
+// in form of "[field] reason; reason".
+func parseReasons(message string) []FieldReason {
+	if message == "" {
+		return nil
+	}
+
+	parts := strings.Split(message, "; ")
+	reasons := make([]FieldReason, 0, len(parts))
+
+	for _, part := range parts {
+		reason := FieldReason{Reason: part}
+
+		if strings.HasPrefix(part, "[") {
+			if end := strings.Index(part, "] "); end > 0 {
+				reason.Field = part[1:end]
+				reason.Reason = part[end+2:]
+			}
+		}
+
+		reasons = append(reasons, reason)
+	}
+
+	return reasons
+}
+
+func hasCapacityHint(reasons []FieldReason) bool {
+	for _, r := range reasons {
+		reason := strings.ToLower(r.Reason)
+		for _, hint := range capacityHints {
+			if strings.Contains(reason, hint) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
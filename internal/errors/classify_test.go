@@ -0,0 +1,153 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	rateLimited := &http.Response{Header: http.Header{"Retry-After": []string{"30"}}}
+
+	for name, tc := range map[string]struct {
+		err                error
+		expectedNil        bool
+		expectedClass      Class
+		expectedTerminal   bool
+		expectedReasons    []FieldReason
+		expectedRetryAfter time.Duration
+	}{
+		"nil": {
+			expectedNil: true,
+		},
+		"not_linode": {
+			err:         errors.New("boom"),
+			expectedNil: true,
+		},
+		"unauthorized": {
+			err:              &linodego.Error{Code: http.StatusUnauthorized, Message: "Invalid Token"},
+			expectedClass:    ClassUnauthorized,
+			expectedTerminal: true,
+			expectedReasons:  []FieldReason{{Reason: "Invalid Token"}},
+		},
+		"forbidden_wrapped": {
+			err:              fmt.Errorf("failed to create cluster: %w", linodego.Error{Code: http.StatusForbidden}),
+			expectedClass:    ClassForbidden,
+			expectedTerminal: true,
+		},
+		"rate_limited": {
+			err:                &linodego.Error{Code: http.StatusTooManyRequests, Response: rateLimited},
+			expectedClass:      ClassRateLimited,
+			expectedRetryAfter: 30 * time.Second,
+		},
+		"validation": {
+			err: &linodego.Error{
+				Code:    http.StatusBadRequest,
+				Message: "[label] Label must be unique; [k8s_version] Invalid version",
+			},
+			expectedClass:    ClassValidation,
+			expectedTerminal: true,
+			expectedReasons: []FieldReason{
+				{Field: "label", Reason: "Label must be unique"},
+				{Field: "k8s_version", Reason: "Invalid version"},
+			},
+		},
+		"capacity": {
+			err: &linodego.Error{
+				Code:    http.StatusBadRequest,
+				Message: "[region] Region does not have capacity for this type",
+			},
+			expectedClass: ClassUnavailable,
+			expectedReasons: []FieldReason{
+				{Field: "region", Reason: "Region does not have capacity for this type"},
+			},
+		},
+		"unavailable": {
+			err:           ErrLinodeResourceNotAvailable,
+			expectedClass: ClassUnavailable,
+		},
+		"conflict": {
+			err:           &linodego.Error{Code: http.StatusConflict},
+			expectedClass: ClassConflict,
+		},
+		"server_error": {
+			err:           &linodego.Error{Code: http.StatusBadGateway},
+			expectedClass: ClassTransient,
+		},
+		"network_error": {
+			err:           linodego.NewError(errors.New("connection reset")),
+			expectedClass: ClassTransient,
+			expectedReasons: []FieldReason{
+				{Reason: "connection reset"},
+			},
+		},
+		"not_found": {
+			err:           ErrLinodeNotFound,
+			expectedClass: ClassNotFound,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lerr := Classify(tc.err)
+			if tc.expectedNil {
+				if lerr != nil {
+					t.Errorf("expected nil, got: %#+v", lerr)
+				}
+				return
+			}
+
+			if lerr == nil {
+				t.Fatalf("expected classified error, got nil")
+			}
+
+			if lerr.Class != tc.expectedClass {
+				t.Errorf("expected Class value: %#+v, got: %#+v",
+					tc.expectedClass, lerr.Class)
+			}
+
+			if lerr.Terminal() != tc.expectedTerminal {
+				t.Errorf("expected Terminal value: %#+v, got: %#+v",
+					tc.expectedTerminal, lerr.Terminal())
+			}
+
+			if !reflect.DeepEqual(lerr.Reasons, tc.expectedReasons) {
+				t.Errorf("expected Reasons value: %#+v, got: %#+v",
+					tc.expectedReasons, lerr.Reasons)
+			}
+
+			if lerr.RetryAfter != tc.expectedRetryAfter {
+				t.Errorf("expected RetryAfter value: %#+v, got: %#+v",
+					tc.expectedRetryAfter, lerr.RetryAfter)
+			}
+
+			if !errors.Is(lerr, tc.err) {
+				t.Errorf("expected classified error to wrap: %#+v", tc.err)
+			}
+		})
+	}
+}
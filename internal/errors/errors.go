@@ -18,7 +18,9 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/linode/linodego"
 )
@@ -26,10 +28,35 @@ import (
 var (
 	ErrNilSecret         = errors.New("secret is nil")
 	ErrTokenMissing      = errors.New("token is missing from secret")
+	ErrNoCredentials     = errors.New("no token reference provided and no default token configured")
 	ErrNoClusterID       = errors.New("no cluster ID")
 	ErrInvalidLKEVersion = errors.New("invalid LKE version from API")
 	ErrNotReady          = errors.New("not ready")
+	ErrCircuitOpen       = errors.New("circuit breaker is open")
+
+	ErrReferenceNotPermitted = errors.New("cross-namespace reference not permitted")
+	ErrInvalidLabel          = errors.New("invalid cluster label")
+	ErrClusterManaged        = errors.New("cluster is already managed by another object")
+	ErrLabelInUse            = errors.New("cluster label is already in use")
+
+	ErrTokenExpired       = errors.New("token has expired")
+	ErrTokenMissingScopes = errors.New("token is missing required scopes")
+	ErrTokenMissingGrants = errors.New("user is missing required grants")
 
 	ErrLinodeNotFound             = linodego.Error{Code: http.StatusNotFound}
 	ErrLinodeResourceNotAvailable = linodego.Error{Code: http.StatusServiceUnavailable}
 )
+
+// CircuitOpenError is returned when requests to the Linode API are rejected,
+// because the API returned too many server errors for the given token.
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrCircuitOpen, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
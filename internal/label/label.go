@@ -0,0 +1,126 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package label renders and validates the labels of LKE clusters.
+package label
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+)
+
+const (
+	// DefaultTemplate is the template used by objects not setting the label,
+	// if no other default is configured. The namespace is included, as the label
+	// must be unique within the Linode account.
+	DefaultTemplate = "{{ .Namespace }}-{{ .Name }}"
+
+	// MaxLength is the maximum length of the LKE cluster label.
+	MaxLength = 32
+)
+
+var labelPattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._-]*[a-zA-Z0-9])?$`)
+
+// Vars are the variables available in the label template.
+type Vars struct {
+	// Namespace of the LKEClusterConfig.
+	Namespace string
+
+	// Name of the LKEClusterConfig.
+	Name string
+
+	// Prefix configured for the whole operator.
+	Prefix string
+}
+
+// Template renders the labels of LKE clusters.
+type Template struct {
+	// Prefix is exposed to the templates as the Prefix variable.
+	Prefix string
+
+	// Default is the template used by objects not setting the label.
+	// Defaults to DefaultTemplate.
+	Default string
+}
+
+// KeepsExisting returns true if the label of the existing LKE cluster managed by
+// the object is kept instead of the rendered one, as neither the object sets the
+// label, nor the default template is configured. The clusters created before
+// DefaultTemplate included the namespace are thereby not renamed.
+func (t Template) KeepsExisting(lke *v1alpha1.LKEClusterConfig) bool {
+	return lke.Spec.Label == "" && t.Default == "" && lke.Status.ClusterID != nil
+}
+
+// Render returns the label of the LKE cluster managed by the object.
+func (t Template) Render(lke *v1alpha1.LKEClusterConfig) (string, error) {
+	text := lke.Spec.Label
+	if text == "" {
+		text = t.Default
+	}
+
+	if text == "" {
+		text = DefaultTemplate
+	}
+
+	tmpl, err := template.New("label").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to parse template: %w", internalerrors.ErrInvalidLabel, err)
+	}
+
+	sb := &strings.Builder{}
+	if err := tmpl.Execute(sb, Vars{
+		Namespace: lke.Namespace,
+		Name:      lke.Name,
+		Prefix:    t.Prefix,
+	}); err != nil {
+		return "", fmt.Errorf("%w: failed to render template: %w", internalerrors.ErrInvalidLabel, err)
+	}
+
+	label := sb.String()
+	if err := Validate(label); err != nil {
+		return "", err
+	}
+
+	return label, nil
+}
+
+// Validate checks the label against the rules of the Linode API: it must be
+// 1 to 32 characters long, contain only alphanumeric characters, dashes,
+// underscores and periods, begin and end with an alphanumeric character, and
+// must not contain two dashes, underscores or periods in a row.
+func Validate(label string) error {
+	switch {
+	case label == "":
+		return fmt.Errorf("%w: label is empty", internalerrors.ErrInvalidLabel)
+	case len(label) > MaxLength:
+		return fmt.Errorf("%w: label %q is longer than %d characters",
+			internalerrors.ErrInvalidLabel, label, MaxLength)
+	case !labelPattern.MatchString(label):
+		return fmt.Errorf("%w: label %q must consist of alphanumeric characters, '-', '_' or '.', "+
+			"and must begin and end with an alphanumeric character",
+			internalerrors.ErrInvalidLabel, label)
+	case strings.Contains(label, "--"), strings.Contains(label, "__"), strings.Contains(label, ".."):
+		return fmt.Errorf("%w: label %q must not contain two '-', '_' or '.' in a row",
+			internalerrors.ErrInvalidLabel, label)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package label
+
+import (
+	"errors"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		template      Template
+		label         string
+		expectedLabel string
+		expectedError error
+	}{
+		"default": {
+			expectedLabel: "prod-cluster",
+		},
+		"operator_default": {
+			template:      Template{Prefix: "acme", Default: "{{ .Prefix }}-{{ .Namespace }}-{{ .Name }}"},
+			expectedLabel: "acme-prod-cluster",
+		},
+		"spec": {
+			template:      Template{Default: "{{ .Namespace }}-{{ .Name }}"},
+			label:         "{{ .Name }}_{{ .Namespace }}",
+			expectedLabel: "cluster_prod",
+		},
+		"static": {
+			label:         "my.cluster",
+			expectedLabel: "my.cluster",
+		},
+		"unknown_variable": {
+			label:         "{{ .Region }}",
+			expectedError: internalerrors.ErrInvalidLabel,
+		},
+		"malformed": {
+			label:         "{{ .Name",
+			expectedError: internalerrors.ErrInvalidLabel,
+		},
+		"empty_prefix": {
+			label:         "{{ .Prefix }}-{{ .Name }}",
+			expectedError: internalerrors.ErrInvalidLabel,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lke := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: v1.ObjectMeta{Namespace: "prod", Name: "cluster"},
+				Spec:       v1alpha1.LKEClusterConfigSpec{Label: tc.label},
+			}
+
+			label, err := tc.template.Render(lke)
+			if !errors.Is(err, tc.expectedError) {
+				t.Errorf("expected Error value: %#+v, got: %#+v", tc.expectedError, err)
+			}
+
+			if label != tc.expectedLabel {
+				t.Errorf("expected Label value: %#+v, got: %#+v", tc.expectedLabel, label)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		label string
+		valid bool
+	}{
+		"valid":                 {label: "prod-cluster_1.eu", valid: true},
+		"single_character":      {label: "a", valid: true},
+		"max_length":            {label: "abcdefghijklmnopqrstuvwxyz012345", valid: true},
+		"empty":                 {label: ""},
+		"too_long":              {label: "abcdefghijklmnopqrstuvwxyz0123456"},
+		"leading_dash":          {label: "-cluster"},
+		"trailing_period":       {label: "cluster."},
+		"invalid_character":     {label: "prod/cluster"},
+		"consecutive_dashes":    {label: "prod--cluster"},
+		"consecutive_periods":   {label: "prod..cluster"},
+		"consecutive_underline": {label: "prod__cluster"},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := Validate(tc.label)
+			if tc.valid != (err == nil) {
+				t.Errorf("expected Valid value: %#+v, got error: %v", tc.valid, err)
+			}
+
+			if err != nil && !errors.Is(err, internalerrors.ErrInvalidLabel) {
+				t.Errorf("expected Error value: %#+v, got: %#+v", internalerrors.ErrInvalidLabel, err)
+			}
+		})
+	}
+}
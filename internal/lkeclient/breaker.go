@@ -0,0 +1,113 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lkeclient
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/linode/linodego"
+)
+
+// CircuitBreaker rejects requests after a number of consecutive server errors,
+// until the cooldown period passes.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// NewCircuitBreaker returns CircuitBreaker that opens after threshold consecutive
+// server errors. Threshold lower than 1 disables the breaker.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow returns internalerrors.CircuitOpenError if the breaker is open.
+func (b *CircuitBreaker) Allow() error {
+	if b == nil || b.threshold < 1 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now := b.now(); now.Before(b.openUntil) {
+		return &internalerrors.CircuitOpenError{RetryAfter: b.openUntil.Sub(now)}
+	}
+
+	return nil
+}
+
+// Record updates the breaker state with the result of a request. Only server
+// errors (5xx) are counted as failures, any other result closes the breaker.
+func (b *CircuitBreaker) Record(err error) {
+	if b == nil || b.threshold < 1 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isServerError(err) {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+func isServerError(err error) bool {
+	code, ok := errorCode(err)
+	return ok && code >= http.StatusInternalServerError
+}
+
+func isServiceUnavailable(err error) bool {
+	code, ok := errorCode(err)
+	return ok && code == http.StatusServiceUnavailable
+}
+
+// errorCode returns the HTTP status code of the Linode API error.
+func errorCode(err error) (int, bool) {
+	var (
+		ptr *linodego.Error
+		val linodego.Error
+	)
+
+	switch {
+	case errors.As(err, &ptr):
+		return ptr.Code, true
+	case errors.As(err, &val):
+		return val.Code, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lkeclient
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/linode/linodego"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		serverErr   = &linodego.Error{Code: http.StatusServiceUnavailable}
+		notFoundErr = &linodego.Error{Code: http.StatusNotFound}
+	)
+
+	for name, tc := range map[string]struct {
+		threshold   int
+		results     []error
+		elapsed     time.Duration
+		expectedErr error
+	}{
+		"closed": {
+			threshold:   3,
+			results:     []error{serverErr, serverErr},
+			expectedErr: nil,
+		},
+		"open": {
+			threshold:   3,
+			results:     []error{serverErr, serverErr, serverErr},
+			expectedErr: internalerrors.ErrCircuitOpen,
+		},
+		"reset_by_success": {
+			threshold:   3,
+			results:     []error{serverErr, serverErr, nil, serverErr},
+			expectedErr: nil,
+		},
+		"reset_by_client_error": {
+			threshold:   3,
+			results:     []error{serverErr, serverErr, notFoundErr, serverErr},
+			expectedErr: nil,
+		},
+		"value_error": {
+			threshold:   1,
+			results:     []error{linodego.Error{Code: http.StatusBadGateway}},
+			expectedErr: internalerrors.ErrCircuitOpen,
+		},
+		"cooldown_passed": {
+			threshold:   1,
+			results:     []error{serverErr},
+			elapsed:     2 * time.Minute,
+			expectedErr: nil,
+		},
+		"disabled": {
+			threshold:   0,
+			results:     []error{serverErr, serverErr, serverErr},
+			expectedErr: nil,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Now()
+
+			b := NewCircuitBreaker(tc.threshold, time.Minute)
+			b.now = func() time.Time { return now }
+
+			for _, err := range tc.results {
+				b.Record(err)
+			}
+
+			now = now.Add(tc.elapsed)
+
+			err := b.Allow()
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected Error value: %#+v, got: %#+v",
+					tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestGuardExpecting(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		err         error
+		expectedErr error
+	}{
+		"expected": {
+			err:         &linodego.Error{Code: http.StatusServiceUnavailable},
+			expectedErr: nil,
+		},
+		"unexpected": {
+			err:         &linodego.Error{Code: http.StatusInternalServerError},
+			expectedErr: internalerrors.ErrCircuitOpen,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b := NewCircuitBreaker(1, time.Minute)
+
+			_, _ = guardExpecting(b, isServiceUnavailable, func() (struct{}, error) {
+				return struct{}{}, tc.err
+			})
+
+			err := b.Allow()
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected Error value: %#+v, got: %#+v",
+					tc.expectedErr, err)
+			}
+		})
+	}
+}
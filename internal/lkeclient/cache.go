@@ -0,0 +1,213 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lkeclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego"
+	"golang.org/x/time/rate"
+)
+
+// Key identifies the source of a token.
+type Key struct {
+	// ID is a stable identifier of the token source, e.g. UID of the Secret.
+	// Client is shared by all reconciliations using the same ID, while the rate
+	// limiter and the circuit breaker are shared by all clients using the same token
+	// and endpoint, i.e. the same Linode account.
+	ID string
+
+	// Version changes whenever the token might have changed, e.g. resourceVersion
+	// of the Secret. Client is rebuilt each time the Version changes.
+	Version string
+}
+
+// CacheOptions configures rate limiting and circuit breaking applied to each token.
+type CacheOptions struct {
+	// UserAgent is the User-Agent header sent with each request. If empty,
+	// DefaultUserAgent is used.
+	UserAgent string
+
+	// Endpoint configures the URL, version, proxy and CA bundle of the Linode API
+	// used by default. Fields set in the Endpoint passed to Get take precedence.
+	Endpoint Endpoint
+
+	// RateLimit is the number of requests per second allowed for a single token.
+	RateLimit rate.Limit
+
+	// Burst is the maximum number of requests sent at once for a single token.
+	Burst int
+
+	// FailureThreshold is the number of consecutive server errors after which
+	// all requests using the token are rejected. Zero disables the circuit breaker.
+	FailureThreshold int
+
+	// Cooldown is the time for which requests are rejected once the failure
+	// threshold is reached.
+	Cooldown time.Duration
+
+	// IdleTimeout is the time after which the state of a token not used by any
+	// reconciliation is dropped, e.g. after its Secret was deleted or its endpoint
+	// changed. Zero keeps the state forever.
+	IdleTimeout time.Duration
+
+	// Logger is used by all clients. Clients are shared between objects, so the
+	// logger must not carry values of a single object.
+	Logger linodego.Logger
+}
+
+// DefaultCacheOptions returns CacheOptions used when none are provided.
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		RateLimit:        5,
+		Burst:            10,
+		FailureThreshold: 5,
+		Cooldown:         time.Minute,
+		IdleTimeout:      time.Hour,
+	}
+}
+
+// Cache shares Linode clients between reconciliations of all objects using
+// the same token.
+type Cache struct {
+	opts CacheOptions
+	now  func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]*cacheEntry
+	accounts map[string]*accountEntry
+}
+
+type cacheEntry struct {
+	version  string
+	account  string
+	lastUsed time.Time
+	client   Client
+}
+
+// accountEntry is the state shared by all clients using the same token and endpoint.
+type accountEntry struct {
+	lastUsed time.Time
+	limiter  *rate.Limiter
+	breaker  *CircuitBreaker
+}
+
+// NewCache returns empty Cache.
+func NewCache(opts CacheOptions) *Cache {
+	return &Cache{
+		opts:     opts,
+		now:      time.Now,
+		entries:  make(map[string]*cacheEntry),
+		accounts: make(map[string]*accountEntry),
+	}
+}
+
+// Get returns the client for the key, building a new one if the key is seen for
+// the first time, or its version has changed. State of the tokens idle for longer
+// than the IdleTimeout is dropped.
+func (c *Cache) Get(key Key, token string, opts ...Option) Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.expire(now)
+
+	opts = append([]Option{WithEndpoint(c.opts.Endpoint), WithLogger(c.opts.Logger)}, opts...)
+
+	accountKey := c.accountKey(token, opts)
+
+	account, ok := c.accounts[accountKey]
+	if !ok {
+		account = &accountEntry{
+			limiter: rate.NewLimiter(c.opts.RateLimit, c.opts.Burst),
+			breaker: NewCircuitBreaker(c.opts.FailureThreshold, c.opts.Cooldown),
+		}
+		c.accounts[accountKey] = account
+	}
+
+	account.lastUsed = now
+
+	entry, ok := c.entries[key.ID]
+	if !ok {
+		entry = &cacheEntry{}
+		c.entries[key.ID] = entry
+	}
+
+	entry.lastUsed = now
+
+	if entry.client == nil || entry.version != key.Version || entry.account != accountKey {
+		opts = append(opts, withRateLimiter(account.limiter))
+
+		ua := c.opts.UserAgent
+		if ua == "" {
+			ua = DefaultUserAgent()
+		}
+
+		entry.version = key.Version
+		entry.account = accountKey
+		entry.client = &guardedClient{
+			base:    New(token, ua, opts...),
+			breaker: account.breaker,
+		}
+	}
+
+	return entry.client
+}
+
+// accountKey returns the key of the state shared by the clients using the token
+// with the endpoint resulting from the options.
+func (c *Cache) accountKey(token string, opts []Option) string {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:]) + "@" + o.endpoint.Digest()
+}
+
+// expire removes the entries not used since the IdleTimeout. Caller must hold the lock.
+func (c *Cache) expire(now time.Time) {
+	if c.opts.IdleTimeout <= 0 {
+		return
+	}
+
+	for id, entry := range c.entries {
+		if now.Sub(entry.lastUsed) > c.opts.IdleTimeout {
+			delete(c.entries, id)
+		}
+	}
+
+	for key, account := range c.accounts {
+		if now.Sub(account.lastUsed) > c.opts.IdleTimeout {
+			delete(c.accounts, key)
+		}
+	}
+}
+
+// Forget removes the client associated with the ID. The state shared with other
+// clients using the same token is dropped once idle.
+func (c *Cache) Forget(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, id)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lkeclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_Get(t *testing.T) {
+	t.Parallel()
+
+	cache := NewCache(DefaultCacheOptions())
+
+	var (
+		first   = cache.Get(Key{ID: "a", Version: "1"}, "token")
+		same    = cache.Get(Key{ID: "a", Version: "1"}, "token")
+		resaved = cache.Get(Key{ID: "a", Version: "2"}, "token")
+		bumped  = cache.Get(Key{ID: "a", Version: "3"}, "rotated")
+	)
+
+	if first != same {
+		t.Errorf("expected client to be reused for the same key")
+	}
+
+	if first == resaved || resaved == bumped {
+		t.Errorf("expected client to be rebuilt after version change")
+	}
+
+	if first.(*guardedClient).breaker != resaved.(*guardedClient).breaker {
+		t.Errorf("expected circuit breaker to be shared between versions of the same token")
+	}
+
+	if first.(*guardedClient).breaker == bumped.(*guardedClient).breaker {
+		t.Errorf("expected circuit breaker not to be shared between tokens")
+	}
+
+	cache.Forget("a")
+
+	if forgotten := cache.Get(Key{ID: "a", Version: "2"}, "rotated"); forgotten == bumped {
+		t.Errorf("expected client to be rebuilt after Forget")
+	}
+}
+
+func TestCache_Get_sharedToken(t *testing.T) {
+	t.Parallel()
+
+	// the burst is not refilled
+	opts := DefaultCacheOptions()
+	opts.RateLimit = 0
+
+	cache := NewCache(opts)
+
+	// the same token copied into Secrets in different namespaces
+	var (
+		first    = cache.Get(Key{ID: "secret-a/token", Version: "1"}, "token")
+		second   = cache.Get(Key{ID: "secret-b/token", Version: "1"}, "token")
+		endpoint = cache.Get(Key{ID: "secret-c/token@other", Version: "1"}, "token",
+			WithEndpoint(Endpoint{URL: "https://linode.example.com"}))
+	)
+
+	if first == second {
+		t.Errorf("expected separate clients for different keys")
+	}
+
+	if first.(*guardedClient).breaker != second.(*guardedClient).breaker {
+		t.Errorf("expected circuit breaker to be shared by keys with the same token")
+	}
+
+	// requests of the first key exhaust the burst of the second one
+	limiter := cache.accounts[cache.entries["secret-a/token"].account].limiter
+	if !limiter.AllowN(time.Now(), opts.Burst) {
+		t.Fatalf("expected burst to be available")
+	}
+
+	if cache.accounts[cache.entries["secret-b/token"].account].limiter.Allow() {
+		t.Errorf("expected rate limiter to be shared by keys with the same token")
+	}
+
+	if first.(*guardedClient).breaker == endpoint.(*guardedClient).breaker {
+		t.Errorf("expected circuit breaker not to be shared between endpoints")
+	}
+}
+
+func TestCache_Get_idle(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	cache := NewCache(DefaultCacheOptions())
+	cache.now = func() time.Time { return now }
+
+	var (
+		idle = cache.Get(Key{ID: "a", Version: "1"}, "token")
+		used = cache.Get(Key{ID: "b", Version: "1"}, "token")
+	)
+
+	now = now.Add(30 * time.Minute)
+	cache.Get(Key{ID: "b", Version: "1"}, "token")
+
+	now = now.Add(45 * time.Minute)
+
+	if got := cache.Get(Key{ID: "b", Version: "1"}, "token"); got != used {
+		t.Errorf("expected client used within the idle timeout to be kept")
+	}
+
+	if got := cache.Get(Key{ID: "a", Version: "1"}, "token"); got == idle {
+		t.Errorf("expected idle client to be dropped")
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cassette records the HTTP traffic of the Linode client into cassettes,
+// and replays them offline, so the recorded responses can be reproduced exactly
+// in regression tests. The cassettes are only as faithful as the API they were
+// recorded against. Credentials and kubeconfigs are redacted before the
+// interactions are stored.
+package cassette
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Redacted replaces the values of the sensitive headers.
+const Redacted = "REDACTED"
+
+// RedactedKubeconfig replaces the kubeconfig returned by the Linode API.
+const RedactedKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: redacted
+  cluster:
+    server: https://redacted.invalid:443
+contexts:
+- name: redacted
+  context:
+    cluster: redacted
+    user: redacted
+current-context: redacted
+users:
+- name: redacted
+  user:
+    token: REDACTED
+`
+
+// redactedHeaders are the request headers carrying credentials.
+var redactedHeaders = []string{"Authorization"}
+
+// Cassette is the ordered list of recorded interactions with the Linode API.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is the single request and the response returned to it.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded HTTP request.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is the recorded HTTP response.
+type Response struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Load reads the cassette from the YAML file.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	c := &Cassette{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Save writes the cassette to the YAML file, creating its directory if needed.
+func (c *Cassette) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// matches reports whether the recorded request matches the request with the body.
+// Requests match by the method, path, query, filter and body, so the cassettes
+// recorded against one API URL are replayed against any other.
+func (r *Request) matches(req *http.Request, body []byte) bool {
+	recorded, err := url.Parse(r.URL)
+	if err != nil {
+		return false
+	}
+
+	return r.Method == req.Method &&
+		recorded.Path == req.URL.Path &&
+		recorded.Query().Encode() == req.URL.Query().Encode() &&
+		r.Headers.Get("X-Filter") == req.Header.Get("X-Filter") &&
+		bodiesMatch(r.Body, string(body))
+}
+
+// bodiesMatch reports whether the request bodies are equal. JSON bodies are compared
+// by their values, ignoring the order of the object keys and the whitespace.
+func bodiesMatch(recorded, body string) bool {
+	if recorded == body {
+		return true
+	}
+
+	var a, b any
+	if json.Unmarshal([]byte(recorded), &a) != nil || json.Unmarshal([]byte(body), &b) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// redact returns the interaction without the credentials and kubeconfigs.
+func redact(i Interaction) Interaction {
+	i.Request.Headers = i.Request.Headers.Clone()
+	for _, h := range redactedHeaders {
+		if i.Request.Headers.Get(h) != "" {
+			i.Request.Headers.Set(h, Redacted)
+		}
+	}
+
+	if strings.HasSuffix(i.Request.URL, "/kubeconfig") && i.Response.Status == http.StatusOK {
+		i.Response.Body = redactKubeconfig(i.Response.Body)
+	}
+
+	return i
+}
+
+// redactKubeconfig replaces the kubeconfig in the response body, keeping all
+// other fields of the response.
+func redactKubeconfig(body string) string {
+	fields := map[string]any{}
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		// never store the payload which could not be redacted
+		return ""
+	}
+
+	fields["kubeconfig"] = base64.StdEncoding.EncodeToString([]byte(RedactedKubeconfig))
+
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+
+	return string(out)
+}
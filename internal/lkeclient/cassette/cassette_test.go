@@ -0,0 +1,264 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cassette
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const secretKubeconfig = "apiVersion: v1\nusers:\n- name: admin\n  user:\n    token: secret\n"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	calls := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/kubeconfig"):
+			fmt.Fprintf(w, `{"kubeconfig":%q}`, base64.StdEncoding.EncodeToString([]byte(secretKubeconfig)))
+		case r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(body)
+		default:
+			fmt.Fprintf(w, `{"call":%d}`, calls)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func do(t *testing.T, rt http.RoundTripper, method, url, body string) (int, string, error) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret-token")
+
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+
+	return resp.StatusCode, string(out), nil
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t)
+	rec := NewRecorder(nil)
+
+	_, body, err := do(t, rec, http.MethodGet, srv.URL+"/v4/lke/clusters/1/kubeconfig", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(body, base64.StdEncoding.EncodeToString([]byte(secretKubeconfig))) {
+		t.Errorf("expected unredacted kubeconfig returned to the caller, got: %s", body)
+	}
+
+	if _, _, err := do(t, rec, http.MethodPost, srv.URL+"/v4/lke/clusters", `{"label":"test"}`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := rec.Cassette()
+	if len(c.Interactions) != 2 {
+		t.Fatalf("expected 2 interactions, got: %d", len(c.Interactions))
+	}
+
+	for _, i := range c.Interactions {
+		if auth := i.Request.Headers.Get("Authorization"); auth != Redacted {
+			t.Errorf("expected Authorization value: %#+v, got: %#+v", Redacted, auth)
+		}
+
+		if strings.Contains(i.Response.Body, base64.StdEncoding.EncodeToString([]byte(secretKubeconfig))) {
+			t.Errorf("expected kubeconfig to be redacted, got: %s", i.Response.Body)
+		}
+	}
+
+	kubeconfig := struct {
+		KubeConfig string `json:"kubeconfig"`
+	}{}
+	if err := json.Unmarshal([]byte(c.Interactions[0].Response.Body), &kubeconfig); err != nil {
+		t.Fatalf("failed to parse redacted response: %v", err)
+	}
+
+	if decoded, _ := base64.StdEncoding.DecodeString(kubeconfig.KubeConfig); string(decoded) != RedactedKubeconfig {
+		t.Errorf("expected RedactedKubeconfig, got: %s", decoded)
+	}
+
+	if body := c.Interactions[1].Request.Body; body != `{"label":"test"}` {
+		t.Errorf("expected request Body value: %#+v, got: %#+v", `{"label":"test"}`, body)
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t)
+	rec := NewRecorder(nil)
+
+	for range 2 {
+		if _, _, err := do(t, rec, http.MethodGet, srv.URL+"/v4/lke/clusters/1", ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "cassettes", "replay.yaml")
+	if err := rec.Cassette().Save(path); err != nil {
+		t.Fatalf("failed to save cassette: %v", err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+
+	rep := NewReplayer(c)
+
+	// replayed against another host, in the recorded order
+	for _, expected := range []string{`{"call":1}`, `{"call":2}`} {
+		status, body, err := do(t, rep, http.MethodGet, "https://api.linode.com/v4/lke/clusters/1", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if status != http.StatusOK || body != expected {
+			t.Errorf("expected response value: %#+v, got: %d %#+v", expected, status, body)
+		}
+	}
+
+	_, _, err = do(t, rep, http.MethodGet, "https://api.linode.com/v4/lke/clusters/1", "")
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction, got: %v", err)
+	}
+
+	if unused := rep.Unused(); len(unused) != 0 {
+		t.Errorf("expected no unused interactions, got: %#+v", unused)
+	}
+}
+
+func TestRequest_matches(t *testing.T) {
+	t.Parallel()
+
+	recorded := Request{
+		Method:  http.MethodPut,
+		URL:     "https://api.linode.com/v4/lke/clusters/1?page=1",
+		Headers: http.Header{"X-Filter": []string{`{"label":"test"}`}},
+		Body:    `{"label":"test","tags":["a","b"]}`,
+	}
+
+	for name, tc := range map[string]struct {
+		method   string
+		url      string
+		filter   string
+		body     string
+		expected bool
+	}{
+		"match": {
+			method:   http.MethodPut,
+			url:      "http://127.0.0.1:8080/v4/lke/clusters/1?page=1",
+			filter:   `{"label":"test"}`,
+			body:     `{"label":"test","tags":["a","b"]}`,
+			expected: true,
+		},
+		"body_key_order": {
+			method:   http.MethodPut,
+			url:      "http://127.0.0.1:8080/v4/lke/clusters/1?page=1",
+			filter:   `{"label":"test"}`,
+			body:     `{"tags": ["a", "b"], "label": "test"}`,
+			expected: true,
+		},
+		"method": {
+			method: http.MethodPost,
+			url:    "http://127.0.0.1:8080/v4/lke/clusters/1?page=1",
+			filter: `{"label":"test"}`,
+			body:   `{"label":"test","tags":["a","b"]}`,
+		},
+		"path": {
+			method: http.MethodPut,
+			url:    "http://127.0.0.1:8080/v4/lke/clusters/2?page=1",
+			filter: `{"label":"test"}`,
+			body:   `{"label":"test","tags":["a","b"]}`,
+		},
+		"query": {
+			method: http.MethodPut,
+			url:    "http://127.0.0.1:8080/v4/lke/clusters/1?page=2",
+			filter: `{"label":"test"}`,
+			body:   `{"label":"test","tags":["a","b"]}`,
+		},
+		"filter": {
+			method: http.MethodPut,
+			url:    "http://127.0.0.1:8080/v4/lke/clusters/1?page=1",
+			filter: `{"label":"other"}`,
+			body:   `{"label":"test","tags":["a","b"]}`,
+		},
+		"body": {
+			method: http.MethodPut,
+			url:    "http://127.0.0.1:8080/v4/lke/clusters/1?page=1",
+			filter: `{"label":"test"}`,
+			body:   `{"label":"test","tags":["b","a"]}`,
+		},
+		"no_body": {
+			method: http.MethodPut,
+			url:    "http://127.0.0.1:8080/v4/lke/clusters/1?page=1",
+			filter: `{"label":"test"}`,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			req.Header.Set("X-Filter", tc.filter)
+
+			if actual := recorded.matches(req, []byte(tc.body)); actual != tc.expected {
+				t.Errorf("expected value: %#+v, got: %#+v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cassette
+
+import (
+	"net/http"
+	"os"
+	"testing"
+)
+
+// RecordEnv is the environment variable enabling the recording of the cassettes
+// in tests, e.g. LKE_OPERATOR_RECORD=1 go test ./internal/controller/...
+const RecordEnv = "LKE_OPERATOR_RECORD"
+
+// Recording reports whether the tests record the cassettes instead of replaying them.
+func Recording() bool {
+	return os.Getenv(RecordEnv) != ""
+}
+
+// Transport returns the transport replaying the cassette at the path, and fails
+// the test if any of its interactions is not replayed. When Recording, the
+// requests are sent with the base transport instead, and the cassette is saved
+// at the end of the passed test.
+func Transport(t testing.TB, path string, base http.RoundTripper) http.RoundTripper {
+	t.Helper()
+
+	if Recording() {
+		rec := NewRecorder(base)
+
+		t.Cleanup(func() {
+			if t.Failed() {
+				t.Logf("not saving cassette %s of the failed test", path)
+				return
+			}
+
+			if err := rec.Cassette().Save(path); err != nil {
+				t.Errorf("failed to save cassette: %v", err)
+			}
+		})
+
+		return rec
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load cassette, record it with %s=1: %v", RecordEnv, err)
+	}
+
+	rep := NewReplayer(c)
+
+	t.Cleanup(func() {
+		if unused := rep.Unused(); len(unused) > 0 {
+			t.Errorf("%d interactions of cassette %s were not replayed, first: %s %s",
+				len(unused), path, unused[0].Request.Method, unused[0].Request.URL)
+		}
+	})
+
+	return rep
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// ErrNoInteraction is returned by the Replayer for requests not found in the cassette.
+var ErrNoInteraction = errors.New("no matching interaction in cassette")
+
+// Recorder is the transport recording the interactions of the base transport.
+// Responses are returned to the caller unchanged, only the recorded copies are
+// redacted.
+type Recorder struct {
+	base http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+var _ http.RoundTripper = (*Recorder)(nil)
+
+// NewRecorder returns the Recorder sending the requests with the base transport,
+// or http.DefaultTransport if the base is nil.
+func NewRecorder(base http.RoundTripper) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Recorder{base: base}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+
+	if req.Body != nil {
+		var err error
+
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := redact(Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: req.Header,
+			Body:    string(reqBody),
+		},
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: resp.Header.Clone(),
+			Body:    string(respBody),
+		},
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, interaction)
+
+	return resp, nil
+}
+
+// Cassette returns the cassette of the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Cassette{Interactions: slices.Clone(r.interactions)}
+}
+
+// Replayer is the transport answering the requests from the cassette. Each
+// request is answered by the first matching interaction, which was not replayed
+// yet, so the repeated requests, e.g. polling for the cluster status, get the
+// responses in the recorded order.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+var _ http.RoundTripper = (*Replayer)(nil)
+
+// NewReplayer returns the Replayer of the cassette.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{
+		interactions: slices.Clone(c.Interactions),
+		replayed:     make([]bool, len(c.Interactions)),
+	}
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.interactions {
+		if r.replayed[i] || !r.interactions[i].Request.matches(req, body) {
+			continue
+		}
+
+		r.replayed[i] = true
+		recorded := r.interactions[i].Response
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+			StatusCode:    recorded.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Headers.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+}
+
+// Unused returns the interactions which were not replayed.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unused := []Interaction{}
+
+	for i, interaction := range r.interactions {
+		if !r.replayed[i] {
+			unused = append(unused, interaction)
+		}
+	}
+
+	return unused
+}
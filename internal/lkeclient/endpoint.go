@@ -0,0 +1,158 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lkeclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Endpoint configures how the Linode API is reached. Empty fields keep the
+// linodego defaults.
+type Endpoint struct {
+	// URL is the base URL of the Linode API, e.g. https://api.linode.com. It may
+	// include the API version, e.g. http://127.0.0.1:8080/v4.
+	URL string
+
+	// Version is the version of the Linode API, e.g. v4beta. It takes precedence
+	// over the version included in the URL.
+	Version string
+
+	// Proxy is the URL of the HTTP(S) proxy. If empty, the proxy is taken from the
+	// HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string
+
+	// CA is the PEM encoded bundle of CA certificates trusted in addition to the
+	// system ones.
+	CA []byte
+}
+
+// IsZero checks if the endpoint keeps all defaults.
+func (e Endpoint) IsZero() bool {
+	return e.URL == "" && e.Version == "" && e.Proxy == "" && len(e.CA) == 0
+}
+
+// Merge returns the endpoint with the fields set in the override taking precedence.
+func (e Endpoint) Merge(override Endpoint) Endpoint {
+	if override.URL != "" {
+		e.URL = override.URL
+	}
+
+	if override.Version != "" {
+		e.Version = override.Version
+	}
+
+	if override.Proxy != "" {
+		e.Proxy = override.Proxy
+	}
+
+	if len(override.CA) != 0 {
+		e.CA = override.CA
+	}
+
+	return e
+}
+
+// Digest returns the hash identifying the endpoint.
+func (e Endpoint) Digest() string {
+	sum := sha256.New()
+	for _, field := range [][]byte{[]byte(e.URL), []byte(e.Version), []byte(e.Proxy), e.CA} {
+		_, _ = fmt.Fprintf(sum, "%d:%s;", len(field), field)
+	}
+
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// Validate checks if the URLs are absolute and the CA bundle contains certificates.
+func (e Endpoint) Validate() error {
+	var errs []error
+
+	if e.URL != "" {
+		if _, err := parseAbsoluteURL(e.URL); err != nil {
+			errs = append(errs, fmt.Errorf("invalid API URL: %w", err))
+		}
+	}
+
+	if e.Proxy != "" {
+		if _, err := parseAbsoluteURL(e.Proxy); err != nil {
+			errs = append(errs, fmt.Errorf("invalid proxy URL: %w", err))
+		}
+	}
+
+	if len(e.CA) != 0 {
+		if !x509.NewCertPool().AppendCertsFromPEM(e.CA) {
+			errs = append(errs, errors.New("invalid CA bundle: no PEM encoded certificates found"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func parseAbsoluteURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%q must be an absolute URL", s)
+	}
+
+	return u, nil
+}
+
+// transport returns the HTTP transport reaching the endpoint, instrumented with
+// OpenTelemetry. Invalid proxy URL fails each request, and invalid CA bundle
+// trusts no certificates, so the misconfiguration never falls back to defaults.
+func (e Endpoint) transport() http.RoundTripper {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if e.Proxy != "" {
+		proxy, err := parseAbsoluteURL(e.Proxy)
+		t.Proxy = func(*http.Request) (*url.URL, error) {
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy URL: %w", err)
+			}
+
+			return proxy, nil
+		}
+	}
+
+	if len(e.CA) != 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || !x509.NewCertPool().AppendCertsFromPEM(e.CA) {
+			pool = x509.NewCertPool()
+		}
+
+		pool.AppendCertsFromPEM(e.CA)
+
+		t.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    pool,
+		}
+	}
+
+	return otelhttp.NewTransport(t)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lkeclient
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/linode/linodego"
+)
+
+func TestEndpoint_Validate(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		endpoint    Endpoint
+		expectedErr bool
+	}{
+		"zero": {
+			endpoint: Endpoint{},
+		},
+		"valid": {
+			endpoint: Endpoint{URL: "https://api.linode.com/v4", Version: "v4beta", Proxy: "http://proxy:3128"},
+		},
+		"relative_url": {
+			endpoint:    Endpoint{URL: "api.linode.com"},
+			expectedErr: true,
+		},
+		"relative_proxy": {
+			endpoint:    Endpoint{Proxy: "proxy:3128"},
+			expectedErr: true,
+		},
+		"invalid_ca": {
+			endpoint:    Endpoint{CA: []byte("not a certificate")},
+			expectedErr: true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tc.endpoint.Validate(); (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %#+v, got: %#+v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestEndpoint_Merge(t *testing.T) {
+	t.Parallel()
+
+	defaults := Endpoint{URL: "https://api.linode.com", Proxy: "http://proxy:3128"}
+	merged := defaults.Merge(Endpoint{Version: "v4beta", Proxy: "http://other:3128"})
+
+	expected := Endpoint{URL: "https://api.linode.com", Version: "v4beta", Proxy: "http://other:3128"}
+	if merged.Digest() != expected.Digest() {
+		t.Errorf("expected Endpoint value: %#+v, got: %#+v", expected, merged)
+	}
+}
+
+func profileHandler(t *testing.T, requests *atomic.Int32) http.HandlerFunc {
+	t.Helper()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.URL.Path != "/v4beta/profile" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(linodego.Profile{Username: "test"})
+	}
+}
+
+func TestNew_CA(t *testing.T) {
+	t.Parallel()
+
+	requests := &atomic.Int32{}
+
+	srv := httptest.NewTLSServer(profileHandler(t, requests))
+	t.Cleanup(srv.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	for name, tc := range map[string]struct {
+		ca          []byte
+		expectedErr bool
+	}{
+		"trusted": {
+			ca: ca,
+		},
+		"untrusted": {
+			expectedErr: true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := New("token", "test", WithEndpoint(Endpoint{
+				URL:     srv.URL,
+				Version: "v4beta",
+				CA:      tc.ca,
+			}))
+			client.SetRetryCount(0)
+
+			_, err := client.GetProfile(context.Background())
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %#+v, got: %#+v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestNew_Proxy(t *testing.T) {
+	t.Parallel()
+
+	requests := &atomic.Int32{}
+
+	// plain HTTP proxy receives the requests with the absolute URL, and serves
+	// them itself, so the API host does not need to resolve
+	proxy := httptest.NewServer(profileHandler(t, requests))
+	t.Cleanup(proxy.Close)
+
+	client := New("token", "test", WithEndpoint(Endpoint{
+		URL:     "http://api.linode.invalid/v4beta",
+		Proxy:   proxy.URL,
+		Version: "v4beta",
+	}))
+	client.SetRetryCount(0)
+
+	profile, err := client.GetProfile(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if profile.Username != "test" || requests.Load() != 1 {
+		t.Errorf("expected the request to be sent through the proxy, got: %#+v", profile)
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake implements an in-memory lkeclient.Client for unit tests. Each call
+// is recorded, and may be answered by the scripted reactors, e.g. to inject errors,
+// before falling through to the in-memory state of the LKE clusters and node pools.
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/linode/linodego"
+
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+// AnyMethod matches calls to all methods of the Client.
+const AnyMethod = "*"
+
+// Call is the recorded call to the Client.
+type Call struct {
+	// Method is the name of the called method, e.g. GetLKECluster.
+	Method string
+
+	// Args are the arguments of the call, without the context.
+	Args []any
+}
+
+// Reactor scripts the response to the call. If handled is false, the call falls
+// through to the next reactor, and finally to the in-memory state. The returned
+// value must have the type returned by the method, or be nil.
+type Reactor func(call Call) (handled bool, ret any, err error)
+
+type reactor struct {
+	method string
+	fn     Reactor
+}
+
+// Client is the in-memory lkeclient.Client. Filters of the list options are
+// ignored, so all resources are always returned. Zero value is not usable,
+// use NewClient instead.
+type Client struct {
+	mu sync.Mutex
+
+	nextID        int
+	versions      []linodego.LKEVersion
+	clusters      map[int]*linodego.LKECluster
+	pools         map[int][]*linodego.LKENodePool
+	kubeconfigs   map[int]string
+	nodeBalancers []linodego.NodeBalancer
+	volumes       []linodego.Volume
+	profile       linodego.Profile
+	tokens        []linodego.Token
+	grants        linodego.GrantsListResponse
+
+	reactors []reactor
+	calls    []Call
+}
+
+var _ lkeclient.Client = (*Client)(nil)
+
+// NewClient returns the Client without any LKE clusters, offering Kubernetes 1.30.
+func NewClient() *Client {
+	return &Client{
+		nextID:      1,
+		versions:    []linodego.LKEVersion{{ID: "1.30"}},
+		clusters:    make(map[int]*linodego.LKECluster),
+		pools:       make(map[int][]*linodego.LKENodePool),
+		kubeconfigs: make(map[int]string),
+		profile:     linodego.Profile{Username: "fake"},
+	}
+}
+
+// NotFound returns the error returned by the Linode API for missing resources.
+func NotFound() error {
+	return &linodego.Error{Code: http.StatusNotFound, Message: "Not found"}
+}
+
+// Unavailable returns the error returned by the Linode API for resources which
+// are not available yet, e.g. kubeconfig of the provisioning cluster.
+func Unavailable() error {
+	return &linodego.Error{Code: http.StatusServiceUnavailable, Message: "Service unavailable"}
+}
+
+// PrependReactor adds the reactor for calls to the method, or AnyMethod, taking
+// precedence over the reactors added before.
+func (c *Client) PrependReactor(method string, fn Reactor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reactors = append([]reactor{{method: method, fn: fn}}, c.reactors...)
+}
+
+// InjectError makes the next times calls to the method, or AnyMethod, fail with
+// the error. Zero times fails all calls.
+func (c *Client) InjectError(method string, err error, times int) {
+	var (
+		mu        sync.Mutex
+		remaining = times
+	)
+
+	c.PrependReactor(method, func(Call) (bool, any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if times > 0 {
+			if remaining == 0 {
+				return false, nil, nil
+			}
+
+			remaining--
+		}
+
+		return true, nil, err
+	})
+}
+
+// Calls returns the recorded calls, in the order they were made.
+func (c *Client) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return slices.Clone(c.calls)
+}
+
+// Methods returns the names of the called methods, in the order they were called.
+func (c *Client) Methods() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	methods := make([]string, 0, len(c.calls))
+	for _, call := range c.calls {
+		methods = append(methods, call.Method)
+	}
+
+	return methods
+}
+
+// CallsTo returns the recorded calls to the method.
+func (c *Client) CallsTo(method string) []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	calls := []Call{}
+
+	for _, call := range c.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// ResetCalls forgets the recorded calls.
+func (c *Client) ResetCalls() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = nil
+}
+
+// handle records the call and returns the response of the first reactor handling
+// it, or of the fallback, which is called with the lock held.
+func handle[T any](c *Client, method string, args []any, fallback func() (T, error)) (T, error) {
+	var zero T
+
+	call := Call{Method: method, Args: args}
+
+	c.mu.Lock()
+	c.calls = append(c.calls, call)
+	reactors := slices.Clone(c.reactors)
+	c.mu.Unlock()
+
+	for _, r := range reactors {
+		if r.method != AnyMethod && r.method != method {
+			continue
+		}
+
+		handled, ret, err := r.fn(call)
+		if !handled {
+			continue
+		}
+
+		if ret == nil {
+			return zero, err
+		}
+
+		out, ok := ret.(T)
+		if !ok {
+			return zero, fmt.Errorf("fake: reactor for %s returned %T, expected %T", method, ret, zero)
+		}
+
+		return out, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return fallback()
+}
+
+// handleErr is handle for the methods returning only the error.
+func handleErr(c *Client, method string, args []any, fallback func() error) error {
+	_, err := handle(c, method, args, func() (struct{}, error) {
+		return struct{}{}, fallback()
+	})
+
+	return err
+}
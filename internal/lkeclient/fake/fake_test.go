@@ -0,0 +1,148 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/linode/linodego"
+
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+)
+
+func TestClient_InjectError(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		method   string
+		times    int
+		expected []bool
+	}{
+		"once": {
+			method:   "GetLKECluster",
+			times:    1,
+			expected: []bool{true, false, false},
+		},
+		"always": {
+			method:   "GetLKECluster",
+			expected: []bool{true, true, true},
+		},
+		"any_method": {
+			method:   AnyMethod,
+			times:    2,
+			expected: []bool{true, true, false},
+		},
+		"other_method": {
+			method:   "DeleteLKECluster",
+			expected: []bool{false, false, false},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewClient()
+			id := c.AddCluster(linodego.LKECluster{Label: "test"})
+
+			c.InjectError(tc.method, NotFound(), tc.times)
+
+			failed := []bool{}
+
+			for range tc.expected {
+				_, err := c.GetLKECluster(context.Background(), id)
+				failed = append(failed, errors.Is(err, internalerrors.ErrLinodeNotFound))
+			}
+
+			if !reflect.DeepEqual(failed, tc.expected) {
+				t.Errorf("expected Failed value: %#+v, got: %#+v", tc.expected, failed)
+			}
+
+			if calls := len(c.CallsTo("GetLKECluster")); calls != len(tc.expected) {
+				t.Errorf("expected Calls value: %#+v, got: %#+v", len(tc.expected), calls)
+			}
+		})
+	}
+}
+
+func TestClient_PrependReactor(t *testing.T) {
+	t.Parallel()
+
+	c := NewClient()
+
+	c.PrependReactor("ListLKEVersions", func(Call) (bool, any, error) {
+		return true, []linodego.LKEVersion{{ID: "1.31"}}, nil
+	})
+
+	versions, err := c.ListLKEVersions(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(versions) != 1 || versions[0].ID != "1.31" {
+		t.Errorf("expected scripted versions, got: %#+v", versions)
+	}
+
+	c.PrependReactor("GetProfile", func(Call) (bool, any, error) {
+		return true, "unexpected", nil
+	})
+
+	if _, err := c.GetProfile(context.Background()); err == nil {
+		t.Errorf("expected error for the mismatched type")
+	}
+}
+
+func TestClient_NodePools(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := NewClient()
+
+	cluster, err := c.CreateLKECluster(ctx, linodego.LKEClusterCreateOptions{
+		Label:     "test",
+		NodePools: []linodego.LKENodePoolCreateOptions{{Type: "g6-standard-1", Count: 1}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pools := c.NodePools(cluster.ID)
+
+	pool, err := c.UpdateLKENodePool(ctx, cluster.ID, pools[0].ID, linodego.LKENodePoolUpdateOptions{Count: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pool.Count != 3 || len(pool.Linodes) != 3 {
+		t.Errorf("expected 3 nodes, got: %#+v", pool)
+	}
+
+	if err := c.DeleteLKENodePool(ctx, cluster.ID, pool.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := c.DeleteLKENodePool(ctx, cluster.ID, pool.ID); !errors.Is(err, internalerrors.ErrLinodeNotFound) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+
+	expected := []string{"CreateLKECluster", "UpdateLKENodePool", "DeleteLKENodePool", "DeleteLKENodePool"}
+	if methods := c.Methods(); !reflect.DeepEqual(methods, expected) {
+		t.Errorf("expected Methods value: %#+v, got: %#+v", expected, methods)
+	}
+}
@@ -0,0 +1,482 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/linode/linodego"
+)
+
+// SetVersions sets the Kubernetes versions offered for the LKE clusters.
+func (c *Client) SetVersions(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.versions = make([]linodego.LKEVersion, 0, len(ids))
+	for _, id := range ids {
+		c.versions = append(c.versions, linodego.LKEVersion{ID: id})
+	}
+}
+
+// AddCluster adds the LKE cluster with the node pools, and returns its ID. IDs of
+// the cluster and the node pools are assigned if not set, and the nodes are created
+// to match the pool count. Empty cluster status defaults to ready.
+func (c *Client) AddCluster(cluster linodego.LKECluster, pools ...linodego.LKENodePool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cluster.ID == 0 {
+		cluster.ID = c.id()
+	}
+
+	if cluster.Status == "" {
+		cluster.Status = linodego.LKEClusterReady
+	}
+
+	cluster.Tags = slices.Clone(cluster.Tags)
+	c.clusters[cluster.ID] = &cluster
+	c.kubeconfigs[cluster.ID] = kubeconfig(cluster.ID)
+
+	for _, pool := range pools {
+		if pool.ID == 0 {
+			pool.ID = c.id()
+		}
+
+		pool.Tags = slices.Clone(pool.Tags)
+		pool.Linodes = nil
+
+		c.resize(&pool, pool.Count, linodego.LKELinodeStatus(cluster.Status))
+		c.pools[cluster.ID] = append(c.pools[cluster.ID], &pool)
+	}
+
+	return cluster.ID
+}
+
+// Cluster returns the copy of the LKE cluster.
+func (c *Client) Cluster(id int) (linodego.LKECluster, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cluster, ok := c.clusters[id]
+	if !ok {
+		return linodego.LKECluster{}, false
+	}
+
+	return copyCluster(cluster), true
+}
+
+// Clusters returns the copies of all LKE clusters, ordered by their IDs. Unlike
+// ListLKEClusters, the call is neither recorded nor passed to the reactors.
+func (c *Client) Clusters() []linodego.LKECluster {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clusters := make([]linodego.LKECluster, 0, len(c.clusters))
+	for _, cluster := range c.clusters {
+		clusters = append(clusters, copyCluster(cluster))
+	}
+
+	slices.SortFunc(clusters, func(a, b linodego.LKECluster) int { return a.ID - b.ID })
+
+	return clusters
+}
+
+// NodePools returns the copies of the node pools of the LKE cluster.
+func (c *Client) NodePools(clusterID int) []linodego.LKENodePool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.listPools(clusterID)
+}
+
+// SetStatus sets the status of the LKE cluster and all its nodes. Kubeconfig
+// of the cluster is unavailable until it is ready.
+func (c *Client) SetStatus(clusterID int, status linodego.LKEClusterStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cluster, ok := c.clusters[clusterID]
+	if !ok {
+		return
+	}
+
+	cluster.Status = status
+
+	for _, pool := range c.pools[clusterID] {
+		for i := range pool.Linodes {
+			pool.Linodes[i].Status = linodego.LKELinodeStatus(status)
+		}
+	}
+}
+
+// SetKubeconfig sets the kubeconfig returned for the LKE cluster.
+func (c *Client) SetKubeconfig(clusterID int, kubeconfig string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.kubeconfigs[clusterID] = kubeconfig
+}
+
+// id returns the next unique ID. Must be called with the lock held.
+func (c *Client) id() int {
+	id := c.nextID
+	c.nextID++
+
+	return id
+}
+
+func kubeconfig(clusterID int) string {
+	return fmt.Sprintf("kubeconfig of LKE cluster %d", clusterID)
+}
+
+func copyCluster(cluster *linodego.LKECluster) linodego.LKECluster {
+	out := *cluster
+	out.Tags = slices.Clone(cluster.Tags)
+
+	return out
+}
+
+func copyPool(pool *linodego.LKENodePool) linodego.LKENodePool {
+	out := *pool
+	out.Tags = slices.Clone(pool.Tags)
+	out.Linodes = slices.Clone(pool.Linodes)
+
+	return out
+}
+
+// resize adds or removes the nodes to match the count. Must be called with the lock held.
+func (c *Client) resize(pool *linodego.LKENodePool, count int, status linodego.LKELinodeStatus) {
+	for len(pool.Linodes) < count {
+		id := c.id()
+		pool.Linodes = append(pool.Linodes, linodego.LKENodePoolLinode{
+			ID:         fmt.Sprintf("%d-%d", pool.ID, id),
+			InstanceID: id,
+			Status:     status,
+		})
+	}
+
+	pool.Linodes = pool.Linodes[:count]
+	pool.Count = count
+}
+
+// listPools must be called with the lock held.
+func (c *Client) listPools(clusterID int) []linodego.LKENodePool {
+	pools := make([]linodego.LKENodePool, 0, len(c.pools[clusterID]))
+	for _, pool := range c.pools[clusterID] {
+		pools = append(pools, copyPool(pool))
+	}
+
+	return pools
+}
+
+// lookupPool must be called with the lock held.
+func (c *Client) lookupPool(clusterID, poolID int) (*linodego.LKENodePool, int, error) {
+	if _, ok := c.clusters[clusterID]; !ok {
+		return nil, 0, NotFound()
+	}
+
+	for i, pool := range c.pools[clusterID] {
+		if pool.ID == poolID {
+			return pool, i, nil
+		}
+	}
+
+	return nil, 0, NotFound()
+}
+
+// createPool must be called with the lock held.
+func (c *Client) createPool(cluster *linodego.LKECluster, opts linodego.LKENodePoolCreateOptions) *linodego.LKENodePool {
+	pool := &linodego.LKENodePool{
+		ID:    c.id(),
+		Type:  opts.Type,
+		Disks: opts.Disks,
+		Tags:  slices.Clone(opts.Tags),
+	}
+
+	if pool.Tags == nil {
+		pool.Tags = []string{}
+	}
+
+	if opts.Autoscaler != nil {
+		pool.Autoscaler = *opts.Autoscaler
+	}
+
+	c.resize(pool, opts.Count, linodego.LKELinodeStatus(cluster.Status))
+	c.pools[cluster.ID] = append(c.pools[cluster.ID], pool)
+
+	return pool
+}
+
+func (c *Client) ListLKEVersions(_ context.Context, opts *linodego.ListOptions) ([]linodego.LKEVersion, error) {
+	return handle(c, "ListLKEVersions", []any{opts}, func() ([]linodego.LKEVersion, error) {
+		return slices.Clone(c.versions), nil
+	})
+}
+
+func (c *Client) ListLKEClusterAPIEndpoints(
+	_ context.Context,
+	clusterID int,
+	opts *linodego.ListOptions,
+) ([]linodego.LKEClusterAPIEndpoint, error) {
+	return handle(c, "ListLKEClusterAPIEndpoints", []any{clusterID, opts}, func() ([]linodego.LKEClusterAPIEndpoint, error) {
+		if _, ok := c.clusters[clusterID]; !ok {
+			return nil, NotFound()
+		}
+
+		return []linodego.LKEClusterAPIEndpoint{
+			{Endpoint: fmt.Sprintf("https://lke%d.fake.invalid:443", clusterID)},
+		}, nil
+	})
+}
+
+func (c *Client) ListLKEClusters(_ context.Context, opts *linodego.ListOptions) ([]linodego.LKECluster, error) {
+	return handle(c, "ListLKEClusters", []any{opts}, func() ([]linodego.LKECluster, error) {
+		clusters := make([]linodego.LKECluster, 0, len(c.clusters))
+		for _, cluster := range c.clusters {
+			clusters = append(clusters, copyCluster(cluster))
+		}
+
+		slices.SortFunc(clusters, func(a, b linodego.LKECluster) int {
+			return a.ID - b.ID
+		})
+
+		return clusters, nil
+	})
+}
+
+func (c *Client) GetLKECluster(_ context.Context, clusterID int) (*linodego.LKECluster, error) {
+	return handle(c, "GetLKECluster", []any{clusterID}, func() (*linodego.LKECluster, error) {
+		cluster, ok := c.clusters[clusterID]
+		if !ok {
+			return nil, NotFound()
+		}
+
+		out := copyCluster(cluster)
+
+		return &out, nil
+	})
+}
+
+func (c *Client) CreateLKECluster(_ context.Context, opts linodego.LKEClusterCreateOptions) (*linodego.LKECluster, error) {
+	return handle(c, "CreateLKECluster", []any{opts}, func() (*linodego.LKECluster, error) {
+		cluster := &linodego.LKECluster{
+			ID:         c.id(),
+			Label:      opts.Label,
+			Region:     opts.Region,
+			K8sVersion: opts.K8sVersion,
+			Status:     linodego.LKEClusterReady,
+			Tags:       slices.Clone(opts.Tags),
+		}
+
+		if cluster.Tags == nil {
+			cluster.Tags = []string{}
+		}
+
+		if opts.ControlPlane != nil && opts.ControlPlane.HighAvailability != nil {
+			cluster.ControlPlane.HighAvailability = *opts.ControlPlane.HighAvailability
+		}
+
+		c.clusters[cluster.ID] = cluster
+		c.kubeconfigs[cluster.ID] = kubeconfig(cluster.ID)
+
+		for _, np := range opts.NodePools {
+			c.createPool(cluster, np)
+		}
+
+		out := copyCluster(cluster)
+
+		return &out, nil
+	})
+}
+
+func (c *Client) UpdateLKECluster(
+	_ context.Context,
+	clusterID int,
+	opts linodego.LKEClusterUpdateOptions,
+) (*linodego.LKECluster, error) {
+	return handle(c, "UpdateLKECluster", []any{clusterID, opts}, func() (*linodego.LKECluster, error) {
+		cluster, ok := c.clusters[clusterID]
+		if !ok {
+			return nil, NotFound()
+		}
+
+		if opts.Label != "" {
+			cluster.Label = opts.Label
+		}
+
+		if opts.K8sVersion != "" {
+			cluster.K8sVersion = opts.K8sVersion
+		}
+
+		if opts.Tags != nil {
+			cluster.Tags = slices.Clone(*opts.Tags)
+		}
+
+		if cp := opts.ControlPlane; cp != nil && cp.HighAvailability != nil {
+			cluster.ControlPlane.HighAvailability = *cp.HighAvailability
+		}
+
+		out := copyCluster(cluster)
+
+		return &out, nil
+	})
+}
+
+func (c *Client) DeleteLKECluster(_ context.Context, clusterID int) error {
+	return handleErr(c, "DeleteLKECluster", []any{clusterID}, func() error {
+		if _, ok := c.clusters[clusterID]; !ok {
+			return NotFound()
+		}
+
+		delete(c.clusters, clusterID)
+		delete(c.pools, clusterID)
+		delete(c.kubeconfigs, clusterID)
+
+		return nil
+	})
+}
+
+func (c *Client) GetLKEClusterKubeconfig(_ context.Context, clusterID int) (*linodego.LKEClusterKubeconfig, error) {
+	return handle(c, "GetLKEClusterKubeconfig", []any{clusterID}, func() (*linodego.LKEClusterKubeconfig, error) {
+		cluster, ok := c.clusters[clusterID]
+		if !ok {
+			return nil, NotFound()
+		}
+
+		if cluster.Status != linodego.LKEClusterReady {
+			return nil, Unavailable()
+		}
+
+		return &linodego.LKEClusterKubeconfig{KubeConfig: c.kubeconfigs[clusterID]}, nil
+	})
+}
+
+func (c *Client) GetLKEClusterDashboard(_ context.Context, clusterID int) (*linodego.LKEClusterDashboard, error) {
+	return handle(c, "GetLKEClusterDashboard", []any{clusterID}, func() (*linodego.LKEClusterDashboard, error) {
+		if _, ok := c.clusters[clusterID]; !ok {
+			return nil, NotFound()
+		}
+
+		return &linodego.LKEClusterDashboard{
+			URL: fmt.Sprintf("https://lke%d.dashboard.fake.invalid", clusterID),
+		}, nil
+	})
+}
+
+func (c *Client) ListLKENodePools(
+	_ context.Context,
+	clusterID int,
+	opts *linodego.ListOptions,
+) ([]linodego.LKENodePool, error) {
+	return handle(c, "ListLKENodePools", []any{clusterID, opts}, func() ([]linodego.LKENodePool, error) {
+		if _, ok := c.clusters[clusterID]; !ok {
+			return nil, NotFound()
+		}
+
+		return c.listPools(clusterID), nil
+	})
+}
+
+func (c *Client) CreateLKENodePool(
+	_ context.Context,
+	clusterID int,
+	opts linodego.LKENodePoolCreateOptions,
+) (*linodego.LKENodePool, error) {
+	return handle(c, "CreateLKENodePool", []any{clusterID, opts}, func() (*linodego.LKENodePool, error) {
+		cluster, ok := c.clusters[clusterID]
+		if !ok {
+			return nil, NotFound()
+		}
+
+		out := copyPool(c.createPool(cluster, opts))
+
+		return &out, nil
+	})
+}
+
+func (c *Client) UpdateLKENodePool(
+	_ context.Context,
+	clusterID, poolID int,
+	opts linodego.LKENodePoolUpdateOptions,
+) (*linodego.LKENodePool, error) {
+	return handle(c, "UpdateLKENodePool", []any{clusterID, poolID, opts}, func() (*linodego.LKENodePool, error) {
+		pool, _, err := c.lookupPool(clusterID, poolID)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.Count > 0 {
+			c.resize(pool, opts.Count, linodego.LKELinodeStatus(c.clusters[clusterID].Status))
+		}
+
+		if opts.Tags != nil {
+			pool.Tags = slices.Clone(*opts.Tags)
+		}
+
+		if opts.Autoscaler != nil {
+			pool.Autoscaler = *opts.Autoscaler
+		}
+
+		out := copyPool(pool)
+
+		return &out, nil
+	})
+}
+
+func (c *Client) DeleteLKENodePool(_ context.Context, clusterID, poolID int) error {
+	return handleErr(c, "DeleteLKENodePool", []any{clusterID, poolID}, func() error {
+		_, i, err := c.lookupPool(clusterID, poolID)
+		if err != nil {
+			return err
+		}
+
+		c.pools[clusterID] = slices.Delete(c.pools[clusterID], i, i+1)
+
+		return nil
+	})
+}
+
+// DeleteLKENodePoolNode recycles the node, replacing it with the new one.
+func (c *Client) DeleteLKENodePoolNode(_ context.Context, clusterID int, nodeID string) error {
+	return handleErr(c, "DeleteLKENodePoolNode", []any{clusterID, nodeID}, func() error {
+		if _, ok := c.clusters[clusterID]; !ok {
+			return NotFound()
+		}
+
+		for _, pool := range c.pools[clusterID] {
+			for i, node := range pool.Linodes {
+				if node.ID != nodeID {
+					continue
+				}
+
+				id := c.id()
+				pool.Linodes[i] = linodego.LKENodePoolLinode{
+					ID:         fmt.Sprintf("%d-%d", pool.ID, id),
+					InstanceID: id,
+					Status:     linodego.LKELinodeNotReady,
+				}
+
+				return nil
+			}
+		}
+
+		return NotFound()
+	})
+}
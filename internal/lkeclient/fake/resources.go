@@ -0,0 +1,114 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"slices"
+
+	"github.com/linode/linodego"
+)
+
+// AddNodeBalancer adds the NodeBalancer and returns its ID.
+func (c *Client) AddNodeBalancer(label string, tags ...string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.id()
+	c.nodeBalancers = append(c.nodeBalancers, linodego.NodeBalancer{ID: id, Label: &label, Tags: tags})
+
+	return id
+}
+
+// AddVolume adds the Volume and returns its ID.
+func (c *Client) AddVolume(label string, tags ...string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.id()
+	c.volumes = append(c.volumes, linodego.Volume{ID: id, Label: label, Tags: tags})
+
+	return id
+}
+
+// SetProfile sets the profile, tokens and grants of the user owning the token.
+func (c *Client) SetProfile(profile linodego.Profile, tokens []linodego.Token, grants linodego.GrantsListResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.profile = profile
+	c.tokens = slices.Clone(tokens)
+	c.grants = grants
+}
+
+func (c *Client) ListNodeBalancers(_ context.Context, opts *linodego.ListOptions) ([]linodego.NodeBalancer, error) {
+	return handle(c, "ListNodeBalancers", []any{opts}, func() ([]linodego.NodeBalancer, error) {
+		return slices.Clone(c.nodeBalancers), nil
+	})
+}
+
+func (c *Client) DeleteNodeBalancer(_ context.Context, nodebalancerID int) error {
+	return handleErr(c, "DeleteNodeBalancer", []any{nodebalancerID}, func() error {
+		i := slices.IndexFunc(c.nodeBalancers, func(nb linodego.NodeBalancer) bool { return nb.ID == nodebalancerID })
+		if i < 0 {
+			return NotFound()
+		}
+
+		c.nodeBalancers = slices.Delete(c.nodeBalancers, i, i+1)
+
+		return nil
+	})
+}
+
+func (c *Client) ListVolumes(_ context.Context, opts *linodego.ListOptions) ([]linodego.Volume, error) {
+	return handle(c, "ListVolumes", []any{opts}, func() ([]linodego.Volume, error) {
+		return slices.Clone(c.volumes), nil
+	})
+}
+
+func (c *Client) DeleteVolume(_ context.Context, volumeID int) error {
+	return handleErr(c, "DeleteVolume", []any{volumeID}, func() error {
+		i := slices.IndexFunc(c.volumes, func(v linodego.Volume) bool { return v.ID == volumeID })
+		if i < 0 {
+			return NotFound()
+		}
+
+		c.volumes = slices.Delete(c.volumes, i, i+1)
+
+		return nil
+	})
+}
+
+func (c *Client) GetProfile(context.Context) (*linodego.Profile, error) {
+	return handle(c, "GetProfile", nil, func() (*linodego.Profile, error) {
+		profile := c.profile
+		return &profile, nil
+	})
+}
+
+func (c *Client) ListTokens(_ context.Context, opts *linodego.ListOptions) ([]linodego.Token, error) {
+	return handle(c, "ListTokens", []any{opts}, func() ([]linodego.Token, error) {
+		return slices.Clone(c.tokens), nil
+	})
+}
+
+func (c *Client) GrantsList(context.Context) (*linodego.GrantsListResponse, error) {
+	return handle(c, "GrantsList", nil, func() (*linodego.GrantsListResponse, error) {
+		grants := c.grants
+		return &grants, nil
+	})
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lkeclient
+
+import (
+	"context"
+
+	"github.com/linode/linodego"
+)
+
+// guardedClient implements Client interface, rejecting all calls while the
+// circuit breaker is open.
+type guardedClient struct {
+	base    Client
+	breaker *CircuitBreaker
+}
+
+var _ Client = (*guardedClient)(nil)
+
+func guard[T any](b *CircuitBreaker, call func() (T, error)) (T, error) {
+	return guardExpecting(b, nil, call)
+}
+
+// guardExpecting works like guard, but errors matched by expected are part of
+// the normal operation, and are not recorded by the circuit breaker.
+func guardExpecting[T any](b *CircuitBreaker, expected func(error) bool, call func() (T, error)) (T, error) {
+	if err := b.Allow(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	res, err := call()
+	if err == nil || expected == nil || !expected(err) {
+		b.Record(err)
+	}
+
+	return res, err
+}
+
+func guardErr(b *CircuitBreaker, call func() error) error {
+	_, err := guard(b, func() (struct{}, error) {
+		return struct{}{}, call()
+	})
+
+	return err
+}
+
+func (c *guardedClient) ListLKEVersions(ctx context.Context, opts *linodego.ListOptions) ([]linodego.LKEVersion, error) {
+	return guard(c.breaker, func() ([]linodego.LKEVersion, error) {
+		return c.base.ListLKEVersions(ctx, opts)
+	})
+}
+
+func (c *guardedClient) ListLKEClusterAPIEndpoints(ctx context.Context, clusterID int, opts *linodego.ListOptions) ([]linodego.LKEClusterAPIEndpoint, error) {
+	return guard(c.breaker, func() ([]linodego.LKEClusterAPIEndpoint, error) {
+		return c.base.ListLKEClusterAPIEndpoints(ctx, clusterID, opts)
+	})
+}
+
+func (c *guardedClient) ListLKEClusters(ctx context.Context, opts *linodego.ListOptions) ([]linodego.LKECluster, error) {
+	return guard(c.breaker, func() ([]linodego.LKECluster, error) {
+		return c.base.ListLKEClusters(ctx, opts)
+	})
+}
+
+func (c *guardedClient) GetLKECluster(ctx context.Context, clusterID int) (*linodego.LKECluster, error) {
+	return guard(c.breaker, func() (*linodego.LKECluster, error) {
+		return c.base.GetLKECluster(ctx, clusterID)
+	})
+}
+
+func (c *guardedClient) CreateLKECluster(ctx context.Context, opts linodego.LKEClusterCreateOptions) (*linodego.LKECluster, error) {
+	return guard(c.breaker, func() (*linodego.LKECluster, error) {
+		return c.base.CreateLKECluster(ctx, opts)
+	})
+}
+
+func (c *guardedClient) UpdateLKECluster(ctx context.Context, clusterID int, opts linodego.LKEClusterUpdateOptions) (*linodego.LKECluster, error) {
+	return guard(c.breaker, func() (*linodego.LKECluster, error) {
+		return c.base.UpdateLKECluster(ctx, clusterID, opts)
+	})
+}
+
+func (c *guardedClient) DeleteLKECluster(ctx context.Context, clusterID int) error {
+	return guardErr(c.breaker, func() error {
+		return c.base.DeleteLKECluster(ctx, clusterID)
+	})
+}
+
+// GetLKEClusterKubeconfig responds with 503 until the kubeconfig of the new cluster
+// is ready, so the error does not count towards the circuit breaker threshold.
+func (c *guardedClient) GetLKEClusterKubeconfig(ctx context.Context, clusterID int) (*linodego.LKEClusterKubeconfig, error) {
+	return guardExpecting(c.breaker, isServiceUnavailable, func() (*linodego.LKEClusterKubeconfig, error) {
+		return c.base.GetLKEClusterKubeconfig(ctx, clusterID)
+	})
+}
+
+func (c *guardedClient) GetLKEClusterDashboard(ctx context.Context, clusterID int) (*linodego.LKEClusterDashboard, error) {
+	return guard(c.breaker, func() (*linodego.LKEClusterDashboard, error) {
+		return c.base.GetLKEClusterDashboard(ctx, clusterID)
+	})
+}
+
+func (c *guardedClient) ListLKENodePools(ctx context.Context, clusterID int, opts *linodego.ListOptions) ([]linodego.LKENodePool, error) {
+	return guard(c.breaker, func() ([]linodego.LKENodePool, error) {
+		return c.base.ListLKENodePools(ctx, clusterID, opts)
+	})
+}
+
+func (c *guardedClient) CreateLKENodePool(ctx context.Context, clusterID int, opts linodego.LKENodePoolCreateOptions) (*linodego.LKENodePool, error) {
+	return guard(c.breaker, func() (*linodego.LKENodePool, error) {
+		return c.base.CreateLKENodePool(ctx, clusterID, opts)
+	})
+}
+
+func (c *guardedClient) UpdateLKENodePool(ctx context.Context, clusterID, poolID int, opts linodego.LKENodePoolUpdateOptions) (*linodego.LKENodePool, error) {
+	return guard(c.breaker, func() (*linodego.LKENodePool, error) {
+		return c.base.UpdateLKENodePool(ctx, clusterID, poolID, opts)
+	})
+}
+
+func (c *guardedClient) DeleteLKENodePool(ctx context.Context, clusterID, poolID int) error {
+	return guardErr(c.breaker, func() error {
+		return c.base.DeleteLKENodePool(ctx, clusterID, poolID)
+	})
+}
+
+func (c *guardedClient) DeleteLKENodePoolNode(ctx context.Context, clusterID int, nodeID string) error {
+	return guardErr(c.breaker, func() error {
+		return c.base.DeleteLKENodePoolNode(ctx, clusterID, nodeID)
+	})
+}
+
+func (c *guardedClient) ListNodeBalancers(ctx context.Context, opts *linodego.ListOptions) ([]linodego.NodeBalancer, error) {
+	return guard(c.breaker, func() ([]linodego.NodeBalancer, error) {
+		return c.base.ListNodeBalancers(ctx, opts)
+	})
+}
+
+func (c *guardedClient) DeleteNodeBalancer(ctx context.Context, nodebalancerID int) error {
+	return guardErr(c.breaker, func() error {
+		return c.base.DeleteNodeBalancer(ctx, nodebalancerID)
+	})
+}
+
+func (c *guardedClient) ListVolumes(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Volume, error) {
+	return guard(c.breaker, func() ([]linodego.Volume, error) {
+		return c.base.ListVolumes(ctx, opts)
+	})
+}
+
+func (c *guardedClient) DeleteVolume(ctx context.Context, volumeID int) error {
+	return guardErr(c.breaker, func() error {
+		return c.base.DeleteVolume(ctx, volumeID)
+	})
+}
+
+func (c *guardedClient) GetProfile(ctx context.Context) (*linodego.Profile, error) {
+	return guard(c.breaker, func() (*linodego.Profile, error) {
+		return c.base.GetProfile(ctx)
+	})
+}
+
+func (c *guardedClient) ListTokens(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Token, error) {
+	return guard(c.breaker, func() ([]linodego.Token, error) {
+		return c.base.ListTokens(ctx, opts)
+	})
+}
+
+func (c *guardedClient) GrantsList(ctx context.Context) (*linodego.GrantsListResponse, error) {
+	return guard(c.breaker, func() (*linodego.GrantsListResponse, error) {
+		return c.base.GrantsList(ctx)
+	})
+}
@@ -18,8 +18,13 @@ package lkeclient
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 
+	"github.com/anza-labs/lke-operator/internal/version"
 	"github.com/linode/linodego"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/time/rate"
 )
 
 // Client defines a subset of all Linode Client methods required by LKE Operator.
@@ -27,6 +32,7 @@ type Client interface {
 	ListLKEVersions(ctx context.Context, opts *linodego.ListOptions) ([]linodego.LKEVersion, error)
 	ListLKEClusterAPIEndpoints(ctx context.Context, clusterID int, opts *linodego.ListOptions) ([]linodego.LKEClusterAPIEndpoint, error)
 
+	ListLKEClusters(ctx context.Context, opts *linodego.ListOptions) ([]linodego.LKECluster, error)
 	GetLKECluster(ctx context.Context, clusterID int) (*linodego.LKECluster, error)
 	CreateLKECluster(ctx context.Context, opts linodego.LKEClusterCreateOptions) (*linodego.LKECluster, error)
 	UpdateLKECluster(ctx context.Context, clusterID int, opts linodego.LKEClusterUpdateOptions) (*linodego.LKECluster, error)
@@ -40,13 +46,108 @@ type Client interface {
 	UpdateLKENodePool(ctx context.Context, clusterID, poolID int, opts linodego.LKENodePoolUpdateOptions) (*linodego.LKENodePool, error)
 	DeleteLKENodePool(ctx context.Context, clusterID, poolID int) error
 	DeleteLKENodePoolNode(ctx context.Context, clusterID int, nodeID string) error
+
+	ListNodeBalancers(ctx context.Context, opts *linodego.ListOptions) ([]linodego.NodeBalancer, error)
+	DeleteNodeBalancer(ctx context.Context, nodebalancerID int) error
+	ListVolumes(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Volume, error)
+	DeleteVolume(ctx context.Context, volumeID int) error
+
+	GetProfile(ctx context.Context) (*linodego.Profile, error)
+	ListTokens(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Token, error)
+	GrantsList(ctx context.Context) (*linodego.GrantsListResponse, error)
+}
+
+// DefaultUserAgent returns User-Agent identifying the operator build.
+func DefaultUserAgent() string {
+	return fmt.Sprintf("lke-operator/%s (%s; %s)",
+		version.Version,
+		version.OS,
+		version.Arch,
+	)
+}
+
+// Option configures the underlying Linode client.
+type Option func(*options)
+
+type options struct {
+	endpoint  Endpoint
+	transport http.RoundTripper
+	limiter   *rate.Limiter
+	logger    linodego.Logger
+}
+
+// WithAPIURL overrides the URL of the Linode API, e.g. to point the client at
+// an emulator. The URL may include the API version, e.g. http://127.0.0.1:8080/v4.
+func WithAPIURL(apiURL string) Option {
+	return func(o *options) {
+		o.endpoint.URL = apiURL
+	}
 }
 
-func New(token, ua string) *linodego.Client {
-	linodeClient := linodego.NewClient(nil)
+// WithEndpoint configures the URL, version, proxy and CA bundle of the Linode API.
+// Fields set in the endpoint override the ones set by the previous options.
+func WithEndpoint(endpoint Endpoint) Option {
+	return func(o *options) {
+		o.endpoint = o.endpoint.Merge(endpoint)
+	}
+}
+
+// WithTransport sets the HTTP transport used by the Linode client. Proxy and CA
+// bundle of the endpoint are not applied to the custom transport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = rt
+	}
+}
+
+// WithLogger sets the logger used by the Linode client.
+func WithLogger(logger linodego.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+func withRateLimiter(limiter *rate.Limiter) Option {
+	return func(o *options) {
+		o.limiter = limiter
+	}
+}
+
+func New(token, ua string, opts ...Option) *linodego.Client {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	transport := o.endpoint.transport()
+	if o.transport != nil {
+		transport = otelhttp.NewTransport(o.transport)
+	}
+
+	if o.limiter != nil {
+		transport = &rateLimitedTransport{
+			base:    transport,
+			limiter: o.limiter,
+		}
+	}
+
+	linodeClient := linodego.NewClient(&http.Client{Transport: transport})
 
 	linodeClient.SetUserAgent(ua)
 	linodeClient.SetToken(token)
 
+	if o.logger != nil {
+		linodeClient.SetLogger(o.logger)
+	}
+
+	if o.endpoint.URL != "" {
+		// URL is validated by the caller, parsing is the only failure
+		_, _ = linodeClient.UseURL(o.endpoint.URL)
+	}
+
+	if o.endpoint.Version != "" {
+		linodeClient.SetAPIVersion(o.endpoint.Version)
+	}
+
 	return &linodeClient
 }
@@ -152,6 +152,50 @@ func (_d ClientWithTracing) DeleteLKENodePoolNode(ctx context.Context, clusterID
 	return _d.Client.DeleteLKENodePoolNode(ctx, clusterID, nodeID)
 }
 
+// DeleteNodeBalancer implements lkeclient.Client
+func (_d ClientWithTracing) DeleteNodeBalancer(ctx context.Context, nodebalancerID int) (err error) {
+	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.DeleteNodeBalancer")
+	defer func() {
+		if _d._spanDecorator != nil {
+			_d._spanDecorator(_span, map[string]interface{}{
+				"ctx":            ctx,
+				"nodebalancerID": nodebalancerID}, map[string]interface{}{
+				"err": err})
+		} else if err != nil {
+			_span.RecordError(err)
+			_span.SetAttributes(
+				attribute.String("event", "error"),
+				attribute.String("message", err.Error()),
+			)
+		}
+
+		_span.End()
+	}()
+	return _d.Client.DeleteNodeBalancer(ctx, nodebalancerID)
+}
+
+// DeleteVolume implements lkeclient.Client
+func (_d ClientWithTracing) DeleteVolume(ctx context.Context, volumeID int) (err error) {
+	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.DeleteVolume")
+	defer func() {
+		if _d._spanDecorator != nil {
+			_d._spanDecorator(_span, map[string]interface{}{
+				"ctx":      ctx,
+				"volumeID": volumeID}, map[string]interface{}{
+				"err": err})
+		} else if err != nil {
+			_span.RecordError(err)
+			_span.SetAttributes(
+				attribute.String("event", "error"),
+				attribute.String("message", err.Error()),
+			)
+		}
+
+		_span.End()
+	}()
+	return _d.Client.DeleteVolume(ctx, volumeID)
+}
+
 // GetLKECluster implements lkeclient.Client
 func (_d ClientWithTracing) GetLKECluster(ctx context.Context, clusterID int) (lp1 *linodego.LKECluster, err error) {
 	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.GetLKECluster")
@@ -221,6 +265,50 @@ func (_d ClientWithTracing) GetLKEClusterKubeconfig(ctx context.Context, cluster
 	return _d.Client.GetLKEClusterKubeconfig(ctx, clusterID)
 }
 
+// GetProfile implements lkeclient.Client
+func (_d ClientWithTracing) GetProfile(ctx context.Context) (lp1 *linodego.Profile, err error) {
+	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.GetProfile")
+	defer func() {
+		if _d._spanDecorator != nil {
+			_d._spanDecorator(_span, map[string]interface{}{
+				"ctx": ctx}, map[string]interface{}{
+				"lp1": lp1,
+				"err": err})
+		} else if err != nil {
+			_span.RecordError(err)
+			_span.SetAttributes(
+				attribute.String("event", "error"),
+				attribute.String("message", err.Error()),
+			)
+		}
+
+		_span.End()
+	}()
+	return _d.Client.GetProfile(ctx)
+}
+
+// GrantsList implements lkeclient.Client
+func (_d ClientWithTracing) GrantsList(ctx context.Context) (lp1 *linodego.GrantsListResponse, err error) {
+	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.GrantsList")
+	defer func() {
+		if _d._spanDecorator != nil {
+			_d._spanDecorator(_span, map[string]interface{}{
+				"ctx": ctx}, map[string]interface{}{
+				"lp1": lp1,
+				"err": err})
+		} else if err != nil {
+			_span.RecordError(err)
+			_span.SetAttributes(
+				attribute.String("event", "error"),
+				attribute.String("message", err.Error()),
+			)
+		}
+
+		_span.End()
+	}()
+	return _d.Client.GrantsList(ctx)
+}
+
 // ListLKEClusterAPIEndpoints implements lkeclient.Client
 func (_d ClientWithTracing) ListLKEClusterAPIEndpoints(ctx context.Context, clusterID int, opts *linodego.ListOptions) (la1 []linodego.LKEClusterAPIEndpoint, err error) {
 	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.ListLKEClusterAPIEndpoints")
@@ -245,6 +333,29 @@ func (_d ClientWithTracing) ListLKEClusterAPIEndpoints(ctx context.Context, clus
 	return _d.Client.ListLKEClusterAPIEndpoints(ctx, clusterID, opts)
 }
 
+// ListLKEClusters implements lkeclient.Client
+func (_d ClientWithTracing) ListLKEClusters(ctx context.Context, opts *linodego.ListOptions) (la1 []linodego.LKECluster, err error) {
+	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.ListLKEClusters")
+	defer func() {
+		if _d._spanDecorator != nil {
+			_d._spanDecorator(_span, map[string]interface{}{
+				"ctx":  ctx,
+				"opts": opts}, map[string]interface{}{
+				"la1": la1,
+				"err": err})
+		} else if err != nil {
+			_span.RecordError(err)
+			_span.SetAttributes(
+				attribute.String("event", "error"),
+				attribute.String("message", err.Error()),
+			)
+		}
+
+		_span.End()
+	}()
+	return _d.Client.ListLKEClusters(ctx, opts)
+}
+
 // ListLKENodePools implements lkeclient.Client
 func (_d ClientWithTracing) ListLKENodePools(ctx context.Context, clusterID int, opts *linodego.ListOptions) (la1 []linodego.LKENodePool, err error) {
 	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.ListLKENodePools")
@@ -292,6 +403,75 @@ func (_d ClientWithTracing) ListLKEVersions(ctx context.Context, opts *linodego.
 	return _d.Client.ListLKEVersions(ctx, opts)
 }
 
+// ListNodeBalancers implements lkeclient.Client
+func (_d ClientWithTracing) ListNodeBalancers(ctx context.Context, opts *linodego.ListOptions) (la1 []linodego.NodeBalancer, err error) {
+	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.ListNodeBalancers")
+	defer func() {
+		if _d._spanDecorator != nil {
+			_d._spanDecorator(_span, map[string]interface{}{
+				"ctx":  ctx,
+				"opts": opts}, map[string]interface{}{
+				"la1": la1,
+				"err": err})
+		} else if err != nil {
+			_span.RecordError(err)
+			_span.SetAttributes(
+				attribute.String("event", "error"),
+				attribute.String("message", err.Error()),
+			)
+		}
+
+		_span.End()
+	}()
+	return _d.Client.ListNodeBalancers(ctx, opts)
+}
+
+// ListTokens implements lkeclient.Client
+func (_d ClientWithTracing) ListTokens(ctx context.Context, opts *linodego.ListOptions) (la1 []linodego.Token, err error) {
+	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.ListTokens")
+	defer func() {
+		if _d._spanDecorator != nil {
+			_d._spanDecorator(_span, map[string]interface{}{
+				"ctx":  ctx,
+				"opts": opts}, map[string]interface{}{
+				"la1": la1,
+				"err": err})
+		} else if err != nil {
+			_span.RecordError(err)
+			_span.SetAttributes(
+				attribute.String("event", "error"),
+				attribute.String("message", err.Error()),
+			)
+		}
+
+		_span.End()
+	}()
+	return _d.Client.ListTokens(ctx, opts)
+}
+
+// ListVolumes implements lkeclient.Client
+func (_d ClientWithTracing) ListVolumes(ctx context.Context, opts *linodego.ListOptions) (la1 []linodego.Volume, err error) {
+	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.ListVolumes")
+	defer func() {
+		if _d._spanDecorator != nil {
+			_d._spanDecorator(_span, map[string]interface{}{
+				"ctx":  ctx,
+				"opts": opts}, map[string]interface{}{
+				"la1": la1,
+				"err": err})
+		} else if err != nil {
+			_span.RecordError(err)
+			_span.SetAttributes(
+				attribute.String("event", "error"),
+				attribute.String("message", err.Error()),
+			)
+		}
+
+		_span.End()
+	}()
+	return _d.Client.ListVolumes(ctx, opts)
+}
+
 // UpdateLKECluster implements lkeclient.Client
 func (_d ClientWithTracing) UpdateLKECluster(ctx context.Context, clusterID int, opts linodego.LKEClusterUpdateOptions) (lp1 *linodego.LKECluster, err error) {
 	ctx, _span := otel.Tracer(_d._instance).Start(ctx, "lkeclient.Client.UpdateLKECluster")
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/anza-labs/lke-operator/internal/controller"
+)
+
+func newImportCommand(o *Options) *cobra.Command {
+	var (
+		name     string
+		instance string
+		adopt    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import CLUSTER_ID",
+		Short: "Generate the LKEClusterConfig of the existing LKE cluster",
+		Long: "Generate the LKEClusterConfig YAML describing the existing LKE cluster. " +
+			"With --adopt, the LKE cluster and its node pools are tagged, so that the operator " +
+			"takes them over once the generated object is applied, instead of provisioning a new cluster.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid cluster ID %q: %w", args[0], err)
+			}
+
+			client, err := o.linodeClient(cmd.Context(), nil)
+			if err != nil {
+				return err
+			}
+
+			if name == "" {
+				cluster, err := client.GetLKECluster(cmd.Context(), clusterID)
+				if err != nil {
+					return fmt.Errorf("failed to get cluster: %w", err)
+				}
+
+				name = objectName(cluster.Label)
+			}
+
+			if err := controller.ValidateInstanceID(instance); err != nil {
+				return fmt.Errorf("invalid --instance-id: %w", err)
+			}
+
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+				return fmt.Errorf("invalid name %q, set --name: %s", name, strings.Join(errs, ", "))
+			}
+
+			lke, err := controller.ImportCluster(cmd.Context(), client, o.Namespace, name, instance, clusterID, adopt)
+			if err != nil {
+				return err
+			}
+
+			out, err := yaml.Marshal(lke)
+			if err != nil {
+				return fmt.Errorf("failed to marshal LKEClusterConfig: %w", err)
+			}
+
+			_, err = o.Out.Write(out)
+
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "",
+		"The name of the generated object. Defaults to the label of the LKE cluster.")
+	cmd.Flags().BoolVar(&adopt, "adopt", false,
+		"Tag the LKE cluster and its node pools for the generated object.")
+	cmd.Flags().StringVar(&instance, "instance-id", controller.DefaultInstanceID,
+		"ID of the operator instance taking over the adopted LKE cluster.")
+
+	return cmd
+}
+
+// objectName returns the object name derived from the label of the LKE cluster.
+func objectName(label string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '-'
+		}
+	}, label), "-.")
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func newKubeconfigCommand(o *Options) *cobra.Command {
+	var (
+		output      string
+		contextName string
+		setCurrent  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "kubeconfig NAME",
+		Short: "Merge the kubeconfig of the LKE cluster into the local kubeconfig",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lke, err := o.get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			data, err := o.kubeconfig(cmd.Context(), lke)
+			if err != nil {
+				return err
+			}
+
+			src, err := clientcmd.Load(data)
+			if err != nil {
+				return fmt.Errorf("failed to parse kubeconfig: %w", err)
+			}
+
+			dst, err := clientcmd.LoadFromFile(output)
+			if errors.Is(err, fs.ErrNotExist) {
+				dst, err = clientcmdapi.NewConfig(), nil
+			}
+
+			if err != nil {
+				return fmt.Errorf("failed to load %s: %w", output, err)
+			}
+
+			if contextName == "" {
+				contextName = fmt.Sprintf("lke-%s-%s", lke.Namespace, lke.Name)
+			}
+
+			if err := mergeKubeconfig(dst, src, contextName, setCurrent); err != nil {
+				return err
+			}
+
+			if err := clientcmd.WriteToFile(*dst, output); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+
+			fmt.Fprintf(o.Out, "Merged context %q into %s\n", contextName, output)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output-file", clientcmd.RecommendedHomeFile,
+		"The kubeconfig file the kubeconfig of the LKE cluster is merged into.")
+	cmd.Flags().StringVar(&contextName, "context-name", "",
+		"The name of the merged context. Defaults to lke-<namespace>-<name>.")
+	cmd.Flags().BoolVar(&setCurrent, "set-current", false,
+		"Switch the current context to the merged context.")
+
+	return cmd
+}
+
+// mergeKubeconfig merges the kubeconfig of the LKE cluster into dst. The context of
+// src, with its cluster and user, is renamed to name, replacing the entries with the
+// same name from the previous merge.
+func mergeKubeconfig(dst, src *clientcmdapi.Config, name string, setCurrent bool) error {
+	srcContextName := src.CurrentContext
+	if srcContextName == "" {
+		names := sortedKeys(src.Contexts)
+		if len(names) != 1 {
+			return fmt.Errorf("kubeconfig has %d contexts and no current context", len(names))
+		}
+
+		srcContextName = names[0]
+	}
+
+	srcContext, ok := src.Contexts[srcContextName]
+	if !ok {
+		return fmt.Errorf("kubeconfig has no context %q", srcContextName)
+	}
+
+	cluster, ok := src.Clusters[srcContext.Cluster]
+	if !ok {
+		return fmt.Errorf("kubeconfig has no cluster %q", srcContext.Cluster)
+	}
+
+	user, ok := src.AuthInfos[srcContext.AuthInfo]
+	if !ok {
+		return fmt.Errorf("kubeconfig has no user %q", srcContext.AuthInfo)
+	}
+
+	merged := srcContext.DeepCopy()
+	merged.Cluster = name
+	merged.AuthInfo = name
+
+	dst.Clusters[name] = cluster.DeepCopy()
+	dst.AuthInfos[name] = user.DeepCopy()
+	dst.Contexts[name] = merged
+
+	if setCurrent || dst.CurrentContext == "" {
+		dst.CurrentContext = name
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+// newPauseCommand returns the pause command, or the resume command if pause is false.
+func newPauseCommand(o *Options, pause bool) *cobra.Command {
+	use, short, done := "pause", "Stop reconciling the LKE cluster", "Paused"
+
+	var value *string
+	if pause {
+		value = mkptr("true")
+	} else {
+		use, short, done = "resume", "Resume reconciling the LKE cluster", "Resumed"
+	}
+
+	return &cobra.Command{
+		Use:   use + " NAME",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.annotate(cmd.Context(), args[0], v1alpha1.PausedAnnotation, value); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(o.Out, "%s %s/%s\n", done, o.Namespace, args[0])
+
+			return nil
+		},
+	}
+}
+
+func mkptr[T any](t T) *T {
+	return &t
+}
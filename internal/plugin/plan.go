@@ -0,0 +1,105 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+func newPlanCommand(o *Options) *cobra.Command {
+	var approve bool
+
+	cmd := &cobra.Command{
+		Use:   "plan NAME",
+		Short: "Show the destructive operations waiting for approval",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lke, err := o.get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			printPlan(o.Out, lke)
+
+			plan := lke.Status.PendingApproval
+			if !approve || plan == nil {
+				return nil
+			}
+
+			if err := o.annotate(cmd.Context(), lke.Name, v1alpha1.ApproveAnnotation, &plan.Hash); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(o.Out, "Approved plan %s\n", plan.Hash)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&approve, "approve", false, "Approve the pending plan.")
+
+	return cmd
+}
+
+// printPlan prints the pending plan of the object.
+func printPlan(w io.Writer, lke *v1alpha1.LKEClusterConfig) {
+	plan := lke.Status.PendingApproval
+	if plan == nil || len(plan.Operations) == 0 {
+		fmt.Fprintf(w, "No operations pending approval for %s/%s\n", lke.Namespace, lke.Name)
+		return
+	}
+
+	root := &tree{text: fmt.Sprintf("Plan %s for %s/%s", plan.Hash, lke.Namespace, lke.Name)}
+	for _, op := range plan.Operations {
+		root.add(fmt.Sprintf("%s: %s", op.Kind, op.Description))
+	}
+
+	root.print(w)
+
+	if lke.Annotations[v1alpha1.ApproveAnnotation] != plan.Hash {
+		fmt.Fprintf(w, "\nApprove with: kubectl lke plan %s -n %s --approve\n", lke.Name, lke.Namespace)
+	}
+}
+
+// annotate sets the annotation of the object, or removes it if the value is nil.
+func (o *Options) annotate(ctx context.Context, name, key string, value *string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]*string{key: value},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build patch: %w", err)
+	}
+
+	if _, err := o.LKE.LkeV1alpha1().LKEClusterConfigs(o.Namespace).Patch(
+		ctx, name, types.MergePatchType, patch, metav1.PatchOptions{},
+	); err != nil {
+		return fmt.Errorf("failed to annotate LKEClusterConfig %s/%s: %w", o.Namespace, name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugin implements kubectl-lke, the kubectl plugin for day-to-day operations
+// on the LKE clusters managed by the operator.
+package plugin
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/credentials"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	"github.com/anza-labs/lke-operator/pkg/client/clientset/versioned"
+)
+
+const (
+	// kubeconfigSecretSuffix is the suffix of the name of the Secret holding the
+	// kubeconfig of the LKE cluster, published by the operator.
+	kubeconfigSecretSuffix = "-kubeconfig"
+
+	// kubeconfigKey is the key of the kubeconfig in the Secret data.
+	kubeconfigKey = "kubeconfig"
+
+	// DefaultPollInterval is the default interval in which the subcommands waiting
+	// for the Linode API poll it.
+	DefaultPollInterval = 10 * time.Second
+)
+
+// Options holds the clients and settings shared by the subcommands. Unset clients
+// are created from the kubeconfig and the flags before running the subcommand.
+type Options struct {
+	Out    io.Writer
+	ErrOut io.Writer
+
+	// Namespace of the LKEClusterConfig objects. Defaults to the namespace of the
+	// current context.
+	Namespace string
+
+	// LKE is the client of the lke.anza-labs.dev API group.
+	LKE versioned.Interface
+
+	// Kubernetes is the client of the core API group.
+	Kubernetes kubernetes.Interface
+
+	// NewLinodeClient returns the Linode client using the token. Defaults to the
+	// client of the Linode API at LinodeAPIURL.
+	NewLinodeClient func(token string) lkeclient.Client
+
+	// NewWorkloadClient returns the client of the LKE cluster using the kubeconfig.
+	NewWorkloadClient func(kubeconfig []byte) (kubernetes.Interface, error)
+
+	// LinodeToken is the Linode API token used by the subcommands calling the Linode
+	// API directly. Defaults to the token from the environment, or the token referenced
+	// by the object, if any.
+	LinodeToken string
+
+	// LinodeAPIURL overrides the URL of the Linode API.
+	LinodeAPIURL string
+
+	// PollInterval is the interval in which the subcommands waiting for the
+	// Linode API poll it. Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+
+	configOverrides *clientcmd.ConfigOverrides
+	loadingRules    *clientcmd.ClientConfigLoadingRules
+}
+
+// NewCommand returns the root command of the plugin writing to the given streams.
+func NewCommand(out, errOut io.Writer) *cobra.Command {
+	return newCommand(&Options{Out: out, ErrOut: errOut})
+}
+
+func newCommand(o *Options) *cobra.Command {
+	o.loadingRules = clientcmd.NewDefaultClientConfigLoadingRules()
+	o.configOverrides = &clientcmd.ConfigOverrides{}
+
+	cmd := &cobra.Command{
+		Use:   "kubectl-lke",
+		Short: "Operate the LKE clusters managed by the lke-operator",
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			return o.complete()
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.loadingRules.ExplicitPath, "kubeconfig", "",
+		"Path to the kubeconfig file to use for the CLI requests.")
+	clientcmd.BindOverrideFlags(o.configOverrides, flags, clientcmd.RecommendedConfigOverrideFlags(""))
+	flags.StringVar(&o.LinodeToken, "linode-token", o.LinodeToken,
+		"The Linode API token. Defaults to the "+credentials.TokenEnv+" environment variable, "+
+			"or the token referenced by the object, if readable.")
+	flags.StringVar(&o.LinodeAPIURL, "linode-api-url", o.LinodeAPIURL,
+		"The URL of the Linode API, e.g. of the emulator.")
+
+	cmd.AddCommand(
+		newKubeconfigCommand(o),
+		newStatusCommand(o),
+		newPlanCommand(o),
+		newRecycleCommand(o),
+		newPauseCommand(o, true),
+		newPauseCommand(o, false),
+		newImportCommand(o),
+	)
+
+	return cmd
+}
+
+// complete creates the unset clients, and defaults the namespace.
+func (o *Options) complete() error {
+	config := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(o.loadingRules, o.configOverrides)
+
+	if o.Namespace == "" {
+		namespace, _, err := config.Namespace()
+		if err != nil {
+			return fmt.Errorf("failed to get namespace: %w", err)
+		}
+
+		o.Namespace = namespace
+	}
+
+	if o.LinodeToken == "" {
+		o.LinodeToken = os.Getenv(credentials.TokenEnv)
+	}
+
+	if o.NewLinodeClient == nil {
+		o.NewLinodeClient = func(token string) lkeclient.Client {
+			var opts []lkeclient.Option
+			if o.LinodeAPIURL != "" {
+				opts = append(opts, lkeclient.WithAPIURL(o.LinodeAPIURL))
+			}
+
+			return lkeclient.New(token, lkeclient.DefaultUserAgent(), opts...)
+		}
+	}
+
+	if o.PollInterval == 0 {
+		o.PollInterval = DefaultPollInterval
+	}
+
+	if o.NewWorkloadClient == nil {
+		o.NewWorkloadClient = func(kubeconfig []byte) (kubernetes.Interface, error) {
+			restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+			}
+
+			return kubernetes.NewForConfig(restConfig)
+		}
+	}
+
+	if o.LKE != nil && o.Kubernetes != nil {
+		return nil
+	}
+
+	restConfig, err := config.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	if o.LKE == nil {
+		if o.LKE, err = versioned.NewForConfig(restConfig); err != nil {
+			return fmt.Errorf("failed to create lke client: %w", err)
+		}
+	}
+
+	if o.Kubernetes == nil {
+		if o.Kubernetes, err = kubernetes.NewForConfig(restConfig); err != nil {
+			return fmt.Errorf("failed to create kubernetes client: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// get returns the LKEClusterConfig with the name from the namespace.
+func (o *Options) get(ctx context.Context, name string) (*v1alpha1.LKEClusterConfig, error) {
+	lke, err := o.LKE.LkeV1alpha1().LKEClusterConfigs(o.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LKEClusterConfig %s/%s: %w", o.Namespace, name, err)
+	}
+
+	return lke, nil
+}
+
+// kubeconfig returns the kubeconfig of the LKE cluster published by the operator.
+func (o *Options) kubeconfig(ctx context.Context, lke *v1alpha1.LKEClusterConfig) ([]byte, error) {
+	name := lke.Name + kubeconfigSecretSuffix
+
+	secret, err := o.Kubernetes.CoreV1().Secrets(lke.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig secret %s/%s: %w", lke.Namespace, name, err)
+	}
+
+	kubeconfig, ok := secret.Data[kubeconfigKey]
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig secret %s/%s has no %q key", lke.Namespace, name, kubeconfigKey)
+	}
+
+	// The kubeconfig is stored base64 encoded, as returned by the Linode API.
+	if decoded, err := base64.StdEncoding.DecodeString(string(kubeconfig)); err == nil {
+		kubeconfig = decoded
+	}
+
+	return kubeconfig, nil
+}
+
+// linodeClient returns the Linode client using the token from the flags, or the
+// token referenced by the object.
+func (o *Options) linodeClient(ctx context.Context, lke *v1alpha1.LKEClusterConfig) (lkeclient.Client, error) {
+	if o.LinodeToken != "" {
+		return o.NewLinodeClient(o.LinodeToken), nil
+	}
+
+	if lke == nil || lke.Spec.TokenSecretRef == nil {
+		return nil, fmt.Errorf("%w: set --linode-token or %s", internalerrors.ErrNoCredentials, credentials.TokenEnv)
+	}
+
+	ref := lke.Spec.TokenSecretRef
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = lke.Namespace
+	}
+
+	cred, err := credentials.Secret(o.Kubernetes, credentials.SecretRef{
+		Namespace: namespace,
+		Name:      ref.Name,
+		Key:       ref.Key,
+	}).Credential(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	return o.NewLinodeClient(cred.Token), nil
+}
+
+// sortedKeys returns the sorted keys of the map.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,366 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+	lkefake "github.com/anza-labs/lke-operator/internal/lkeclient/fake"
+	"github.com/anza-labs/lke-operator/pkg/client/clientset/versioned/fake"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: lke1
+  cluster:
+    server: https://lke1.example.com:443
+users:
+- name: lke1-admin
+  user:
+    token: secret
+contexts:
+- name: lke1-ctx
+  context:
+    cluster: lke1
+    user: lke1-admin
+current-context: lke1-ctx
+`
+
+type testEnv struct {
+	options *Options
+	out     *bytes.Buffer
+	linode  *lkefake.Client
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	linode := lkefake.NewClient()
+	clusterID := linode.AddCluster(linodego.LKECluster{Label: "prod_Cluster", Region: "us-east", K8sVersion: "1.30"},
+		linodego.LKENodePool{ID: 10, Count: 2, Type: "g6-standard-1", Tags: []string{"lke-operator.name=default"}},
+		linodego.LKENodePool{ID: 11, Count: 1, Type: "g6-standard-2", Tags: []string{"lke-operator.name=extra"}},
+	)
+
+	lke := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "prod"},
+		Spec: v1alpha1.LKEClusterConfigSpec{
+			Region:         "us-east",
+			TokenSecretRef: &v1alpha1.SecretRef{Name: "token", Key: "LINODE_TOKEN"},
+		},
+		Status: v1alpha1.LKEClusterConfigStatus{
+			Phase:     mkptr(v1alpha1.PhaseActive),
+			ClusterID: mkptr(clusterID),
+			NodePoolStatuses: map[string]v1alpha1.NodePoolStatus{
+				"default": {ID: mkptr(10), NodePoolDetails: v1alpha1.LKENodePool{NodeCount: 2, LinodeType: "g6-standard-1"}},
+				"extra":   {ID: mkptr(11), NodePoolDetails: v1alpha1.LKENodePool{NodeCount: 1, LinodeType: "g6-standard-2"}},
+			},
+			PendingApproval: &v1alpha1.Plan{
+				Hash: "0123456789abcdef",
+				Operations: []v1alpha1.PlannedOperation{{
+					Kind:        v1alpha1.OperationNodePoolDelete,
+					Description: "delete node pool old (g6-standard-1)",
+				}},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+
+	return &testEnv{
+		out:    out,
+		linode: linode,
+		options: &Options{
+			Out:       out,
+			ErrOut:    out,
+			Namespace: "foo",
+			LKE:       fake.NewSimpleClientset(lke),
+			Kubernetes: kubefake.NewSimpleClientset(
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "prod-kubeconfig"},
+					Data: map[string][]byte{
+						kubeconfigKey: []byte(base64.StdEncoding.EncodeToString([]byte(testKubeconfig))),
+					},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "token"},
+					Data:       map[string][]byte{"LINODE_TOKEN": []byte("token")},
+				},
+			),
+			PollInterval:    time.Millisecond,
+			NewLinodeClient: func(string) lkeclient.Client { return linode },
+			NewWorkloadClient: func([]byte) (kubernetes.Interface, error) {
+				return kubefake.NewSimpleClientset(
+					&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+						Name:   "lke1-10-a",
+						Labels: map[string]string{poolIDLabel: "10"},
+					}},
+				), nil
+			},
+		},
+	}
+}
+
+func (e *testEnv) run(t *testing.T, args ...string) error {
+	t.Helper()
+
+	cmd := newCommand(e.options)
+	cmd.SetArgs(args)
+
+	return cmd.ExecuteContext(context.Background())
+}
+
+func (e *testEnv) get(t *testing.T) *v1alpha1.LKEClusterConfig {
+	t.Helper()
+
+	lke, err := e.options.LKE.LkeV1alpha1().LKEClusterConfigs("foo").Get(context.Background(), "prod", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get object: %v", err)
+	}
+
+	return lke
+}
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		args           []string
+		expectedErr    bool
+		expectedOutput []string
+		setup          func(e *testEnv)
+		verify         func(t *testing.T, e *testEnv)
+	}{
+		"status": {
+			args: []string{"status", "prod"},
+			expectedOutput: []string{
+				"LKEClusterConfig foo/prod (phase: Active, cluster: 1)\n" +
+					"├── default (pool 10): 2 x g6-standard-1\n" +
+					"│   └── lke1-10-a (NotReady)\n" +
+					"└── extra (pool 11): 1 x g6-standard-2\n",
+			},
+		},
+		"plan": {
+			args: []string{"plan", "prod"},
+			expectedOutput: []string{
+				"Plan 0123456789abcdef for foo/prod\n└── NodePoolDelete: delete node pool old (g6-standard-1)\n",
+				"kubectl lke plan prod -n foo --approve",
+			},
+		},
+		"plan_approve": {
+			args:           []string{"plan", "prod", "--approve"},
+			expectedOutput: []string{"Approved plan 0123456789abcdef"},
+			verify: func(t *testing.T, e *testEnv) {
+				if hash := e.get(t).Annotations[v1alpha1.ApproveAnnotation]; hash != "0123456789abcdef" {
+					t.Errorf("expected approve annotation value: %#+v, got: %#+v", "0123456789abcdef", hash)
+				}
+			},
+		},
+		"pause": {
+			args:           []string{"pause", "prod"},
+			expectedOutput: []string{"Paused foo/prod"},
+			verify: func(t *testing.T, e *testEnv) {
+				if paused := e.get(t).Annotations[v1alpha1.PausedAnnotation]; paused != "true" {
+					t.Errorf("expected paused annotation value: %#+v, got: %#+v", "true", paused)
+				}
+			},
+		},
+		"resume": {
+			args:           []string{"resume", "prod"},
+			expectedOutput: []string{"Resumed foo/prod"},
+			verify: func(t *testing.T, e *testEnv) {
+				if _, ok := e.get(t).Annotations[v1alpha1.PausedAnnotation]; ok {
+					t.Errorf("expected paused annotation to be removed")
+				}
+			},
+		},
+		"recycle": {
+			args:           []string{"recycle", "prod", "--pool", "default"},
+			expectedOutput: []string{"Recycled node", "of node pool default"},
+			setup: func(e *testEnv) {
+				// the replacement nodes become ready by the next poll
+				e.linode.PrependReactor("ListLKENodePools", func(call lkefake.Call) (bool, any, error) {
+					e.linode.SetStatus(call.Args[0].(int), linodego.LKEClusterReady)
+					return false, nil, nil
+				})
+			},
+			verify: func(t *testing.T, e *testEnv) {
+				if calls := len(e.linode.CallsTo("DeleteLKENodePoolNode")); calls != 2 {
+					t.Errorf("expected recycled nodes: %#+v, got: %#+v", 2, calls)
+				}
+			},
+		},
+		"recycle_timeout": {
+			args:        []string{"recycle", "prod", "--pool", "default", "--timeout", "50ms"},
+			expectedErr: true,
+			verify: func(t *testing.T, e *testEnv) {
+				if calls := len(e.linode.CallsTo("DeleteLKENodePoolNode")); calls != 1 {
+					t.Errorf("expected recycled nodes: %#+v, got: %#+v", 1, calls)
+				}
+			},
+		},
+		"recycle_unknown_pool": {
+			args:        []string{"recycle", "prod", "--pool", "missing"},
+			expectedErr: true,
+		},
+		"import": {
+			args: []string{"import", "1"},
+			expectedOutput: []string{
+				"kind: LKEClusterConfig",
+				"name: prod-cluster",
+				"label: prod_Cluster",
+			},
+			verify: func(t *testing.T, e *testEnv) {
+				if calls := len(e.linode.CallsTo("UpdateLKECluster")); calls != 0 {
+					t.Errorf("expected cluster not to be tagged, got: %d updates", calls)
+				}
+			},
+		},
+		"import_adopt": {
+			args:           []string{"import", "1", "--name", "imported", "--adopt", "--linode-token", "token"},
+			expectedOutput: []string{"name: imported"},
+			verify: func(t *testing.T, e *testEnv) {
+				if calls := len(e.linode.CallsTo("UpdateLKECluster")); calls != 1 {
+					t.Errorf("expected cluster to be tagged, got: %d updates", calls)
+				}
+			},
+		},
+		"import_invalid_id": {
+			args:        []string{"import", "abc"},
+			expectedErr: true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			e := newTestEnv(t)
+			if strings.HasPrefix(name, "import") {
+				// import has no object referencing the token
+				e.options.LinodeToken = "token"
+			}
+
+			if tc.setup != nil {
+				tc.setup(e)
+			}
+
+			err := e.run(t, tc.args...)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %#+v, got: %v", tc.expectedErr, err)
+			}
+
+			for _, expected := range tc.expectedOutput {
+				if !strings.Contains(e.out.String(), expected) {
+					t.Errorf("expected output to contain: %q, got: %q", expected, e.out.String())
+				}
+			}
+
+			if tc.verify != nil {
+				tc.verify(t, e)
+			}
+		})
+	}
+}
+
+func TestKubeconfigCommand(t *testing.T) {
+	t.Parallel()
+
+	e := newTestEnv(t)
+
+	output := filepath.Join(t.TempDir(), ".kube", "config")
+
+	existing := clientcmdapi.NewConfig()
+	existing.Clusters["other"] = &clientcmdapi.Cluster{Server: "https://other.example.com"}
+	existing.AuthInfos["other"] = &clientcmdapi.AuthInfo{Token: "other"}
+	existing.Contexts["other"] = &clientcmdapi.Context{Cluster: "other", AuthInfo: "other"}
+	existing.CurrentContext = "other"
+
+	if err := clientcmd.WriteToFile(*existing, output); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+
+	// merged twice, the second time replacing the entries of the first
+	for range 2 {
+		if err := e.run(t, "kubeconfig", "prod", "--output-file", output, "--set-current"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	config, err := clientcmd.LoadFromFile(output)
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+
+	if len(config.Contexts) != 2 || len(config.Clusters) != 2 || len(config.AuthInfos) != 2 {
+		t.Errorf("expected the context, cluster and user to be merged, got: %#+v", config)
+	}
+
+	if config.CurrentContext != "lke-foo-prod" {
+		t.Errorf("expected CurrentContext value: %#+v, got: %#+v", "lke-foo-prod", config.CurrentContext)
+	}
+
+	merged, ok := config.Contexts["lke-foo-prod"]
+	if !ok || merged.Cluster != "lke-foo-prod" || merged.AuthInfo != "lke-foo-prod" {
+		t.Fatalf("expected merged context, got: %#+v", merged)
+	}
+
+	if server := config.Clusters["lke-foo-prod"].Server; server != "https://lke1.example.com:443" {
+		t.Errorf("expected Server value: %#+v, got: %#+v", "https://lke1.example.com:443", server)
+	}
+}
+
+func TestImportCommand_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	e := newTestEnv(t)
+	e.options.LinodeToken = "token"
+
+	if err := e.run(t, "import", "1", "--name", "prod"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lke := &v1alpha1.LKEClusterConfig{}
+	if err := yaml.UnmarshalStrict(e.out.Bytes(), lke); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+
+	for name, expected := range map[string]v1alpha1.LKENodePool{
+		"default": {NodeCount: 2, LinodeType: "g6-standard-1"},
+		"extra":   {NodeCount: 1, LinodeType: "g6-standard-2"},
+	} {
+		if actual := lke.Spec.NodePools[name]; actual != expected {
+			t.Errorf("expected %s NodePool value: %#+v, got: %#+v", name, expected, actual)
+		}
+	}
+}
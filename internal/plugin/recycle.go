@@ -0,0 +1,177 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/linode/linodego"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/lkeclient"
+)
+
+// DefaultRecycleTimeout is the default time to wait for the replacement of each
+// recycled node to become ready.
+const DefaultRecycleTimeout = 30 * time.Minute
+
+func newRecycleCommand(o *Options) *cobra.Command {
+	var (
+		pools   []string
+		timeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "recycle NAME",
+		Short: "Replace the nodes of the LKE cluster with new ones",
+		Long: "Replace the nodes of the node pools managed by the operator with new ones. " +
+			"Nodes are recycled one at a time, waiting for the replacement of each node " +
+			"to become ready before recycling the next one.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lke, err := o.get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := o.linodeClient(cmd.Context(), lke)
+			if err != nil {
+				return err
+			}
+
+			return recycle(cmd.Context(), o.Out, client, lke, pools, o.PollInterval, timeout)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&pools, "pool", nil,
+		"Name of the node pool to recycle. Defaults to all node pools managed by the operator.")
+	cmd.Flags().DurationVar(&timeout, "timeout", DefaultRecycleTimeout,
+		"Time to wait for the replacement of each recycled node to become ready.")
+
+	return cmd
+}
+
+// recycle recycles the nodes of the named node pools from the status of the object,
+// or of all node pools if no names are given, one node at a time.
+func recycle(
+	ctx context.Context,
+	w io.Writer,
+	client lkeclient.Client,
+	lke *v1alpha1.LKEClusterConfig,
+	names []string,
+	interval, timeout time.Duration,
+) error {
+	if lke.Status.ClusterID == nil {
+		return fmt.Errorf("%w: %s/%s", internalerrors.ErrNoClusterID, lke.Namespace, lke.Name)
+	}
+
+	clusterID := *lke.Status.ClusterID
+
+	if len(names) == 0 {
+		names = sortedKeys(lke.Status.NodePoolStatuses)
+	}
+
+	poolIDs := make(map[int]string, len(names))
+
+	for _, name := range names {
+		status, ok := lke.Status.NodePoolStatuses[name]
+		if !ok || status.ID == nil {
+			return fmt.Errorf("node pool %s not found in the status of %s/%s", name, lke.Namespace, lke.Name)
+		}
+
+		poolIDs[*status.ID] = name
+	}
+
+	nps, err := client.ListLKENodePools(ctx, clusterID, &linodego.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list node pools: %w", err)
+	}
+
+	slices.SortFunc(nps, func(a, b linodego.LKENodePool) int { return a.ID - b.ID })
+
+	for _, np := range nps {
+		name, ok := poolIDs[np.ID]
+		if !ok {
+			continue
+		}
+
+		for _, node := range np.Linodes {
+			if err := client.DeleteLKENodePoolNode(ctx, clusterID, node.ID); err != nil {
+				return fmt.Errorf("failed to recycle node %s of node pool %s: %w", node.ID, name, err)
+			}
+
+			fmt.Fprintf(w, "Recycling node %s of node pool %s\n", node.ID, name)
+
+			if err := waitForPool(ctx, client, clusterID, np, node.ID, interval, timeout); err != nil {
+				return fmt.Errorf("failed to wait for the replacement of node %s of node pool %s: %w",
+					node.ID, name, err)
+			}
+
+			fmt.Fprintf(w, "Recycled node %s of node pool %s\n", node.ID, name)
+		}
+	}
+
+	return nil
+}
+
+// waitForPool waits until the recycled node is removed from the node pool, and all
+// nodes of the node pool, including the replacement, are ready.
+func waitForPool(
+	ctx context.Context,
+	client lkeclient.Client,
+	clusterID int,
+	np linodego.LKENodePool,
+	nodeID string,
+	interval, timeout time.Duration,
+) error {
+	return wait.PollUntilContextTimeout(ctx, interval, timeout, false, func(ctx context.Context) (bool, error) {
+		nps, err := client.ListLKENodePools(ctx, clusterID, &linodego.ListOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to list node pools: %w", err)
+		}
+
+		i := slices.IndexFunc(nps, func(p linodego.LKENodePool) bool { return p.ID == np.ID })
+		if i < 0 {
+			return false, fmt.Errorf("node pool %d not found", np.ID)
+		}
+
+		return poolReady(nps[i], nodeID, len(np.Linodes)), nil
+	})
+}
+
+// poolReady returns true if the node pool no longer has the recycled node, and has
+// at least count nodes, all of them ready.
+func poolReady(np linodego.LKENodePool, nodeID string, count int) bool {
+	if len(np.Linodes) < count {
+		return false
+	}
+
+	for _, node := range np.Linodes {
+		if node.ID == nodeID || node.Status != linodego.LKELinodeReady {
+			return false
+		}
+	}
+
+	return true
+}
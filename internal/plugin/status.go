@@ -0,0 +1,196 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+// poolIDLabel is the label of the LKE nodes holding the ID of their node pool.
+const poolIDLabel = "lke.linode.com/pool-id"
+
+func newStatusCommand(o *Options) *cobra.Command {
+	var withNodes bool
+
+	cmd := &cobra.Command{
+		Use:   "status NAME",
+		Short: "Show the tree of the node pools and nodes of the LKE cluster",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lke, err := o.get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			var nodes map[int][]corev1.Node
+			if withNodes {
+				nodes, err = o.listNodes(cmd.Context(), lke)
+				if err != nil {
+					fmt.Fprintf(o.ErrOut, "Nodes unavailable: %v\n", err)
+				}
+			}
+
+			printStatus(o.Out, lke, nodes)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&withNodes, "nodes", true,
+		"List the nodes of the node pools, using the kubeconfig of the LKE cluster.")
+
+	return cmd
+}
+
+// listNodes returns the nodes of the LKE cluster by the ID of their node pool.
+func (o *Options) listNodes(ctx context.Context, lke *v1alpha1.LKEClusterConfig) (map[int][]corev1.Node, error) {
+	kubeconfig, err := o.kubeconfig(ctx, lke)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := o.NewWorkloadClient(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	list, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: poolIDLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	nodes := map[int][]corev1.Node{}
+
+	for _, node := range list.Items {
+		id, err := strconv.Atoi(node.Labels[poolIDLabel])
+		if err != nil {
+			continue
+		}
+
+		nodes[id] = append(nodes[id], node)
+	}
+
+	return nodes, nil
+}
+
+// printStatus prints the tree of the node pools from the status, with the given
+// nodes of each node pool, if not nil.
+func printStatus(w io.Writer, lke *v1alpha1.LKEClusterConfig, nodes map[int][]corev1.Node) {
+	phase := v1alpha1.PhaseUnknown
+	if lke.Status.Phase != nil {
+		phase = *lke.Status.Phase
+	}
+
+	cluster := "<none>"
+	if lke.Status.ClusterID != nil {
+		cluster = strconv.Itoa(*lke.Status.ClusterID)
+	}
+
+	root := &tree{
+		text: fmt.Sprintf("LKEClusterConfig %s/%s (phase: %s, cluster: %s)", lke.Namespace, lke.Name, phase, cluster),
+	}
+
+	if lke.Status.FailureMessage != nil {
+		root.add("Failure: " + *lke.Status.FailureMessage)
+	}
+
+	if lke.Annotations[v1alpha1.PausedAnnotation] == "true" {
+		root.add("Paused")
+	}
+
+	for _, name := range sortedKeys(lke.Status.NodePoolStatuses) {
+		addPool(root, name, lke.Status.NodePoolStatuses[name], nodes)
+	}
+
+	for _, status := range lke.Status.UnmanagedNodePools {
+		addPool(root, "(unmanaged)", status, nodes)
+	}
+
+	root.print(w)
+}
+
+func addPool(root *tree, name string, status v1alpha1.NodePoolStatus, nodes map[int][]corev1.Node) {
+	details := status.NodePoolDetails
+
+	text := fmt.Sprintf("%s: %d x %s", name, details.NodeCount, details.LinodeType)
+	if status.ID != nil {
+		text = fmt.Sprintf("%s (pool %d): %d x %s", name, *status.ID, details.NodeCount, details.LinodeType)
+	}
+
+	if details.Autoscaler != nil {
+		text += fmt.Sprintf(", autoscaler %d-%d", details.Autoscaler.Min, details.Autoscaler.Max)
+	}
+
+	pool := root.add(text)
+
+	if nodes == nil || status.ID == nil {
+		return
+	}
+
+	for _, node := range nodes[*status.ID] {
+		pool.add(fmt.Sprintf("%s (%s)", node.Name, nodeReadiness(node)))
+	}
+}
+
+func nodeReadiness(node corev1.Node) string {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
+			return "Ready"
+		}
+	}
+
+	return "NotReady"
+}
+
+// tree is the node of the printed tree.
+type tree struct {
+	text     string
+	children []*tree
+}
+
+func (t *tree) add(text string) *tree {
+	child := &tree{text: text}
+	t.children = append(t.children, child)
+
+	return child
+}
+
+func (t *tree) print(w io.Writer) {
+	fmt.Fprintln(w, t.text)
+	t.printChildren(w, "")
+}
+
+func (t *tree) printChildren(w io.Writer, prefix string) {
+	for i, child := range t.children {
+		branch, indent := "├── ", "│   "
+		if i == len(t.children)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		fmt.Fprintln(w, prefix+branch+child.text)
+		child.printChildren(w, prefix+indent)
+	}
+}
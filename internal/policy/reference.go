@@ -0,0 +1,123 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy implements checks shared by the admission webhooks and the reconcilers.
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CrossNamespaceMode defines how references to Secrets in other namespaces are handled.
+type CrossNamespaceMode string
+
+const (
+	// CrossNamespaceAllow permits all references to Secrets in other namespaces.
+	CrossNamespaceAllow CrossNamespaceMode = "Allow"
+
+	// CrossNamespaceRequireGrant permits references to Secrets in other namespaces
+	// only if the namespace of the Secret contains ReferenceGrant allowing it.
+	CrossNamespaceRequireGrant CrossNamespaceMode = "RequireGrant"
+)
+
+// ParseCrossNamespaceMode converts string into CrossNamespaceMode.
+func ParseCrossNamespaceMode(s string) (CrossNamespaceMode, error) {
+	switch mode := CrossNamespaceMode(s); mode {
+	case CrossNamespaceAllow, CrossNamespaceRequireGrant:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown cross-namespace mode %q, expected one of: %s, %s",
+			s,
+			CrossNamespaceAllow,
+			CrossNamespaceRequireGrant,
+		)
+	}
+}
+
+// ReferencePolicy decides if objects may reference Secrets in other namespaces.
+// Policy with empty Mode requires ReferenceGrant.
+type ReferencePolicy struct {
+	Mode   CrossNamespaceMode
+	Reader client.Reader
+}
+
+// CheckSecretRef returns error wrapping internalerrors.ErrReferenceNotPermitted,
+// if the object from the namespace is not allowed to reference the Secret.
+// Reference without namespace points to the namespace of the object.
+// Nil policy denies all references to other namespaces.
+func (p *ReferencePolicy) CheckSecretRef(
+	ctx context.Context,
+	namespace string,
+	ref v1alpha1.SecretRef,
+) error {
+	if ref.Namespace == "" {
+		ref.Namespace = namespace
+	}
+
+	if ref.Namespace == namespace || (p != nil && p.Mode == CrossNamespaceAllow) {
+		return nil
+	}
+
+	if p != nil && p.Reader != nil {
+		grants := &v1alpha1.ReferenceGrantList{}
+		if err := p.Reader.List(ctx, grants, client.InNamespace(ref.Namespace)); err != nil {
+			return fmt.Errorf("failed to list reference grants: %w", err)
+		}
+
+		for _, grant := range grants.Items {
+			if grant.Permits(namespace, ref.Name) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: secret %s/%s from namespace %s",
+		internalerrors.ErrReferenceNotPermitted,
+		ref.Namespace,
+		ref.Name,
+		namespace,
+	)
+}
+
+// CredentialsFor returns LinodeCredentials referenced by the object from the
+// namespace. Error wrapping internalerrors.ErrReferenceNotPermitted is returned,
+// if the credentials do not allow the namespace.
+func CredentialsFor(
+	ctx context.Context,
+	reader client.Reader,
+	namespace string,
+	ref v1alpha1.CredentialsRef,
+) (*v1alpha1.LinodeCredentials, error) {
+	creds := &v1alpha1.LinodeCredentials{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: ref.Name}, creds); err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	if !creds.AllowsNamespace(namespace) {
+		return nil, fmt.Errorf("%w: credentials %s from namespace %s",
+			internalerrors.ErrReferenceNotPermitted,
+			ref.Name,
+			namespace,
+		)
+	}
+
+	return creds, nil
+}
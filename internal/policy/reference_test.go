@@ -0,0 +1,203 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReferencePolicy_CheckSecretRef(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add lke/v1alpha1 to scheme: %v", err)
+	}
+
+	reader := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&v1alpha1.ReferenceGrant{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "any-secret"},
+				Spec: v1alpha1.ReferenceGrantSpec{
+					From: []v1alpha1.ReferenceGrantFrom{{Namespace: "team-a"}},
+				},
+			},
+			&v1alpha1.ReferenceGrant{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "single-secret"},
+				Spec: v1alpha1.ReferenceGrantSpec{
+					From: []v1alpha1.ReferenceGrantFrom{{Namespace: "team-b"}},
+					To:   []v1alpha1.ReferenceGrantTo{{Name: "token-b"}},
+				},
+			},
+		).
+		Build()
+
+	for name, tc := range map[string]struct {
+		nilPolicy   bool
+		mode        CrossNamespaceMode
+		namespace   string
+		ref         v1alpha1.SecretRef
+		targetError error
+	}{
+		"allow_mode": {
+			mode:      CrossNamespaceAllow,
+			namespace: "team-c",
+			ref:       v1alpha1.SecretRef{Namespace: "shared", Name: "token"},
+		},
+		"same_namespace": {
+			mode:      CrossNamespaceRequireGrant,
+			namespace: "team-c",
+			ref:       v1alpha1.SecretRef{Namespace: "team-c", Name: "token"},
+		},
+		"empty_namespace": {
+			mode:      CrossNamespaceRequireGrant,
+			namespace: "team-c",
+			ref:       v1alpha1.SecretRef{Name: "token"},
+		},
+		"granted_namespace": {
+			mode:      CrossNamespaceRequireGrant,
+			namespace: "team-a",
+			ref:       v1alpha1.SecretRef{Namespace: "shared", Name: "token"},
+		},
+		"granted_secret": {
+			mode:      CrossNamespaceRequireGrant,
+			namespace: "team-b",
+			ref:       v1alpha1.SecretRef{Namespace: "shared", Name: "token-b"},
+		},
+		"not_granted_secret": {
+			mode:        CrossNamespaceRequireGrant,
+			namespace:   "team-b",
+			ref:         v1alpha1.SecretRef{Namespace: "shared", Name: "token"},
+			targetError: internalerrors.ErrReferenceNotPermitted,
+		},
+		"not_granted_namespace": {
+			mode:        CrossNamespaceRequireGrant,
+			namespace:   "team-c",
+			ref:         v1alpha1.SecretRef{Namespace: "shared", Name: "token"},
+			targetError: internalerrors.ErrReferenceNotPermitted,
+		},
+		"empty_mode": {
+			namespace:   "team-c",
+			ref:         v1alpha1.SecretRef{Namespace: "shared", Name: "token"},
+			targetError: internalerrors.ErrReferenceNotPermitted,
+		},
+		"nil_policy": {
+			nilPolicy:   true,
+			namespace:   "team-a",
+			ref:         v1alpha1.SecretRef{Namespace: "shared", Name: "token"},
+			targetError: internalerrors.ErrReferenceNotPermitted,
+		},
+		"nil_policy_same_namespace": {
+			nilPolicy: true,
+			namespace: "team-a",
+			ref:       v1alpha1.SecretRef{Name: "token"},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &ReferencePolicy{Mode: tc.mode, Reader: reader}
+			if tc.nilPolicy {
+				p = nil
+			}
+
+			err := p.CheckSecretRef(context.Background(), tc.namespace, tc.ref)
+			if !errors.Is(err, tc.targetError) {
+				t.Errorf("expected Error value: %#+v, got: %#+v",
+					tc.targetError, err)
+			}
+		})
+	}
+}
+
+func TestCredentialsFor(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add lke/v1alpha1 to scheme: %v", err)
+	}
+
+	reader := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&v1alpha1.LinodeCredentials{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+				Spec: v1alpha1.LinodeCredentialsSpec{
+					TokenSecretRef:    v1alpha1.SecretRef{Namespace: "operator", Name: "token"},
+					AllowedNamespaces: []string{"team-a"},
+				},
+			},
+		).
+		Build()
+
+	for name, tc := range map[string]struct {
+		namespace   string
+		ref         v1alpha1.CredentialsRef
+		notFound    bool
+		targetError error
+	}{
+		"allowed_namespace": {
+			namespace: "team-a",
+			ref:       v1alpha1.CredentialsRef{Name: "shared"},
+		},
+		"not_allowed_namespace": {
+			namespace:   "team-b",
+			ref:         v1alpha1.CredentialsRef{Name: "shared"},
+			targetError: internalerrors.ErrReferenceNotPermitted,
+		},
+		"not_found": {
+			namespace: "team-a",
+			ref:       v1alpha1.CredentialsRef{Name: "missing"},
+			notFound:  true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			creds, err := CredentialsFor(context.Background(), reader, tc.namespace, tc.ref)
+			if tc.notFound {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("expected NotFound error, got: %#+v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tc.targetError) {
+				t.Errorf("expected Error value: %#+v, got: %#+v",
+					tc.targetError, err)
+			}
+
+			if err == nil && creds.Name != tc.ref.Name {
+				t.Errorf("expected Name value: %#+v, got: %#+v",
+					tc.ref.Name, creds.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook implements admission webhooks for the lke.anza-labs.dev API group.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	internalerrors "github.com/anza-labs/lke-operator/internal/errors"
+	"github.com/anza-labs/lke-operator/internal/label"
+	"github.com/anza-labs/lke-operator/internal/policy"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-lke-anza-labs-dev-v1alpha1-lkeclusterconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=lke.anza-labs.dev,resources=lkeclusterconfigs,verbs=create;update;delete,versions=v1alpha1,name=vlkeclusterconfig.lke.anza-labs.dev,admissionReviewVersions=v1
+
+// LKEClusterConfigValidator validates LKEClusterConfig objects.
+type LKEClusterConfigValidator struct {
+	// Reader is used to get LinodeCredentials referenced by the objects, and the
+	// other objects whose labels must not be reused.
+	Reader client.Reader
+
+	ReferencePolicy *policy.ReferencePolicy
+
+	// Labels renders the labels of the LKE clusters, which are validated.
+	Labels label.Template
+}
+
+var _ admission.CustomValidator = (*LKEClusterConfigValidator)(nil)
+
+// SetupWebhookWithManager registers the webhook with the Manager.
+func (v *LKEClusterConfigValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.LKEClusterConfig{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *LKEClusterConfigValidator) ValidateCreate(
+	ctx context.Context,
+	obj runtime.Object,
+) (admission.Warnings, error) {
+	lke, ok := obj.(*v1alpha1.LKEClusterConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected LKEClusterConfig, got %T", obj)
+	}
+
+	return v.validate(ctx, lke)
+}
+
+// ValidateUpdate implements admission.CustomValidator. Only the updates changing
+// the spec are validated, so that revoked grants or credentials do not block the
+// status updates and the removal of the finalizer by the controller.
+func (v *LKEClusterConfigValidator) ValidateUpdate(
+	ctx context.Context,
+	oldObj, newObj runtime.Object,
+) (admission.Warnings, error) {
+	old, ok := oldObj.(*v1alpha1.LKEClusterConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected LKEClusterConfig, got %T", oldObj)
+	}
+
+	lke, ok := newObj.(*v1alpha1.LKEClusterConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected LKEClusterConfig, got %T", newObj)
+	}
+
+	if lke.DeletionTimestamp != nil || equality.Semantic.DeepEqual(old.Spec, lke.Spec) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, lke)
+}
+
+// ValidateDelete implements admission.CustomValidator. Deletion of objects
+// with enabled deletion protection is forbidden.
+func (v *LKEClusterConfigValidator) ValidateDelete(
+	_ context.Context,
+	obj runtime.Object,
+) (admission.Warnings, error) {
+	lke, ok := obj.(*v1alpha1.LKEClusterConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected LKEClusterConfig, got %T", obj)
+	}
+
+	if lke.Spec.Protection != nil && lke.Spec.Protection.Deletion {
+		return nil, apierrors.NewForbidden(
+			v1alpha1.GroupVersion.WithResource("lkeclusterconfigs").GroupResource(),
+			lke.Name,
+			errors.New("deletion protection is enabled, disable spec.protection.deletion first"),
+		)
+	}
+
+	return nil, nil
+}
+
+// labelOwner returns the other object rendering the same label of the LKE cluster,
+// or nil if there is none. Labels must be unique within the Linode account, and
+// objects using different accounts are not told apart, so the labels must be unique
+// across all objects.
+func (v *LKEClusterConfigValidator) labelOwner(
+	ctx context.Context,
+	lke *v1alpha1.LKEClusterConfig,
+	rendered string,
+) (*v1alpha1.LKEClusterConfig, error) {
+	lkes := &v1alpha1.LKEClusterConfigList{}
+	if err := v.Reader.List(ctx, lkes); err != nil {
+		return nil, fmt.Errorf("failed to list LKEClusterConfigs: %w", err)
+	}
+
+	for i := range lkes.Items {
+		other := &lkes.Items[i]
+		if other.Namespace == lke.Namespace && other.Name == lke.Name {
+			continue
+		}
+
+		// invalid labels of other objects are reported for them
+		if label, err := v.Labels.Render(other); err == nil && label == rendered {
+			return other, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// validateLabel checks that the label rendered for the object is valid, and not
+// used by another object.
+func (v *LKEClusterConfigValidator) validateLabel(
+	ctx context.Context,
+	lke *v1alpha1.LKEClusterConfig,
+) (field.ErrorList, error) {
+	rendered, err := v.Labels.Render(lke)
+	if err != nil {
+		return field.ErrorList{field.Invalid(
+			field.NewPath("spec", "label"),
+			lke.Spec.Label,
+			err.Error(),
+		)}, nil
+	}
+
+	if v.Reader == nil {
+		return nil, nil
+	}
+
+	owner, err := v.labelOwner(ctx, lke, rendered)
+	if err != nil || owner == nil {
+		return nil, err
+	}
+
+	return field.ErrorList{field.Invalid(
+		field.NewPath("spec", "label"),
+		lke.Spec.Label,
+		fmt.Sprintf("label %q is already used by LKEClusterConfig %s/%s",
+			rendered, owner.Namespace, owner.Name),
+	)}, nil
+}
+
+func (v *LKEClusterConfigValidator) validate(
+	ctx context.Context,
+	lke *v1alpha1.LKEClusterConfig,
+) (admission.Warnings, error) {
+	var (
+		warnings admission.Warnings
+		errs     field.ErrorList
+	)
+
+	// the label of the existing LKE cluster kept by the object is not rendered
+	if !v.Labels.KeepsExisting(lke) {
+		labelErrs, err := v.validateLabel(ctx, lke)
+		if err != nil {
+			return nil, err
+		}
+
+		errs = append(errs, labelErrs...)
+	}
+
+	if ref := lke.Spec.TokenSecretRef; ref != nil {
+		if err := v.ReferencePolicy.CheckSecretRef(ctx, lke.Namespace, *ref); err != nil {
+			if !errors.Is(err, internalerrors.ErrReferenceNotPermitted) {
+				return nil, err
+			}
+
+			errs = append(errs, field.Forbidden(
+				field.NewPath("spec", "tokenSecretRef"),
+				err.Error(),
+			))
+		}
+	}
+
+	if ref := lke.Spec.CredentialsRef; ref != nil && v.Reader != nil {
+		if _, err := policy.CredentialsFor(ctx, v.Reader, lke.Namespace, *ref); err != nil {
+			switch {
+			case apierrors.IsNotFound(err):
+				warnings = append(warnings, fmt.Sprintf("LinodeCredentials %s not found", ref.Name))
+
+			case errors.Is(err, internalerrors.ErrReferenceNotPermitted):
+				errs = append(errs, field.Forbidden(
+					field.NewPath("spec", "credentialsRef"),
+					err.Error(),
+				))
+
+			default:
+				return nil, err
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return warnings, nil
+	}
+
+	return warnings, apierrors.NewInvalid(
+		v1alpha1.GroupVersion.WithKind("LKEClusterConfig").GroupKind(),
+		lke.Name,
+		errs,
+	)
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anza-labs/lke-operator/api/v1alpha1"
+	"github.com/anza-labs/lke-operator/internal/policy"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLKEClusterConfigValidator_tokenSecretRef(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add lke/v1alpha1 to scheme: %v", err)
+	}
+
+	reader := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&v1alpha1.ReferenceGrant{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "team-a"},
+				Spec: v1alpha1.ReferenceGrantSpec{
+					From: []v1alpha1.ReferenceGrantFrom{{Namespace: "team-a"}},
+				},
+			},
+		).
+		Build()
+
+	for name, tc := range map[string]struct {
+		namespace string
+		ref       v1alpha1.SecretRef
+		invalid   bool
+	}{
+		"same_namespace": {
+			namespace: "team-b",
+			ref:       v1alpha1.SecretRef{Namespace: "team-b", Name: "token"},
+		},
+		"granted": {
+			namespace: "team-a",
+			ref:       v1alpha1.SecretRef{Namespace: "shared", Name: "token"},
+		},
+		"denied": {
+			namespace: "team-b",
+			ref:       v1alpha1.SecretRef{Namespace: "shared", Name: "token"},
+			invalid:   true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := &LKEClusterConfigValidator{
+				Reader: reader,
+				ReferencePolicy: &policy.ReferencePolicy{
+					Mode:   policy.CrossNamespaceRequireGrant,
+					Reader: reader,
+				},
+			}
+
+			lke := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: tc.namespace, Name: "cluster"},
+				Spec: v1alpha1.LKEClusterConfigSpec{
+					TokenSecretRef: &tc.ref,
+				},
+			}
+
+			for op, validate := range map[string]func() error{
+				"create": func() error {
+					_, err := v.ValidateCreate(context.Background(), lke)
+					return err
+				},
+				"update": func() error {
+					old := lke.DeepCopy()
+					old.Spec.TokenSecretRef = nil
+
+					_, err := v.ValidateUpdate(context.Background(), old, lke)
+					return err
+				},
+			} {
+				err := validate()
+				if tc.invalid != apierrors.IsInvalid(err) {
+					t.Errorf("expected %s to be invalid: %#+v, got: %#+v",
+						op, tc.invalid, err)
+				}
+
+				if !tc.invalid && err != nil {
+					t.Errorf("expected %s to succeed, got: %#+v", op, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLKEClusterConfigValidator_label(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add lke/v1alpha1 to scheme: %v", err)
+	}
+
+	reader := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&v1alpha1.LKEClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "cluster"},
+			},
+			&v1alpha1.LKEClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "static"},
+				Spec:       v1alpha1.LKEClusterConfigSpec{Label: "shared"},
+			},
+		).
+		Build()
+
+	for name, tc := range map[string]struct {
+		namespace string
+		name      string
+		label     string
+		clusterID *int
+		invalid   bool
+	}{
+		"same_object": {
+			namespace: "team-a",
+			name:      "cluster",
+		},
+		"other_namespace": {
+			namespace: "team-b",
+			name:      "cluster",
+		},
+		"duplicate_default": {
+			namespace: "team-b",
+			name:      "other",
+			label:     "team-a-cluster",
+			invalid:   true,
+		},
+		"duplicate_static": {
+			namespace: "team-b",
+			name:      "cluster",
+			label:     "shared",
+			invalid:   true,
+		},
+		"too_long": {
+			namespace: "platform-engineering-team",
+			name:      "production-cluster",
+			invalid:   true,
+		},
+		"too_long_existing_cluster": {
+			namespace: "platform-engineering-team",
+			name:      "production-cluster",
+			clusterID: mkptr(1),
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := &LKEClusterConfigValidator{
+				Reader:          reader,
+				ReferencePolicy: &policy.ReferencePolicy{Reader: reader},
+			}
+
+			lke := &v1alpha1.LKEClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: tc.namespace, Name: tc.name},
+				Spec:       v1alpha1.LKEClusterConfigSpec{Label: tc.label, Region: "us-east"},
+				Status:     v1alpha1.LKEClusterConfigStatus{ClusterID: tc.clusterID},
+			}
+
+			for op, validate := range map[string]func() error{
+				"create": func() error {
+					_, err := v.ValidateCreate(context.Background(), lke)
+					return err
+				},
+				"update": func() error {
+					old := lke.DeepCopy()
+					old.Spec.Region = "us-west"
+
+					_, err := v.ValidateUpdate(context.Background(), old, lke)
+					return err
+				},
+			} {
+				err := validate()
+				if tc.invalid != apierrors.IsInvalid(err) {
+					t.Errorf("expected %s to be invalid: %#+v, got: %#+v",
+						op, tc.invalid, err)
+				}
+
+				if !tc.invalid && err != nil {
+					t.Errorf("expected %s to succeed, got: %#+v", op, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLKEClusterConfigValidator_update(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add lke/v1alpha1 to scheme: %v", err)
+	}
+
+	// the grant referenced by the object was deleted
+	reader := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	old := &v1alpha1.LKEClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "team-a",
+			Name:       "cluster",
+			Finalizers: []string{"lke.anza-labs.dev/finalizer"},
+		},
+		Spec: v1alpha1.LKEClusterConfigSpec{
+			TokenSecretRef: &v1alpha1.SecretRef{Namespace: "shared", Name: "token"},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		update  func(lke *v1alpha1.LKEClusterConfig)
+		invalid bool
+	}{
+		"status_only": {
+			update: func(lke *v1alpha1.LKEClusterConfig) {
+				lke.Status.Phase = mkptr(v1alpha1.PhaseActive)
+			},
+		},
+		"finalizer_removal": {
+			update: func(lke *v1alpha1.LKEClusterConfig) {
+				lke.DeletionTimestamp = mkptr(metav1.Now())
+				lke.Finalizers = nil
+			},
+		},
+		"spec_change": {
+			update: func(lke *v1alpha1.LKEClusterConfig) {
+				lke.Spec.Region = "us-east"
+			},
+			invalid: true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := &LKEClusterConfigValidator{
+				Reader: reader,
+				ReferencePolicy: &policy.ReferencePolicy{
+					Mode:   policy.CrossNamespaceRequireGrant,
+					Reader: reader,
+				},
+			}
+
+			lke := old.DeepCopy()
+			tc.update(lke)
+
+			_, err := v.ValidateUpdate(context.Background(), old, lke)
+			if tc.invalid != apierrors.IsInvalid(err) {
+				t.Errorf("expected update to be invalid: %#+v, got: %#+v", tc.invalid, err)
+			}
+
+			if !tc.invalid && err != nil {
+				t.Errorf("expected update to succeed, got: %#+v", err)
+			}
+		})
+	}
+}
+
+func mkptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	lkev1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+// LKEClusterConfigSpecApplyConfiguration represents an declarative configuration of the LKEClusterConfigSpec type for use
+// with apply.
+type LKEClusterConfigSpecApplyConfiguration struct {
+	Region             *string                                  `json:"region,omitempty"`
+	Label              *string                                  `json:"label,omitempty"`
+	TokenSecretRef     *SecretRefApplyConfiguration             `json:"tokenSecretRef,omitempty"`
+	CredentialsRef     *CredentialsRefApplyConfiguration        `json:"credentialsRef,omitempty"`
+	HighAvailability   *bool                                    `json:"highAvailability,omitempty"`
+	NodePools          map[string]LKENodePoolApplyConfiguration `json:"nodePools,omitempty"`
+	KubernetesVersion  *string                                  `json:"kubernetesVersion,omitempty"`
+	DriftPolicy        *lkev1alpha1.DriftPolicy                 `json:"driftPolicy,omitempty"`
+	UnmanagedNodePools *lkev1alpha1.UnmanagedNodePoolsPolicy    `json:"unmanagedNodePools,omitempty"`
+	RequeueIntervals   *RequeueIntervalsApplyConfiguration      `json:"requeueIntervals,omitempty"`
+	Protection         *ProtectionApplyConfiguration            `json:"protection,omitempty"`
+	PreDeleteCleanup   *PreDeleteCleanupApplyConfiguration      `json:"preDeleteCleanup,omitempty"`
+}
+
+// LKEClusterConfigSpecApplyConfiguration constructs an declarative configuration of the LKEClusterConfigSpec type for use with
+// apply.
+func LKEClusterConfigSpec() *LKEClusterConfigSpecApplyConfiguration {
+	return &LKEClusterConfigSpecApplyConfiguration{}
+}
+
+// WithRegion sets the Region field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Region field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithRegion(value string) *LKEClusterConfigSpecApplyConfiguration {
+	b.Region = &value
+	return b
+}
+
+// WithLabel sets the Label field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Label field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithLabel(value string) *LKEClusterConfigSpecApplyConfiguration {
+	b.Label = &value
+	return b
+}
+
+// WithTokenSecretRef sets the TokenSecretRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TokenSecretRef field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithTokenSecretRef(value *SecretRefApplyConfiguration) *LKEClusterConfigSpecApplyConfiguration {
+	b.TokenSecretRef = value
+	return b
+}
+
+// WithCredentialsRef sets the CredentialsRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CredentialsRef field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithCredentialsRef(value *CredentialsRefApplyConfiguration) *LKEClusterConfigSpecApplyConfiguration {
+	b.CredentialsRef = value
+	return b
+}
+
+// WithHighAvailability sets the HighAvailability field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the HighAvailability field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithHighAvailability(value bool) *LKEClusterConfigSpecApplyConfiguration {
+	b.HighAvailability = &value
+	return b
+}
+
+// WithNodePools puts the entries into the NodePools field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the NodePools field,
+// overwriting an existing map entries in NodePools field with the same key.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithNodePools(entries map[string]LKENodePoolApplyConfiguration) *LKEClusterConfigSpecApplyConfiguration {
+	if b.NodePools == nil && len(entries) > 0 {
+		b.NodePools = make(map[string]LKENodePoolApplyConfiguration, len(entries))
+	}
+	for k, v := range entries {
+		b.NodePools[k] = v
+	}
+	return b
+}
+
+// WithKubernetesVersion sets the KubernetesVersion field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the KubernetesVersion field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithKubernetesVersion(value string) *LKEClusterConfigSpecApplyConfiguration {
+	b.KubernetesVersion = &value
+	return b
+}
+
+// WithDriftPolicy sets the DriftPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DriftPolicy field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithDriftPolicy(value lkev1alpha1.DriftPolicy) *LKEClusterConfigSpecApplyConfiguration {
+	b.DriftPolicy = &value
+	return b
+}
+
+// WithUnmanagedNodePools sets the UnmanagedNodePools field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UnmanagedNodePools field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithUnmanagedNodePools(value lkev1alpha1.UnmanagedNodePoolsPolicy) *LKEClusterConfigSpecApplyConfiguration {
+	b.UnmanagedNodePools = &value
+	return b
+}
+
+// WithRequeueIntervals sets the RequeueIntervals field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RequeueIntervals field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithRequeueIntervals(value *RequeueIntervalsApplyConfiguration) *LKEClusterConfigSpecApplyConfiguration {
+	b.RequeueIntervals = value
+	return b
+}
+
+// WithProtection sets the Protection field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Protection field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithProtection(value *ProtectionApplyConfiguration) *LKEClusterConfigSpecApplyConfiguration {
+	b.Protection = value
+	return b
+}
+
+// WithPreDeleteCleanup sets the PreDeleteCleanup field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PreDeleteCleanup field is set to the value of the last call.
+func (b *LKEClusterConfigSpecApplyConfiguration) WithPreDeleteCleanup(value *PreDeleteCleanupApplyConfiguration) *LKEClusterConfigSpecApplyConfiguration {
+	b.PreDeleteCleanup = value
+	return b
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LKEClusterConfigStatusApplyConfiguration represents an declarative configuration of the LKEClusterConfigStatus type for use
+// with apply.
+type LKEClusterConfigStatusApplyConfiguration struct {
+	Phase               *v1alpha1.Phase                             `json:"phase,omitempty"`
+	ClusterID           *int                                        `json:"clusterID,omitempty"`
+	NodePoolStatuses    map[string]NodePoolStatusApplyConfiguration `json:"nodePoolStatuses,omitempty"`
+	FailureMessage      *string                                     `json:"failureMessage,omitempty"`
+	UnmanagedNodePools  []NodePoolStatusApplyConfiguration          `json:"unmanagedNodePools,omitempty"`
+	PendingApproval     *PlanApplyConfiguration                     `json:"pendingApproval,omitempty"`
+	AppliedHash         *string                                     `json:"appliedHash,omitempty"`
+	DeletionRequestedAt *v1.Time                                    `json:"deletionRequestedAt,omitempty"`
+	Conditions          []v1.Condition                              `json:"conditions,omitempty"`
+}
+
+// LKEClusterConfigStatusApplyConfiguration constructs an declarative configuration of the LKEClusterConfigStatus type for use with
+// apply.
+func LKEClusterConfigStatus() *LKEClusterConfigStatusApplyConfiguration {
+	return &LKEClusterConfigStatusApplyConfiguration{}
+}
+
+// WithPhase sets the Phase field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Phase field is set to the value of the last call.
+func (b *LKEClusterConfigStatusApplyConfiguration) WithPhase(value v1alpha1.Phase) *LKEClusterConfigStatusApplyConfiguration {
+	b.Phase = &value
+	return b
+}
+
+// WithClusterID sets the ClusterID field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ClusterID field is set to the value of the last call.
+func (b *LKEClusterConfigStatusApplyConfiguration) WithClusterID(value int) *LKEClusterConfigStatusApplyConfiguration {
+	b.ClusterID = &value
+	return b
+}
+
+// WithNodePoolStatuses puts the entries into the NodePoolStatuses field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the NodePoolStatuses field,
+// overwriting an existing map entries in NodePoolStatuses field with the same key.
+func (b *LKEClusterConfigStatusApplyConfiguration) WithNodePoolStatuses(entries map[string]NodePoolStatusApplyConfiguration) *LKEClusterConfigStatusApplyConfiguration {
+	if b.NodePoolStatuses == nil && len(entries) > 0 {
+		b.NodePoolStatuses = make(map[string]NodePoolStatusApplyConfiguration, len(entries))
+	}
+	for k, v := range entries {
+		b.NodePoolStatuses[k] = v
+	}
+	return b
+}
+
+// WithFailureMessage sets the FailureMessage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FailureMessage field is set to the value of the last call.
+func (b *LKEClusterConfigStatusApplyConfiguration) WithFailureMessage(value string) *LKEClusterConfigStatusApplyConfiguration {
+	b.FailureMessage = &value
+	return b
+}
+
+// WithUnmanagedNodePools adds the given value to the UnmanagedNodePools field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the UnmanagedNodePools field.
+func (b *LKEClusterConfigStatusApplyConfiguration) WithUnmanagedNodePools(values ...*NodePoolStatusApplyConfiguration) *LKEClusterConfigStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithUnmanagedNodePools")
+		}
+		b.UnmanagedNodePools = append(b.UnmanagedNodePools, *values[i])
+	}
+	return b
+}
+
+// WithPendingApproval sets the PendingApproval field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PendingApproval field is set to the value of the last call.
+func (b *LKEClusterConfigStatusApplyConfiguration) WithPendingApproval(value *PlanApplyConfiguration) *LKEClusterConfigStatusApplyConfiguration {
+	b.PendingApproval = value
+	return b
+}
+
+// WithAppliedHash sets the AppliedHash field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AppliedHash field is set to the value of the last call.
+func (b *LKEClusterConfigStatusApplyConfiguration) WithAppliedHash(value string) *LKEClusterConfigStatusApplyConfiguration {
+	b.AppliedHash = &value
+	return b
+}
+
+// WithDeletionRequestedAt sets the DeletionRequestedAt field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DeletionRequestedAt field is set to the value of the last call.
+func (b *LKEClusterConfigStatusApplyConfiguration) WithDeletionRequestedAt(value v1.Time) *LKEClusterConfigStatusApplyConfiguration {
+	b.DeletionRequestedAt = &value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *LKEClusterConfigStatusApplyConfiguration) WithConditions(values ...v1.Condition) *LKEClusterConfigStatusApplyConfiguration {
+	for i := range values {
+		b.Conditions = append(b.Conditions, values[i])
+	}
+	return b
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// LKENodePoolApplyConfiguration represents an declarative configuration of the LKENodePool type for use
+// with apply.
+type LKENodePoolApplyConfiguration struct {
+	NodeCount  *int                                     `json:"nodeCount,omitempty"`
+	LinodeType *string                                  `json:"linodeType,omitempty"`
+	Autoscaler *LKENodePoolAutoscalerApplyConfiguration `json:"autoscaler,omitempty"`
+}
+
+// LKENodePoolApplyConfiguration constructs an declarative configuration of the LKENodePool type for use with
+// apply.
+func LKENodePool() *LKENodePoolApplyConfiguration {
+	return &LKENodePoolApplyConfiguration{}
+}
+
+// WithNodeCount sets the NodeCount field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NodeCount field is set to the value of the last call.
+func (b *LKENodePoolApplyConfiguration) WithNodeCount(value int) *LKENodePoolApplyConfiguration {
+	b.NodeCount = &value
+	return b
+}
+
+// WithLinodeType sets the LinodeType field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LinodeType field is set to the value of the last call.
+func (b *LKENodePoolApplyConfiguration) WithLinodeType(value string) *LKENodePoolApplyConfiguration {
+	b.LinodeType = &value
+	return b
+}
+
+// WithAutoscaler sets the Autoscaler field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Autoscaler field is set to the value of the last call.
+func (b *LKENodePoolApplyConfiguration) WithAutoscaler(value *LKENodePoolAutoscalerApplyConfiguration) *LKENodePoolApplyConfiguration {
+	b.Autoscaler = value
+	return b
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// NodePoolStatusApplyConfiguration represents an declarative configuration of the NodePoolStatus type for use
+// with apply.
+type NodePoolStatusApplyConfiguration struct {
+	ID              *int                           `json:"id,omitempty"`
+	NodePoolDetails *LKENodePoolApplyConfiguration `json:"details,omitempty"`
+}
+
+// NodePoolStatusApplyConfiguration constructs an declarative configuration of the NodePoolStatus type for use with
+// apply.
+func NodePoolStatus() *NodePoolStatusApplyConfiguration {
+	return &NodePoolStatusApplyConfiguration{}
+}
+
+// WithID sets the ID field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ID field is set to the value of the last call.
+func (b *NodePoolStatusApplyConfiguration) WithID(value int) *NodePoolStatusApplyConfiguration {
+	b.ID = &value
+	return b
+}
+
+// WithNodePoolDetails sets the NodePoolDetails field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NodePoolDetails field is set to the value of the last call.
+func (b *NodePoolStatusApplyConfiguration) WithNodePoolDetails(value *LKENodePoolApplyConfiguration) *NodePoolStatusApplyConfiguration {
+	b.NodePoolDetails = value
+	return b
+}
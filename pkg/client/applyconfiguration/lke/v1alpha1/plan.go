@@ -0,0 +1,52 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// PlanApplyConfiguration represents an declarative configuration of the Plan type for use
+// with apply.
+type PlanApplyConfiguration struct {
+	Hash       *string                              `json:"hash,omitempty"`
+	Operations []PlannedOperationApplyConfiguration `json:"operations,omitempty"`
+}
+
+// PlanApplyConfiguration constructs an declarative configuration of the Plan type for use with
+// apply.
+func Plan() *PlanApplyConfiguration {
+	return &PlanApplyConfiguration{}
+}
+
+// WithHash sets the Hash field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Hash field is set to the value of the last call.
+func (b *PlanApplyConfiguration) WithHash(value string) *PlanApplyConfiguration {
+	b.Hash = &value
+	return b
+}
+
+// WithOperations adds the given value to the Operations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Operations field.
+func (b *PlanApplyConfiguration) WithOperations(values ...*PlannedOperationApplyConfiguration) *PlanApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithOperations")
+		}
+		b.Operations = append(b.Operations, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+// PlannedOperationApplyConfiguration represents an declarative configuration of the PlannedOperation type for use
+// with apply.
+type PlannedOperationApplyConfiguration struct {
+	Kind        *v1alpha1.OperationKind `json:"kind,omitempty"`
+	Description *string                 `json:"description,omitempty"`
+}
+
+// PlannedOperationApplyConfiguration constructs an declarative configuration of the PlannedOperation type for use with
+// apply.
+func PlannedOperation() *PlannedOperationApplyConfiguration {
+	return &PlannedOperationApplyConfiguration{}
+}
+
+// WithKind sets the Kind field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Kind field is set to the value of the last call.
+func (b *PlannedOperationApplyConfiguration) WithKind(value v1alpha1.OperationKind) *PlannedOperationApplyConfiguration {
+	b.Kind = &value
+	return b
+}
+
+// WithDescription sets the Description field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Description field is set to the value of the last call.
+func (b *PlannedOperationApplyConfiguration) WithDescription(value string) *PlannedOperationApplyConfiguration {
+	b.Description = &value
+	return b
+}
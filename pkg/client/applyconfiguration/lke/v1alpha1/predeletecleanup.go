@@ -0,0 +1,60 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PreDeleteCleanupApplyConfiguration represents an declarative configuration of the PreDeleteCleanup type for use
+// with apply.
+type PreDeleteCleanupApplyConfiguration struct {
+	LoadBalancers *bool        `json:"loadBalancers,omitempty"`
+	Volumes       *bool        `json:"volumes,omitempty"`
+	Timeout       *v1.Duration `json:"timeout,omitempty"`
+}
+
+// PreDeleteCleanupApplyConfiguration constructs an declarative configuration of the PreDeleteCleanup type for use with
+// apply.
+func PreDeleteCleanup() *PreDeleteCleanupApplyConfiguration {
+	return &PreDeleteCleanupApplyConfiguration{}
+}
+
+// WithLoadBalancers sets the LoadBalancers field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LoadBalancers field is set to the value of the last call.
+func (b *PreDeleteCleanupApplyConfiguration) WithLoadBalancers(value bool) *PreDeleteCleanupApplyConfiguration {
+	b.LoadBalancers = &value
+	return b
+}
+
+// WithVolumes sets the Volumes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Volumes field is set to the value of the last call.
+func (b *PreDeleteCleanupApplyConfiguration) WithVolumes(value bool) *PreDeleteCleanupApplyConfiguration {
+	b.Volumes = &value
+	return b
+}
+
+// WithTimeout sets the Timeout field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Timeout field is set to the value of the last call.
+func (b *PreDeleteCleanupApplyConfiguration) WithTimeout(value v1.Duration) *PreDeleteCleanupApplyConfiguration {
+	b.Timeout = &value
+	return b
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+)
+
+// ProtectionApplyConfiguration represents an declarative configuration of the Protection type for use
+// with apply.
+type ProtectionApplyConfiguration struct {
+	Deletion        *bool                    `json:"deletion,omitempty"`
+	RequireApproval []v1alpha1.OperationKind `json:"requireApproval,omitempty"`
+}
+
+// ProtectionApplyConfiguration constructs an declarative configuration of the Protection type for use with
+// apply.
+func Protection() *ProtectionApplyConfiguration {
+	return &ProtectionApplyConfiguration{}
+}
+
+// WithDeletion sets the Deletion field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Deletion field is set to the value of the last call.
+func (b *ProtectionApplyConfiguration) WithDeletion(value bool) *ProtectionApplyConfiguration {
+	b.Deletion = &value
+	return b
+}
+
+// WithRequireApproval adds the given value to the RequireApproval field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the RequireApproval field.
+func (b *ProtectionApplyConfiguration) WithRequireApproval(values ...v1alpha1.OperationKind) *ProtectionApplyConfiguration {
+	for i := range values {
+		b.RequireApproval = append(b.RequireApproval, values[i])
+	}
+	return b
+}
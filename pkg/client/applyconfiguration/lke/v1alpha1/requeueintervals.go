@@ -0,0 +1,69 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RequeueIntervalsApplyConfiguration represents an declarative configuration of the RequeueIntervals type for use
+// with apply.
+type RequeueIntervalsApplyConfiguration struct {
+	Provisioning *v1.Duration `json:"provisioning,omitempty"`
+	Updating     *v1.Duration `json:"updating,omitempty"`
+	Deleting     *v1.Duration `json:"deleting,omitempty"`
+	Resync       *v1.Duration `json:"resync,omitempty"`
+}
+
+// RequeueIntervalsApplyConfiguration constructs an declarative configuration of the RequeueIntervals type for use with
+// apply.
+func RequeueIntervals() *RequeueIntervalsApplyConfiguration {
+	return &RequeueIntervalsApplyConfiguration{}
+}
+
+// WithProvisioning sets the Provisioning field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Provisioning field is set to the value of the last call.
+func (b *RequeueIntervalsApplyConfiguration) WithProvisioning(value v1.Duration) *RequeueIntervalsApplyConfiguration {
+	b.Provisioning = &value
+	return b
+}
+
+// WithUpdating sets the Updating field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Updating field is set to the value of the last call.
+func (b *RequeueIntervalsApplyConfiguration) WithUpdating(value v1.Duration) *RequeueIntervalsApplyConfiguration {
+	b.Updating = &value
+	return b
+}
+
+// WithDeleting sets the Deleting field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Deleting field is set to the value of the last call.
+func (b *RequeueIntervalsApplyConfiguration) WithDeleting(value v1.Duration) *RequeueIntervalsApplyConfiguration {
+	b.Deleting = &value
+	return b
+}
+
+// WithResync sets the Resync field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Resync field is set to the value of the last call.
+func (b *RequeueIntervalsApplyConfiguration) WithResync(value v1.Duration) *RequeueIntervalsApplyConfiguration {
+	b.Resync = &value
+	return b
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	v1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+	lkev1alpha1 "github.com/anza-labs/lke-operator/pkg/client/applyconfiguration/lke/v1alpha1"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=lke.anza-labs.dev, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("CredentialsRef"):
+		return &lkev1alpha1.CredentialsRefApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("LKEClusterConfig"):
+		return &lkev1alpha1.LKEClusterConfigApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("LKEClusterConfigSpec"):
+		return &lkev1alpha1.LKEClusterConfigSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("LKEClusterConfigStatus"):
+		return &lkev1alpha1.LKEClusterConfigStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("LKENodePool"):
+		return &lkev1alpha1.LKENodePoolApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("LKENodePoolAutoscaler"):
+		return &lkev1alpha1.LKENodePoolAutoscalerApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("NodePoolStatus"):
+		return &lkev1alpha1.NodePoolStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Plan"):
+		return &lkev1alpha1.PlanApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PlannedOperation"):
+		return &lkev1alpha1.PlannedOperationApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PreDeleteCleanup"):
+		return &lkev1alpha1.PreDeleteCleanupApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Protection"):
+		return &lkev1alpha1.ProtectionApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("RequeueIntervals"):
+		return &lkev1alpha1.RequeueIntervalsApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("SecretRef"):
+		return &lkev1alpha1.SecretRefApplyConfiguration{}
+
+	}
+	return nil
+}
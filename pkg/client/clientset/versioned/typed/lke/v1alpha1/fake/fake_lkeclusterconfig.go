@@ -0,0 +1,154 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+
+	v1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+	lkev1alpha1 "github.com/anza-labs/lke-operator/pkg/client/applyconfiguration/lke/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeLKEClusterConfigs implements LKEClusterConfigInterface
+type FakeLKEClusterConfigs struct {
+	Fake *FakeLkeV1alpha1
+	ns   string
+}
+
+var lkeclusterconfigsResource = schema.GroupVersionResource{Group: "lke.anza-labs.dev", Version: "v1alpha1", Resource: "lkeclusterconfigs"}
+
+var lkeclusterconfigsKind = schema.GroupVersionKind{Group: "lke.anza-labs.dev", Version: "v1alpha1", Kind: "LKEClusterConfig"}
+
+// Get takes name of the lKEClusterConfig, and returns the corresponding lKEClusterConfig object, and an error if there is any.
+func (c *FakeLKEClusterConfigs) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.LKEClusterConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(lkeclusterconfigsResource, c.ns, name), &v1alpha1.LKEClusterConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.LKEClusterConfig), err
+}
+
+// List takes label and field selectors, and returns the list of LKEClusterConfigs that match those selectors.
+func (c *FakeLKEClusterConfigs) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.LKEClusterConfigList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(lkeclusterconfigsResource, lkeclusterconfigsKind, c.ns, opts), &v1alpha1.LKEClusterConfigList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.LKEClusterConfigList{ListMeta: obj.(*v1alpha1.LKEClusterConfigList).ListMeta}
+	for _, item := range obj.(*v1alpha1.LKEClusterConfigList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested lKEClusterConfigs.
+func (c *FakeLKEClusterConfigs) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(lkeclusterconfigsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a lKEClusterConfig and creates it.  Returns the server's representation of the lKEClusterConfig, and an error, if there is any.
+func (c *FakeLKEClusterConfigs) Create(ctx context.Context, lKEClusterConfig *v1alpha1.LKEClusterConfig, opts v1.CreateOptions) (result *v1alpha1.LKEClusterConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(lkeclusterconfigsResource, c.ns, lKEClusterConfig), &v1alpha1.LKEClusterConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.LKEClusterConfig), err
+}
+
+// Update takes the representation of a lKEClusterConfig and updates it. Returns the server's representation of the lKEClusterConfig, and an error, if there is any.
+func (c *FakeLKEClusterConfigs) Update(ctx context.Context, lKEClusterConfig *v1alpha1.LKEClusterConfig, opts v1.UpdateOptions) (result *v1alpha1.LKEClusterConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(lkeclusterconfigsResource, c.ns, lKEClusterConfig), &v1alpha1.LKEClusterConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.LKEClusterConfig), err
+}
+
+// Delete takes name of the lKEClusterConfig and deletes it. Returns an error if one occurs.
+func (c *FakeLKEClusterConfigs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(lkeclusterconfigsResource, c.ns, name, opts), &v1alpha1.LKEClusterConfig{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeLKEClusterConfigs) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(lkeclusterconfigsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.LKEClusterConfigList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched lKEClusterConfig.
+func (c *FakeLKEClusterConfigs) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.LKEClusterConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(lkeclusterconfigsResource, c.ns, name, pt, data, subresources...), &v1alpha1.LKEClusterConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.LKEClusterConfig), err
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied lKEClusterConfig.
+func (c *FakeLKEClusterConfigs) Apply(ctx context.Context, lKEClusterConfig *lkev1alpha1.LKEClusterConfigApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.LKEClusterConfig, err error) {
+	if lKEClusterConfig == nil {
+		return nil, fmt.Errorf("lKEClusterConfig provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(lKEClusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	name := lKEClusterConfig.Name
+	if name == nil {
+		return nil, fmt.Errorf("lKEClusterConfig.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(lkeclusterconfigsResource, c.ns, *name, types.ApplyPatchType, data), &v1alpha1.LKEClusterConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.LKEClusterConfig), err
+}
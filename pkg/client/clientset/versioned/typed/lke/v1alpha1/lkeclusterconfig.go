@@ -0,0 +1,207 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+	"time"
+
+	v1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+	lkev1alpha1 "github.com/anza-labs/lke-operator/pkg/client/applyconfiguration/lke/v1alpha1"
+	scheme "github.com/anza-labs/lke-operator/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// LKEClusterConfigsGetter has a method to return a LKEClusterConfigInterface.
+// A group's client should implement this interface.
+type LKEClusterConfigsGetter interface {
+	LKEClusterConfigs(namespace string) LKEClusterConfigInterface
+}
+
+// LKEClusterConfigInterface has methods to work with LKEClusterConfig resources.
+type LKEClusterConfigInterface interface {
+	Create(ctx context.Context, lKEClusterConfig *v1alpha1.LKEClusterConfig, opts v1.CreateOptions) (*v1alpha1.LKEClusterConfig, error)
+	Update(ctx context.Context, lKEClusterConfig *v1alpha1.LKEClusterConfig, opts v1.UpdateOptions) (*v1alpha1.LKEClusterConfig, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.LKEClusterConfig, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.LKEClusterConfigList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.LKEClusterConfig, err error)
+	Apply(ctx context.Context, lKEClusterConfig *lkev1alpha1.LKEClusterConfigApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.LKEClusterConfig, err error)
+	LKEClusterConfigExpansion
+}
+
+// lKEClusterConfigs implements LKEClusterConfigInterface
+type lKEClusterConfigs struct {
+	client rest.Interface
+	ns     string
+}
+
+// newLKEClusterConfigs returns a LKEClusterConfigs
+func newLKEClusterConfigs(c *LkeV1alpha1Client, namespace string) *lKEClusterConfigs {
+	return &lKEClusterConfigs{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the lKEClusterConfig, and returns the corresponding lKEClusterConfig object, and an error if there is any.
+func (c *lKEClusterConfigs) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.LKEClusterConfig, err error) {
+	result = &v1alpha1.LKEClusterConfig{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("lkeclusterconfigs").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of LKEClusterConfigs that match those selectors.
+func (c *lKEClusterConfigs) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.LKEClusterConfigList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.LKEClusterConfigList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("lkeclusterconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested lKEClusterConfigs.
+func (c *lKEClusterConfigs) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("lkeclusterconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a lKEClusterConfig and creates it.  Returns the server's representation of the lKEClusterConfig, and an error, if there is any.
+func (c *lKEClusterConfigs) Create(ctx context.Context, lKEClusterConfig *v1alpha1.LKEClusterConfig, opts v1.CreateOptions) (result *v1alpha1.LKEClusterConfig, err error) {
+	result = &v1alpha1.LKEClusterConfig{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("lkeclusterconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(lKEClusterConfig).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a lKEClusterConfig and updates it. Returns the server's representation of the lKEClusterConfig, and an error, if there is any.
+func (c *lKEClusterConfigs) Update(ctx context.Context, lKEClusterConfig *v1alpha1.LKEClusterConfig, opts v1.UpdateOptions) (result *v1alpha1.LKEClusterConfig, err error) {
+	result = &v1alpha1.LKEClusterConfig{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("lkeclusterconfigs").
+		Name(lKEClusterConfig.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(lKEClusterConfig).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the lKEClusterConfig and deletes it. Returns an error if one occurs.
+func (c *lKEClusterConfigs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("lkeclusterconfigs").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *lKEClusterConfigs) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("lkeclusterconfigs").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched lKEClusterConfig.
+func (c *lKEClusterConfigs) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.LKEClusterConfig, err error) {
+	result = &v1alpha1.LKEClusterConfig{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("lkeclusterconfigs").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied lKEClusterConfig.
+func (c *lKEClusterConfigs) Apply(ctx context.Context, lKEClusterConfig *lkev1alpha1.LKEClusterConfigApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.LKEClusterConfig, err error) {
+	if lKEClusterConfig == nil {
+		return nil, fmt.Errorf("lKEClusterConfig provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(lKEClusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	name := lKEClusterConfig.Name
+	if name == nil {
+		return nil, fmt.Errorf("lKEClusterConfig.Name must be provided to Apply")
+	}
+	result = &v1alpha1.LKEClusterConfig{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("lkeclusterconfigs").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	time "time"
+
+	lkev1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+	versioned "github.com/anza-labs/lke-operator/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/anza-labs/lke-operator/pkg/client/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/anza-labs/lke-operator/pkg/client/listers/lke/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// LKEClusterConfigInformer provides access to a shared informer and lister for
+// LKEClusterConfigs.
+type LKEClusterConfigInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.LKEClusterConfigLister
+}
+
+type lKEClusterConfigInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewLKEClusterConfigInformer constructs a new informer for LKEClusterConfig type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewLKEClusterConfigInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredLKEClusterConfigInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredLKEClusterConfigInformer constructs a new informer for LKEClusterConfig type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredLKEClusterConfigInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.LkeV1alpha1().LKEClusterConfigs(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.LkeV1alpha1().LKEClusterConfigs(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&lkev1alpha1.LKEClusterConfig{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *lKEClusterConfigInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredLKEClusterConfigInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *lKEClusterConfigInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&lkev1alpha1.LKEClusterConfig{}, f.defaultInformer)
+}
+
+func (f *lKEClusterConfigInformer) Lister() v1alpha1.LKEClusterConfigLister {
+	return v1alpha1.NewLKEClusterConfigLister(f.Informer().GetIndexer())
+}
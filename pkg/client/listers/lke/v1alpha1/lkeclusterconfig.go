@@ -0,0 +1,98 @@
+/*
+Copyright 2024 lke-operator contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/anza-labs/lke-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// LKEClusterConfigLister helps list LKEClusterConfigs.
+// All objects returned here must be treated as read-only.
+type LKEClusterConfigLister interface {
+	// List lists all LKEClusterConfigs in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.LKEClusterConfig, err error)
+	// LKEClusterConfigs returns an object that can list and get LKEClusterConfigs.
+	LKEClusterConfigs(namespace string) LKEClusterConfigNamespaceLister
+	LKEClusterConfigListerExpansion
+}
+
+// lKEClusterConfigLister implements the LKEClusterConfigLister interface.
+type lKEClusterConfigLister struct {
+	indexer cache.Indexer
+}
+
+// NewLKEClusterConfigLister returns a new LKEClusterConfigLister.
+func NewLKEClusterConfigLister(indexer cache.Indexer) LKEClusterConfigLister {
+	return &lKEClusterConfigLister{indexer: indexer}
+}
+
+// List lists all LKEClusterConfigs in the indexer.
+func (s *lKEClusterConfigLister) List(selector labels.Selector) (ret []*v1alpha1.LKEClusterConfig, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.LKEClusterConfig))
+	})
+	return ret, err
+}
+
+// LKEClusterConfigs returns an object that can list and get LKEClusterConfigs.
+func (s *lKEClusterConfigLister) LKEClusterConfigs(namespace string) LKEClusterConfigNamespaceLister {
+	return lKEClusterConfigNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// LKEClusterConfigNamespaceLister helps list and get LKEClusterConfigs.
+// All objects returned here must be treated as read-only.
+type LKEClusterConfigNamespaceLister interface {
+	// List lists all LKEClusterConfigs in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.LKEClusterConfig, err error)
+	// Get retrieves the LKEClusterConfig from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.LKEClusterConfig, error)
+	LKEClusterConfigNamespaceListerExpansion
+}
+
+// lKEClusterConfigNamespaceLister implements the LKEClusterConfigNamespaceLister
+// interface.
+type lKEClusterConfigNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all LKEClusterConfigs in the indexer for a given namespace.
+func (s lKEClusterConfigNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.LKEClusterConfig, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.LKEClusterConfig))
+	})
+	return ret, err
+}
+
+// Get retrieves the LKEClusterConfig from the indexer for a given namespace and name.
+func (s lKEClusterConfigNamespaceLister) Get(name string) (*v1alpha1.LKEClusterConfig, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("lkeclusterconfig"), name)
+	}
+	return obj.(*v1alpha1.LKEClusterConfig), nil
+}